@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// ansiState tracks progress through an escape sequence
+type ansiState int
+
+const (
+	ansiText         ansiState = iota // Plain output
+	ansiEscape                        // After ESC
+	ansiIntermediate                  // After ESC and intermediate bytes, e.g. ESC ( B
+	ansiCSI                           // Control sequence, e.g. ESC [ 31 m
+	ansiString                        // OSC, DCS and similar, ended by BEL or ESC \
+	ansiStringEscape                  // ESC inside a string sequence
+)
+
+// ansiStripper removes ANSI escape sequences before forwarding output to w.
+// The parser state carries over between writes, so sequences split across
+// writes are removed as well.
+type ansiStripper struct {
+	w     io.Writer
+	state ansiState
+}
+
+// StripANSI returns s with all ANSI escape sequences removed
+func StripANSI(s string) string {
+	var buf bytes.Buffer
+	_, _ = (&ansiStripper{w: &buf}).Write([]byte(s))
+	return buf.String()
+}
+
+// Write implements io.Writer
+func (s *ansiStripper) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		if s.keep(b) {
+			out = append(out, b)
+		}
+	}
+
+	if len(out) > 0 {
+		if _, err := s.w.Write(out); err != nil {
+			return 0, fmt.Errorf("failed to write stripped output: %w", err)
+		}
+	}
+	return len(p), nil
+}
+
+// keep advances the parser by one byte and reports whether it is plain output
+func (s *ansiStripper) keep(b byte) bool {
+	switch s.state {
+	case ansiText:
+		if b == 0x1b {
+			s.state = ansiEscape
+			return false
+		}
+		return true
+	case ansiEscape:
+		s.state = escapeState(b)
+	case ansiIntermediate:
+		if b < 0x20 || b > 0x2f {
+			s.state = ansiText
+		}
+	case ansiCSI:
+		if b >= 0x40 && b <= 0x7e {
+			s.state = ansiText
+		}
+	case ansiString:
+		switch b {
+		case 0x07:
+			s.state = ansiText
+		case 0x1b:
+			s.state = ansiStringEscape
+		}
+	case ansiStringEscape:
+		if b == '\\' {
+			s.state = ansiText
+		} else {
+			s.state = ansiString
+		}
+	}
+	return false
+}
+
+// escapeState returns the state following ESC and the byte b
+func escapeState(b byte) ansiState {
+	switch {
+	case b == '[':
+		return ansiCSI
+	case b == ']' || b == 'P' || b == 'X' || b == '^' || b == '_':
+		return ansiString
+	case b >= 0x20 && b <= 0x2f:
+		return ansiIntermediate
+	default:
+		// Single character sequences such as ESC 7 end here
+		return ansiText
+	}
+}
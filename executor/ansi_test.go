@@ -0,0 +1,53 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "hello\n", "hello\n"},
+		{"color", "\x1b[1;31mred\x1b[0m text", "red text"},
+		{"cursor", "50%\x1b[2K\x1b[1G100%", "50%100%"},
+		{"title with BEL", "\x1b]0;title\x07done", "done"},
+		{"hyperlink with ST", "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"charset", "\x1b(Bascii", "ascii"},
+		{"single character", "\x1b7saved\x1b8", "saved"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := executor.StripANSI(tt.input); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithStripANSI(t *testing.T) {
+	var console bytes.Buffer
+	cmd := executor.New("printf", `\033[32mok\033[0m\n`)
+	result, err := cmd.Execute(
+		context.Background(),
+		executor.WithStripANSI(true),
+		executor.WithStdoutWriter(&console),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Stdout != "ok\n" {
+		t.Errorf("expected escape sequences stripped from capture, got %q", result.Stdout)
+	}
+	if console.String() != "\x1b[32mok\x1b[0m\n" {
+		t.Errorf("expected writer to receive raw output, got %q", console.String())
+	}
+}
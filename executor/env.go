@@ -0,0 +1,152 @@
+package executor
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// EnvMode controls which variables of the current process a command inherits
+type EnvMode int
+
+const (
+	// EnvInherit passes the whole environment of the current process (the default)
+	EnvInherit EnvMode = iota
+
+	// EnvClean starts from an empty environment
+	EnvClean
+
+	// EnvAllowList passes only the variables matched by EnvAllow
+	EnvAllowList
+)
+
+// String returns a human-readable representation of the mode
+func (m EnvMode) String() string {
+	switch m {
+	case EnvInherit:
+		return "inherit"
+	case EnvClean:
+		return "clean"
+	case EnvAllowList:
+		return "allow-list"
+	default:
+		return "unknown"
+	}
+}
+
+// Environ returns the environment the command would run with, sorted by
+// name, for debugging. Secret values are replaced with RedactionMask.
+func (c *CommandExecutor) Environ(opts ...Option) []string {
+	options := c.mergeOptions(opts...)
+
+	env := buildEnv(options)
+	for key := range options.SecretEnv {
+		env[key] = RedactionMask
+	}
+	return formatEnv(env)
+}
+
+// customEnv reports whether the command needs an environment other than the
+// unmodified environment of the current process
+func customEnv(options *Options) bool {
+	return options.EnvMode != EnvInherit ||
+		len(options.Env) > 0 ||
+		len(options.EnvUnset) > 0 ||
+		len(options.PathPrepend) > 0 ||
+		len(options.PathAppend) > 0 ||
+		options.secrets.hasEnv()
+}
+
+// commandEnv computes the final environment of a command, including secrets
+func commandEnv(options *Options) []string {
+	env := buildEnv(options)
+	if options.secrets != nil {
+		for key, value := range options.secrets.env {
+			env[key] = string(value)
+		}
+	}
+	return formatEnv(env)
+}
+
+// buildEnv computes the environment from the mode, variables, unset list and
+// PATH changes, in that order
+func buildEnv(options *Options) map[string]string {
+	env := make(map[string]string)
+
+	if options.EnvMode != EnvClean {
+		for _, kv := range os.Environ() {
+			key, value := splitEnv(kv)
+			if options.EnvMode == EnvInherit || envAllowed(key, options.EnvAllow) {
+				env[key] = value
+			}
+		}
+	}
+
+	for key, value := range options.Env {
+		env[key] = value
+	}
+
+	for _, key := range options.EnvUnset {
+		delete(env, key)
+	}
+
+	if len(options.PathPrepend) > 0 || len(options.PathAppend) > 0 {
+		key := pathKey(env)
+		var dirs []string
+		dirs = append(dirs, options.PathPrepend...)
+		if env[key] != "" {
+			dirs = append(dirs, env[key])
+		}
+		dirs = append(dirs, options.PathAppend...)
+		env[key] = strings.Join(dirs, string(os.PathListSeparator))
+	}
+
+	return env
+}
+
+// formatEnv converts an environment map to sorted KEY=value entries
+func formatEnv(env map[string]string) []string {
+	entries := make([]string, 0, len(env))
+	for key, value := range env {
+		entries = append(entries, key+"="+value)
+	}
+	slices.Sort(entries)
+	return entries
+}
+
+// splitEnv splits a KEY=value entry. Windows has hidden variables whose
+// names start with '=', so the separator is searched after the first byte.
+func splitEnv(kv string) (key, value string) {
+	if kv == "" {
+		return "", ""
+	}
+	if i := strings.IndexByte(kv[1:], '='); i >= 0 {
+		return kv[:i+1], kv[i+2:]
+	}
+	return kv, ""
+}
+
+// envAllowed reports whether a variable matches the allow-list. Patterns
+// ending in '*' match any variable with that prefix.
+func envAllowed(key string, allow []string) bool {
+	for _, pattern := range allow {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// pathKey returns the name of the PATH variable, which is "Path" on Windows
+func pathKey(env map[string]string) string {
+	for key := range env {
+		if strings.EqualFold(key, "PATH") {
+			return key
+		}
+	}
+	return "PATH"
+}
@@ -0,0 +1,102 @@
+package executor_test
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+func TestCleanEnv(t *testing.T) {
+	t.Setenv("EXECUTOR_TEST_INHERITED", "yes")
+
+	cmd := executor.New("/usr/bin/env")
+	result, err := cmd.Execute(context.Background(), executor.WithCleanEnv(), executor.WithEnvVar("ONLY", "1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Stdout != "ONLY=1\n" {
+		t.Errorf("expected only explicit variables, got %q", result.Stdout)
+	}
+}
+
+func TestEnvAllowList(t *testing.T) {
+	t.Setenv("EXECUTOR_TEST_KEEP", "1")
+	t.Setenv("EXECUTOR_PREFIX_A", "2")
+	t.Setenv("EXECUTOR_DROP", "3")
+
+	env := executor.New("env").Environ(executor.WithEnvAllowList("EXECUTOR_TEST_KEEP", "EXECUTOR_PREFIX_*"))
+
+	want := []string{"EXECUTOR_PREFIX_A=2", "EXECUTOR_TEST_KEEP=1"}
+	if !slices.Equal(env, want) {
+		t.Errorf("expected %v, got %v", want, env)
+	}
+}
+
+func TestEnvUnset(t *testing.T) {
+	t.Setenv("EXECUTOR_TEST_UNSET", "1")
+
+	env := executor.New("env").Environ(
+		executor.WithEnvVar("EXECUTOR_TEST_SET", "1"),
+		executor.WithEnvUnset("EXECUTOR_TEST_UNSET", "EXECUTOR_TEST_SET"),
+	)
+
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "EXECUTOR_TEST_") {
+			t.Errorf("expected variable to be unset, found %s", kv)
+		}
+	}
+}
+
+func TestPathHelpers(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	t.Setenv("PATH", "/usr/bin")
+
+	env := executor.New("env").Environ(
+		executor.WithPathPrepend("/opt/b"),
+		executor.WithPathPrepend("/opt/a"),
+		executor.WithPathAppend("/opt/z"),
+	)
+
+	want := "PATH=" + strings.Join([]string{"/opt/a", "/opt/b", "/usr/bin", "/opt/z"}, sep)
+	if !slices.Contains(env, want) {
+		t.Errorf("expected %s, got %v", want, env)
+	}
+
+	clean := executor.New("env").Environ(executor.WithCleanEnv(), executor.WithPathPrepend("/tools"))
+	if !slices.Equal(clean, []string{"PATH=/tools"}) {
+		t.Errorf("expected PATH from helpers only, got %v", clean)
+	}
+}
+
+func TestEnvironIsSortedAndMasked(t *testing.T) {
+	env := executor.New("env").Environ(
+		executor.WithCleanEnv(),
+		executor.WithEnvVar("B", "2"),
+		executor.WithEnvVar("A", "1"),
+		executor.WithSecretEnv("TOKEN", executor.FromSecret(&staticSecret{value: []byte("hunter2")})),
+	)
+
+	want := []string{"A=1", "B=2", "TOKEN=" + executor.RedactionMask}
+	if !slices.Equal(env, want) {
+		t.Errorf("expected %v, got %v", want, env)
+	}
+}
+
+func TestEnvOptionsDoNotModifyBaseOptions(t *testing.T) {
+	cmd := executor.NewSystemCommander(executor.WithEnvVar("BASE", "1")).Command("env")
+
+	first := cmd.(*executor.CommandExecutor).Environ(executor.WithCleanEnv(), executor.WithEnvVar("CALL", "1"))
+	second := cmd.(*executor.CommandExecutor).Environ(executor.WithCleanEnv())
+
+	if !slices.Equal(first, []string{"BASE=1", "CALL=1"}) {
+		t.Errorf("unexpected first environment: %v", first)
+	}
+	if !slices.Equal(second, []string{"BASE=1"}) {
+		t.Errorf("expected per-call variable not to leak, got %v", second)
+	}
+}
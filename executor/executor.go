@@ -9,8 +9,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"time"
 )
@@ -21,7 +23,13 @@ type Result struct {
 	Stderr   string
 	Combined string
 	ExitCode int
+	Signal   os.Signal // Signal that terminated the process, nil if it exited normally
 	Err      error
+	Attempts []Attempt // History of every execution attempt, including this one
+	Stages   []*Result // Result of every stage when run as a Pipeline
+
+	Usage     Usage // Resources consumed by the process
+	Truncated bool  // Captured output exceeded MaxOutputBytes
 }
 
 // Executor defines the interface for command execution
@@ -49,19 +57,56 @@ type Options struct {
 	RedirectToConsole bool
 
 	// Retry configuration
-	MaxRetries int
-	RetryDelay time.Duration
-	RetryOn    func(error) bool // Custom retry condition
+	MaxRetries      int
+	RetryDelay      time.Duration
+	RetryOn         func(error) bool   // Custom retry condition
+	RetryOnResult   func(*Result) bool // Custom retry condition with access to output and exit code
+	RetryBackoff    Backoff            // Delay policy between attempts (defaults to RetryDelay)
+	RetryMaxElapsed time.Duration      // Stop retrying once this much time has passed (0 = no limit)
 
 	// Working directory
 	WorkingDir string
 
-	// Environment variables (appended to current env)
+	// Streaming stdin, used instead of the input string when set
+	Stdin io.Reader
+
+	// Environment variables (applied on top of the environment selected by EnvMode)
 	Env map[string]string
 
+	// Environment construction
+	EnvMode     EnvMode  // Which variables of the current process are inherited
+	EnvAllow    []string // Variables inherited in EnvAllowList mode ("PREFIX_*" matches a prefix)
+	EnvUnset    []string // Variables removed after Env is applied
+	PathPrepend []string // Directories added to the front of PATH
+	PathAppend  []string // Directories added to the end of PATH
+
 	// Custom stdout/stderr writers (for advanced use cases)
 	StdoutWriter io.Writer
 	StderrWriter io.Writer
+
+	// Line streaming (called for every line while the command runs)
+	LineHandler LineHandler
+
+	// Termination on context cancellation
+	ProcessGroup bool          // Run in a new process group and signal the whole group
+	GracePeriod  time.Duration // Time between SIGTERM and SIGKILL (0 kills immediately)
+
+	// Resource limits
+	Timeout        time.Duration   // Per-attempt timeout, independent of the parent context (0 = none)
+	MaxOutputBytes int             // Cap on each captured buffer (0 = unlimited)
+	Limits         *ResourceLimits // rlimits applied to the process (Linux only)
+
+	// Secrets (resolved once per execution and masked in all output)
+	SecretEnv   map[string]SecretSource // Environment variables with secret values
+	SecretInput SecretSource            // Secret written to stdin, replacing any plain input
+	Redact      []string                // Additional values to mask in output
+
+	// Terminal emulation
+	PTY       *WindowSize // Run attached to a pseudo-terminal of this size (Linux only)
+	StripANSI bool        // Remove ANSI escape sequences from captured output
+
+	// secrets holds the resolved secret values for the current execution
+	secrets *resolvedSecrets
 }
 
 // Option is a function that modifies Options
@@ -149,34 +194,15 @@ func (c *CommandExecutor) ExecuteWithInput(
 	// Apply options
 	options := c.mergeOptions(opts...)
 
-	// Setup retry logic
-	maxAttempts := options.MaxRetries + 1
-	var lastResult *Result
-
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		result, err := c.executeOnce(ctx, input, options)
-		lastResult = result
-
-		// Success or non-retryable error
-		if err == nil || attempt == maxAttempts {
-			return result, err
-		}
-
-		// Check if we should retry
-		if options.RetryOn != nil && !options.RetryOn(err) {
-			return result, err
-		}
-
-		// Wait before retry
-		select {
-		case <-ctx.Done():
-			return result, fmt.Errorf("context cancelled during retry: %w", ctx.Err())
-		case <-time.After(options.RetryDelay):
-			// Continue to next attempt
-		}
+	// Resolve secrets once so one-time-use secrets are available to every attempt
+	secrets, err := resolveSecrets(ctx, options)
+	if err != nil {
+		return &Result{ExitCode: -1, Err: err}, err
 	}
+	defer secrets.clear()
+	options.secrets = secrets
 
-	return lastResult, lastResult.Err
+	return c.executeWithRetry(ctx, input, options)
 }
 
 // setupCommand configures the exec.Cmd with working directory, environment, and input
@@ -187,33 +213,65 @@ func (c *CommandExecutor) setupCommand(cmd *exec.Cmd, input string, options *Opt
 	}
 
 	// Set environment
-	if len(options.Env) > 0 {
-		cmd.Env = os.Environ()
-		for k, v := range options.Env {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
-		}
+	if customEnv(options) {
+		cmd.Env = commandEnv(options)
 	}
 
 	// Setup input
-	if input != "" {
+	switch {
+	case options.secrets != nil && options.secrets.input != nil:
+		cmd.Stdin = bytes.NewReader(options.secrets.input)
+	case options.Stdin != nil:
+		cmd.Stdin = options.Stdin
+	case input != "":
 		cmd.Stdin = strings.NewReader(input)
 	}
 }
 
+// outputCapture holds the buffers and writers attached to a running command
+type outputCapture struct {
+	stdout    limitedBuffer
+	stderr    limitedBuffer
+	combined  limitedBuffer
+	lines     *lineStreamer
+	redactors []*redactingWriter
+
+	// terminal receives all output when running attached to a pseudo-terminal
+	terminal io.Writer
+}
+
+// Flush writes out any output still held by redaction and line splitting.
+// It must only be called after the process has finished writing.
+func (o *outputCapture) Flush() {
+	for _, r := range o.redactors {
+		_ = r.Flush()
+	}
+	if o.lines != nil {
+		o.lines.Flush()
+	}
+}
+
 // setupOutputCapture configures stdout and stderr writers for the command
-func (c *CommandExecutor) setupOutputCapture(
-	cmd *exec.Cmd,
-	options *Options,
-) (*bytes.Buffer, *bytes.Buffer, *bytes.Buffer) {
-	var stdoutBuf, stderrBuf, combinedBuf bytes.Buffer
+func (c *CommandExecutor) setupOutputCapture(cmd *exec.Cmd, options *Options) *outputCapture {
+	out := &outputCapture{
+		stdout:   limitedBuffer{limit: options.MaxOutputBytes},
+		stderr:   limitedBuffer{limit: options.MaxOutputBytes},
+		combined: limitedBuffer{limit: options.MaxOutputBytes},
+		lines:    newLineStreamer(options.LineHandler),
+	}
+
+	if options.PTY != nil {
+		out.terminal = out.terminalWriter(options)
+		return out
+	}
 
 	// Configure stdout
 	stdoutWriters := []io.Writer{}
 	if options.CaptureStdout || options.CaptureCombined {
 		if options.CaptureCombined {
-			stdoutWriters = append(stdoutWriters, &combinedBuf)
+			stdoutWriters = append(stdoutWriters, out.capture(&out.combined, options))
 		} else {
-			stdoutWriters = append(stdoutWriters, &stdoutBuf)
+			stdoutWriters = append(stdoutWriters, out.capture(&out.stdout, options))
 		}
 	}
 	if options.RedirectToConsole {
@@ -222,18 +280,21 @@ func (c *CommandExecutor) setupOutputCapture(
 	if options.StdoutWriter != nil {
 		stdoutWriters = append(stdoutWriters, options.StdoutWriter)
 	}
+	if out.lines != nil {
+		stdoutWriters = append(stdoutWriters, out.lines.stdout)
+	}
 
 	if len(stdoutWriters) > 0 {
-		cmd.Stdout = io.MultiWriter(stdoutWriters...)
+		cmd.Stdout = out.redact(io.MultiWriter(stdoutWriters...), options)
 	}
 
 	// Configure stderr
 	stderrWriters := []io.Writer{}
 	if options.CaptureStderr || options.CaptureCombined {
 		if options.CaptureCombined {
-			stderrWriters = append(stderrWriters, &combinedBuf)
+			stderrWriters = append(stderrWriters, out.capture(&out.combined, options))
 		} else {
-			stderrWriters = append(stderrWriters, &stderrBuf)
+			stderrWriters = append(stderrWriters, out.capture(&out.stderr, options))
 		}
 	}
 	if options.RedirectToConsole {
@@ -242,24 +303,67 @@ func (c *CommandExecutor) setupOutputCapture(
 	if options.StderrWriter != nil {
 		stderrWriters = append(stderrWriters, options.StderrWriter)
 	}
+	if out.lines != nil {
+		stderrWriters = append(stderrWriters, out.lines.stderr)
+	}
 
 	if len(stderrWriters) > 0 {
-		cmd.Stderr = io.MultiWriter(stderrWriters...)
+		cmd.Stderr = out.redact(io.MultiWriter(stderrWriters...), options)
+	}
+
+	return out
+}
+
+// terminalWriter builds the single writer receiving pseudo-terminal output,
+// in which stdout and stderr are interleaved exactly as a terminal shows them
+func (o *outputCapture) terminalWriter(options *Options) io.Writer {
+	writers := []io.Writer{}
+	if options.CaptureStdout || options.CaptureStderr || options.CaptureCombined {
+		writers = append(writers, o.capture(&o.combined, options))
+	}
+	if options.RedirectToConsole {
+		writers = append(writers, os.Stdout)
+	}
+	if options.StdoutWriter != nil {
+		writers = append(writers, options.StdoutWriter)
+	}
+	if o.lines != nil {
+		writers = append(writers, o.lines.stdout)
+	}
+	return o.redact(io.MultiWriter(writers...), options)
+}
+
+// capture returns the writer for a capture buffer, stripping ANSI escape
+// sequences from the captured copy if requested
+func (o *outputCapture) capture(buf *limitedBuffer, options *Options) io.Writer {
+	if options.StripANSI {
+		return &ansiStripper{w: buf}
 	}
+	return buf
+}
 
-	return &stdoutBuf, &stderrBuf, &combinedBuf
+// redact wraps w so that secret values never reach it
+func (o *outputCapture) redact(w io.Writer, options *Options) io.Writer {
+	if options.secrets == nil {
+		return w
+	}
+	r := newRedactingWriter(w, options.secrets.masked)
+	if r == nil {
+		return w
+	}
+	o.redactors = append(o.redactors, r)
+	return r
 }
 
 // createResult creates a Result from command execution and error
-func (c *CommandExecutor) createResult(
-	stdoutBuf, stderrBuf, combinedBuf *bytes.Buffer,
-	err error,
-) *Result {
+func (c *CommandExecutor) createResult(out *outputCapture, err error) *Result {
 	result := &Result{
-		Stdout:   stdoutBuf.String(),
-		Stderr:   stderrBuf.String(),
-		Combined: combinedBuf.String(),
+		Stdout:   out.stdout.String(),
+		Stderr:   out.stderr.String(),
+		Combined: out.combined.String(),
 		Err:      err,
+
+		Truncated: out.stdout.truncated || out.stderr.truncated || out.combined.truncated,
 	}
 
 	// Get exit code
@@ -281,23 +385,67 @@ func (c *CommandExecutor) executeOnce(
 	input string,
 	options *Options,
 ) (*Result, error) {
-	cmd := exec.CommandContext(ctx, c.program, c.args...)
+	// Apply the per-attempt timeout without affecting the parent context
+	attemptCtx := ctx
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(attemptCtx, c.program, c.args...)
 
 	c.setupCommand(cmd, input, options)
-	stdoutBuf, stderrBuf, combinedBuf := c.setupOutputCapture(cmd, options)
+	out := c.setupOutputCapture(cmd, options)
+	cleanup := c.setupTermination(attemptCtx, cmd, options)
+
+	var term *terminal
+	if options.PTY != nil {
+		var err error
+		if term, err = startTerminal(cmd, *options.PTY, out.terminal); err != nil {
+			return &Result{ExitCode: -1, Err: err}, fmt.Errorf("command execution failed: %w", err)
+		}
+	}
 
 	// Execute command
-	err := cmd.Run()
+	start := time.Now()
+	err := c.run(cmd, options)
+	duration := time.Since(start)
+	term.Close()
+	cleanup()
+
+	// Emit held back output once the process has finished writing
+	out.Flush()
 
 	// Prepare result
-	result := c.createResult(stdoutBuf, stderrBuf, combinedBuf, err)
+	result := c.createResult(out, err)
+	result.Signal = exitSignal(cmd.ProcessState)
+	result.Usage = newUsage(cmd.ProcessState, duration)
 
 	if err != nil {
+		if ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			return result, fmt.Errorf("command execution failed: %w after %s: %w", ErrTimeout, options.Timeout, err)
+		}
 		return result, fmt.Errorf("command execution failed: %w", err)
 	}
 	return result, nil
 }
 
+// run starts the command, applies resource limits and waits for it to exit
+func (c *CommandExecutor) run(cmd *exec.Cmd, options *Options) error {
+	if err := cmd.Start(); err != nil {
+		return err //nolint:wrapcheck // wrapped by executeOnce
+	}
+
+	if err := applyLimits(cmd.Process.Pid, options.Limits); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+
+	return cmd.Wait() //nolint:wrapcheck // wrapped by executeOnce
+}
+
 func (c *CommandExecutor) mergeOptions(opts ...Option) *Options {
 	// Copy base options
 	merged := *c.options
@@ -343,6 +491,28 @@ func WithRetryCondition(fn func(error) bool) Option {
 	}
 }
 
+// WithRetryResultCondition sets a retry condition that can inspect the exit
+// code and captured output of each failed attempt
+func WithRetryResultCondition(fn func(*Result) bool) Option {
+	return func(o *Options) {
+		o.RetryOnResult = fn
+	}
+}
+
+// WithRetryBackoff sets the delay policy between retry attempts
+func WithRetryBackoff(b Backoff) Option {
+	return func(o *Options) {
+		o.RetryBackoff = b
+	}
+}
+
+// WithRetryMaxElapsed stops retrying once the total time spent would exceed d
+func WithRetryMaxElapsed(d time.Duration) Option {
+	return func(o *Options) {
+		o.RetryMaxElapsed = d
+	}
+}
+
 // WithWorkingDir sets the working directory
 func WithWorkingDir(dir string) Option {
 	return func(o *Options) {
@@ -350,25 +520,70 @@ func WithWorkingDir(dir string) Option {
 	}
 }
 
+// WithStdin streams stdin from r instead of an input string, so large inputs
+// need not be held in memory. A retried attempt only sees what earlier
+// attempts left unread.
+func WithStdin(r io.Reader) Option {
+	return func(o *Options) {
+		o.Stdin = r
+	}
+}
+
 // WithEnv adds environment variables
 func WithEnv(env map[string]string) Option {
 	return func(o *Options) {
-		if o.Env == nil {
-			o.Env = make(map[string]string)
-		}
-		for k, v := range env {
-			o.Env[k] = v
-		}
+		// Copy so per-call options never modify the executor's base options
+		merged := make(map[string]string, len(o.Env)+len(env))
+		maps.Copy(merged, o.Env)
+		maps.Copy(merged, env)
+		o.Env = merged
 	}
 }
 
 // WithEnvVar adds a single environment variable
 func WithEnvVar(key, value string) Option {
+	return WithEnv(map[string]string{key: value})
+}
+
+// WithEnvMode selects which variables of the current process are inherited
+func WithEnvMode(mode EnvMode) Option {
 	return func(o *Options) {
-		if o.Env == nil {
-			o.Env = make(map[string]string)
-		}
-		o.Env[key] = value
+		o.EnvMode = mode
+	}
+}
+
+// WithCleanEnv runs the command with only the variables set through options
+func WithCleanEnv() Option {
+	return WithEnvMode(EnvClean)
+}
+
+// WithEnvAllowList inherits only the named variables from the current
+// process. Names ending in '*' match any variable with that prefix.
+func WithEnvAllowList(names ...string) Option {
+	return func(o *Options) {
+		o.EnvMode = EnvAllowList
+		o.EnvAllow = append(slices.Clone(o.EnvAllow), names...)
+	}
+}
+
+// WithEnvUnset removes variables from the command's environment
+func WithEnvUnset(names ...string) Option {
+	return func(o *Options) {
+		o.EnvUnset = append(slices.Clone(o.EnvUnset), names...)
+	}
+}
+
+// WithPathPrepend adds directories to the front of PATH, in the given order
+func WithPathPrepend(dirs ...string) Option {
+	return func(o *Options) {
+		o.PathPrepend = append(slices.Clone(dirs), o.PathPrepend...)
+	}
+}
+
+// WithPathAppend adds directories to the end of PATH, in the given order
+func WithPathAppend(dirs ...string) Option {
+	return func(o *Options) {
+		o.PathAppend = append(slices.Clone(o.PathAppend), dirs...)
 	}
 }
 
@@ -386,6 +601,108 @@ func WithStderrWriter(w io.Writer) Option {
 	}
 }
 
+// WithLineHandler streams output line by line to fn while the command runs.
+// The final Result is still populated according to the capture options.
+func WithLineHandler(fn LineHandler) Option {
+	return func(o *Options) {
+		o.LineHandler = fn
+	}
+}
+
+// WithLineChannel streams output line by line to ch while the command runs.
+// Sends block, so the caller must keep draining ch until execution returns.
+// The channel is never closed by the executor.
+func WithLineChannel(ch chan<- LineEvent) Option {
+	return func(o *Options) {
+		o.LineHandler = func(ev LineEvent) {
+			ch <- ev
+		}
+	}
+}
+
+// WithProcessGroup runs the command in its own process group so that
+// cancellation signals reach every process it spawned, not just the direct child
+func WithProcessGroup(enabled bool) Option {
+	return func(o *Options) {
+		o.ProcessGroup = enabled
+	}
+}
+
+// WithGracePeriod sends SIGTERM on context cancellation and waits up to d
+// before sending SIGKILL
+func WithGracePeriod(d time.Duration) Option {
+	return func(o *Options) {
+		o.GracePeriod = d
+	}
+}
+
+// WithTimeout limits each attempt to d, independently of the parent context
+func WithTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// WithMaxOutputBytes caps each captured buffer at n bytes. Excess output is
+// discarded and TruncationMarker is appended to the captured text.
+func WithMaxOutputBytes(n int) Option {
+	return func(o *Options) {
+		o.MaxOutputBytes = n
+	}
+}
+
+// WithResourceLimits applies rlimits to the command's process (Linux only)
+func WithResourceLimits(limits ResourceLimits) Option {
+	return func(o *Options) {
+		o.Limits = &limits
+	}
+}
+
+// WithSecretEnv sets an environment variable from a secret.
+// The secret is resolved once per execution and its value is masked in all
+// captured output and writers.
+func WithSecretEnv(key string, secret SecretSource) Option {
+	return func(o *Options) {
+		env := make(map[string]SecretSource, len(o.SecretEnv)+1)
+		maps.Copy(env, o.SecretEnv)
+		env[key] = secret
+		o.SecretEnv = env
+	}
+}
+
+// WithSecretInput writes a secret to the command's stdin instead of the plain input.
+// The value is masked in all captured output and writers.
+func WithSecretInput(secret SecretSource) Option {
+	return func(o *Options) {
+		o.SecretInput = secret
+	}
+}
+
+// WithRedact masks additional values in all captured output and writers
+func WithRedact(values ...string) Option {
+	return func(o *Options) {
+		o.Redact = append(slices.Clone(o.Redact), values...)
+	}
+}
+
+// WithPTY runs the command attached to a pseudo-terminal of the given size,
+// so tools behave as they do in an interactive shell. Stdout and stderr are
+// interleaved and captured into Result.Combined, with terminal line endings
+// (\r\n). Zero dimensions default to DefaultWindowSize. Linux only.
+func WithPTY(size WindowSize) Option {
+	return func(o *Options) {
+		o.PTY = &size
+	}
+}
+
+// WithStripANSI removes ANSI escape sequences (colors, cursor movement and
+// the like) from captured output. Console output and writers are unaffected.
+func WithStripANSI(strip bool) Option {
+	return func(o *Options) {
+		o.StripANSI = strip
+	}
+}
+
 // Convenience functions for common patterns
 
 // CaptureAll captures and redirects to console simultaneously
@@ -0,0 +1,263 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrNoFakeResponse is returned by a Fake when no rule matches an invocation
+var ErrNoFakeResponse = errors.New("no fake response configured")
+
+// Commander creates executors for a program and its arguments.
+// Code that shells out should accept a Commander so tests can substitute a Fake.
+type Commander interface {
+	Command(program string, args ...string) Executor
+}
+
+// SystemCommander is a Commander that runs real processes
+type SystemCommander struct {
+	options []Option
+}
+
+// NewSystemCommander creates a Commander that runs real processes with the
+// given options applied to every command
+func NewSystemCommander(opts ...Option) *SystemCommander {
+	return &SystemCommander{options: opts}
+}
+
+// Command implements Commander
+//
+//nolint:ireturn // returning the interface is the purpose of Commander
+func (s *SystemCommander) Command(program string, args ...string) Executor {
+	cmd := New(program, args...)
+	for _, opt := range s.options {
+		opt(cmd.options)
+	}
+	return cmd
+}
+
+// ExitCodeError is returned by a Fake when a scripted response has a non-zero exit code
+type ExitCodeError struct {
+	Code int
+}
+
+// Error implements the error interface
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// FakeResponse is the scripted outcome of a fake invocation
+type FakeResponse struct {
+	Stdout   string        `json:"stdout,omitempty"`
+	Stderr   string        `json:"stderr,omitempty"`
+	ExitCode int           `json:"exitCode"`
+	Delay    time.Duration `json:"delay,omitempty"`
+}
+
+// Invocation records a single command execution
+type Invocation struct {
+	Program    string            `json:"program"`
+	Args       []string          `json:"args"`
+	Input      string            `json:"input,omitempty"`
+	WorkingDir string            `json:"workingDir,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+
+	// Options are the fully merged options the command ran with
+	Options *Options `json:"-"`
+}
+
+// FakeRule matches invocations and returns a scripted response
+type FakeRule struct {
+	match     func(program string, args []string) bool
+	response  FakeResponse
+	remaining int // -1 for unlimited
+}
+
+// Return sets the response returned when the rule matches
+func (r *FakeRule) Return(resp FakeResponse) *FakeRule {
+	r.response = resp
+	return r
+}
+
+// Times limits how many invocations the rule answers before it stops matching
+func (r *FakeRule) Times(n int) *FakeRule {
+	r.remaining = n
+	return r
+}
+
+// Fake is an in-memory Commander whose executors return scripted responses
+// instead of running processes. It records every invocation and is safe for
+// concurrent use.
+type Fake struct {
+	mu          sync.Mutex
+	rules       []*FakeRule
+	invocations []Invocation
+}
+
+// NewFake creates a Fake with no rules
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+// On adds a rule matching the exact program and arguments.
+// Rules are evaluated in the order they were added.
+func (f *Fake) On(program string, args ...string) *FakeRule {
+	return f.OnMatch(func(p string, a []string) bool {
+		return p == program && slices.Equal(a, args)
+	})
+}
+
+// OnMatch adds a rule using a custom matcher
+func (f *Fake) OnMatch(match func(program string, args []string) bool) *FakeRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rule := &FakeRule{match: match, remaining: -1}
+	f.rules = append(f.rules, rule)
+	return rule
+}
+
+// Replay adds a rule for every entry of a recorded session, each answering
+// exactly once, so the same command may return different responses in order
+func (f *Fake) Replay(session *Session) {
+	for _, entry := range session.Entries {
+		f.On(entry.Invocation.Program, entry.Invocation.Args...).Return(entry.Response).Times(1)
+	}
+}
+
+// Invocations returns every recorded invocation in call order
+func (f *Fake) Invocations() []Invocation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.invocations)
+}
+
+// Command implements Commander
+//
+//nolint:ireturn // returning the interface is the purpose of Commander
+func (f *Fake) Command(program string, args ...string) Executor {
+	return &fakeCommand{fake: f, program: program, args: args}
+}
+
+// respond records the invocation and returns the matching response
+func (f *Fake) respond(inv Invocation) (FakeResponse, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.invocations = append(f.invocations, inv)
+
+	for _, rule := range f.rules {
+		if rule.remaining == 0 || !rule.match(inv.Program, inv.Args) {
+			continue
+		}
+		if rule.remaining > 0 {
+			rule.remaining--
+		}
+		return rule.response, true
+	}
+
+	return FakeResponse{}, false
+}
+
+// fakeCommand is the Executor returned by Fake.Command
+type fakeCommand struct {
+	fake    *Fake
+	program string
+	args    []string
+}
+
+// Execute implements the Executor interface
+func (c *fakeCommand) Execute(ctx context.Context, opts ...Option) (*Result, error) {
+	return c.ExecuteWithInput(ctx, "", opts...)
+}
+
+// ExecuteWithInput implements the Executor interface
+func (c *fakeCommand) ExecuteWithInput(ctx context.Context, input string, opts ...Option) (*Result, error) {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Record streamed stdin as the input
+	if options.Stdin != nil && input == "" {
+		data, err := io.ReadAll(options.Stdin)
+		if err != nil {
+			err = fmt.Errorf("failed to read stdin: %w", err)
+			return &Result{ExitCode: -1, Err: err}, err
+		}
+		input = string(data)
+	}
+
+	resp, ok := c.fake.respond(Invocation{
+		Program:    c.program,
+		Args:       slices.Clone(c.args),
+		Input:      input,
+		WorkingDir: options.WorkingDir,
+		Env:        maps.Clone(options.Env),
+		Options:    options,
+	})
+	if !ok {
+		err := fmt.Errorf("%w for %s %v", ErrNoFakeResponse, c.program, c.args)
+		return &Result{ExitCode: -1, Err: err}, err
+	}
+
+	if resp.Delay > 0 {
+		select {
+		case <-ctx.Done():
+			err := fmt.Errorf("command execution failed: %w", ctx.Err())
+			return &Result{ExitCode: -1, Err: ctx.Err()}, err
+		case <-time.After(resp.Delay):
+		}
+	}
+
+	return fakeResult(resp, options)
+}
+
+// fakeResult builds a Result from a scripted response, honoring the capture
+// options and delivering output to any configured writers
+func fakeResult(resp FakeResponse, options *Options) (*Result, error) {
+	captured := func(s string) string {
+		if options.StripANSI {
+			return StripANSI(s)
+		}
+		return s
+	}
+
+	// A pseudo-terminal merges both streams, as does combined capture
+	result := &Result{ExitCode: resp.ExitCode}
+	if options.CaptureCombined || options.PTY != nil {
+		result.Combined = captured(resp.Stdout + resp.Stderr)
+	} else {
+		if options.CaptureStdout {
+			result.Stdout = captured(resp.Stdout)
+		}
+		if options.CaptureStderr {
+			result.Stderr = captured(resp.Stderr)
+		}
+	}
+
+	if options.StdoutWriter != nil {
+		_, _ = options.StdoutWriter.Write([]byte(resp.Stdout))
+	}
+	if options.StderrWriter != nil {
+		_, _ = options.StderrWriter.Write([]byte(resp.Stderr))
+	}
+	if lines := newLineStreamer(options.LineHandler); lines != nil {
+		_, _ = lines.stdout.Write([]byte(resp.Stdout))
+		_, _ = lines.stderr.Write([]byte(resp.Stderr))
+		lines.Flush()
+	}
+
+	if resp.ExitCode != 0 {
+		result.Err = &ExitCodeError{Code: resp.ExitCode}
+		return result, fmt.Errorf("command execution failed: %w", result.Err)
+	}
+	return result, nil
+}
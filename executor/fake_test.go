@@ -0,0 +1,109 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+// gitVersion is an example of code that shells out through a Commander
+func gitVersion(ctx context.Context, c executor.Commander) (string, error) {
+	result, err := c.Command("git", "version").Execute(ctx, executor.WithEnvVar("LC_ALL", "C"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+func TestFakeScriptedResponses(t *testing.T) {
+	fake := executor.NewFake()
+	fake.On("git", "version").Return(executor.FakeResponse{Stdout: "git version 2.45.0\n"})
+	fake.On("git", "fetch").Return(executor.FakeResponse{Stderr: "network down", ExitCode: 128}).Times(1)
+	fake.On("git", "fetch").Return(executor.FakeResponse{})
+
+	version, err := gitVersion(context.Background(), fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != "git version 2.45.0" {
+		t.Errorf("unexpected version %q", version)
+	}
+
+	result, err := fake.Command("git", "fetch").Execute(context.Background())
+	var exitErr *executor.ExitCodeError
+	if !errors.As(err, &exitErr) || exitErr.Code != 128 {
+		t.Fatalf("expected exit code error 128, got %v", err)
+	}
+	if result.ExitCode != 128 || result.Stderr != "network down" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// The limited rule is exhausted, so the fallback rule answers
+	if _, err = fake.Command("git", "fetch").Execute(context.Background()); err != nil {
+		t.Errorf("expected second fetch to succeed, got %v", err)
+	}
+
+	_, err = fake.Command("git", "push").Execute(context.Background())
+	if !errors.Is(err, executor.ErrNoFakeResponse) {
+		t.Errorf("expected ErrNoFakeResponse, got %v", err)
+	}
+
+	calls := fake.Invocations()
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 invocations, got %d", len(calls))
+	}
+	if calls[0].Env["LC_ALL"] != "C" || calls[0].Options == nil {
+		t.Errorf("expected env and options to be recorded, got %+v", calls[0])
+	}
+}
+
+func TestFakeDelayHonorsContext(t *testing.T) {
+	fake := executor.NewFake()
+	fake.On("sleep").Return(executor.FakeResponse{Delay: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := fake.Command("sleep").Execute(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	recorder := executor.NewRecorder(nil)
+	ctx := context.Background()
+
+	if _, err := recorder.Command("echo", "hello").Execute(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := recorder.Command("sh", "-c", "exit 2").Execute(ctx); err == nil {
+		t.Fatal("expected error from failing command")
+	}
+
+	var buf bytes.Buffer
+	if _, err := recorder.Session().WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write session: %v", err)
+	}
+	session, err := executor.ReadSession(&buf)
+	if err != nil {
+		t.Fatalf("failed to read session: %v", err)
+	}
+
+	fake := executor.NewFake()
+	fake.Replay(session)
+
+	result, err := fake.Command("echo", "hello").Execute(ctx)
+	if err != nil || result.Stdout != "hello\n" {
+		t.Errorf("unexpected replay of echo: %+v, %v", result, err)
+	}
+	result, _ = fake.Command("sh", "-c", "exit 2").Execute(ctx)
+	if result.ExitCode != 2 {
+		t.Errorf("expected replayed exit code 2, got %d", result.ExitCode)
+	}
+}
@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"time"
+)
+
+// TruncationMarker is appended to captured output that exceeded MaxOutputBytes
+const TruncationMarker = "\n[output truncated]\n"
+
+// ErrTimeout is returned when an attempt exceeds the per-command Timeout
+var ErrTimeout = errors.New("command timed out")
+
+// ResourceLimits are rlimits applied to the command's process.
+// They are only supported on Linux; zero values leave a limit unchanged.
+type ResourceLimits struct {
+	CPUSeconds   uint64 // Maximum CPU time in seconds (RLIMIT_CPU)
+	AddressSpace uint64 // Maximum virtual memory in bytes (RLIMIT_AS)
+	OpenFiles    uint64 // Maximum number of open file descriptors (RLIMIT_NOFILE)
+}
+
+// Usage describes the resources consumed by a command
+type Usage struct {
+	Duration   time.Duration // Wall clock time from start to exit
+	UserTime   time.Duration // CPU time spent in user mode
+	SystemTime time.Duration // CPU time spent in kernel mode
+	MaxRSS     int64         // Peak resident set size in bytes (Linux only, 0 elsewhere)
+}
+
+// newUsage builds the Usage of an exited process
+func newUsage(state *os.ProcessState, duration time.Duration) Usage {
+	usage := Usage{Duration: duration}
+	if state != nil {
+		usage.UserTime = state.UserTime()
+		usage.SystemTime = state.SystemTime()
+		usage.MaxRSS = maxRSS(state)
+	}
+	return usage
+}
+
+// limitedBuffer is a bytes.Buffer that stops growing after limit bytes.
+// A limit of zero or less means unlimited.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write implements io.Writer, silently discarding bytes beyond the limit so
+// the process is never blocked or failed by a full buffer
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if b.limit > 0 {
+		remaining := b.limit - b.buf.Len()
+		if n > remaining {
+			b.truncated = true
+			p = p[:max(remaining, 0)]
+		}
+	}
+	b.buf.Write(p)
+	return n, nil
+}
+
+// String returns the captured output, followed by TruncationMarker if any
+// output was discarded
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + TruncationMarker
+	}
+	return b.buf.String()
+}
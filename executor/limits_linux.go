@@ -0,0 +1,56 @@
+//go:build linux
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// applyLimits sets the configured rlimits on a started process.
+// Limits are applied immediately after start, as os/exec offers no hook
+// between fork and exec.
+func applyLimits(pid int, limits *ResourceLimits) error {
+	if limits == nil {
+		return nil
+	}
+
+	set := []struct {
+		resource int
+		value    uint64
+		name     string
+	}{
+		{syscall.RLIMIT_CPU, limits.CPUSeconds, "CPU"},
+		{syscall.RLIMIT_AS, limits.AddressSpace, "address space"},
+		{syscall.RLIMIT_NOFILE, limits.OpenFiles, "open files"},
+	}
+
+	for _, l := range set {
+		if l.value == 0 {
+			continue
+		}
+		rlim := syscall.Rlimit{Cur: l.value, Max: l.value}
+		_, _, errno := syscall.RawSyscall6(
+			syscall.SYS_PRLIMIT64,
+			uintptr(pid),
+			uintptr(l.resource),
+			uintptr(unsafe.Pointer(&rlim)),
+			0, 0, 0,
+		)
+		if errno != 0 {
+			return fmt.Errorf("failed to set %s limit: %w", l.name, errno)
+		}
+	}
+
+	return nil
+}
+
+// maxRSS returns the peak resident set size of the process in bytes
+func maxRSS(state *os.ProcessState) int64 {
+	if rusage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return int64(rusage.Maxrss) * 1024 // Linux reports kilobytes
+	}
+	return 0
+}
@@ -0,0 +1,30 @@
+//go:build linux
+
+package executor_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+func TestResourceLimits(t *testing.T) {
+	// Sleep first, as limits are applied just after the process starts
+	cmd := executor.New("sh", "-c", "sleep 0.2; ulimit -n")
+	result, err := cmd.Execute(
+		context.Background(),
+		executor.WithResourceLimits(executor.ResourceLimits{OpenFiles: 64}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.TrimSpace(result.Stdout) != "64" {
+		t.Errorf("expected open files limit of 64, got %q", result.Stdout)
+	}
+	if result.Usage.MaxRSS <= 0 {
+		t.Errorf("expected max RSS to be reported, got %d", result.Usage.MaxRSS)
+	}
+}
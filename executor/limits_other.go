@@ -0,0 +1,22 @@
+//go:build !linux
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// applyLimits fails if any limits are configured, as rlimits are only supported on Linux
+func applyLimits(_ int, limits *ResourceLimits) error {
+	if limits == nil {
+		return nil
+	}
+	return fmt.Errorf("resource limits: %w", errors.ErrUnsupported)
+}
+
+// maxRSS is not reported on this platform
+func maxRSS(_ *os.ProcessState) int64 {
+	return 0
+}
@@ -0,0 +1,63 @@
+package executor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+func TestPerCommandTimeout(t *testing.T) {
+	cmd := executor.New("sleep", "5")
+	start := time.Now()
+	result, err := cmd.Execute(context.Background(), executor.WithTimeout(50*time.Millisecond))
+
+	if !errors.Is(err, executor.ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("expected timeout to stop the command promptly")
+	}
+	if result.Usage.Duration <= 0 {
+		t.Errorf("expected wall clock duration to be recorded")
+	}
+}
+
+func TestMaxOutputBytes(t *testing.T) {
+	cmd := executor.New("sh", "-c", "printf 0123456789; printf abc >&2")
+	result, err := cmd.Execute(context.Background(), executor.WithMaxOutputBytes(4))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !result.Truncated {
+		t.Error("expected result to be marked truncated")
+	}
+	if result.Stdout != "0123"+executor.TruncationMarker {
+		t.Errorf("unexpected truncated stdout %q", result.Stdout)
+	}
+	if result.Stderr != "abc" {
+		t.Errorf("expected stderr under the limit to be intact, got %q", result.Stderr)
+	}
+}
+
+func TestUsageAccounting(t *testing.T) {
+	cmd := executor.New("sh", "-c", "i=0; while [ $i -lt 20000 ]; do i=$((i+1)); done")
+	result, err := cmd.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Usage.Duration <= 0 {
+		t.Error("expected wall clock duration to be recorded")
+	}
+	if result.Usage.UserTime+result.Usage.SystemTime <= 0 {
+		t.Errorf("expected CPU time to be recorded, got %+v", result.Usage)
+	}
+	if result.Truncated || strings.Contains(result.Stdout, executor.TruncationMarker) {
+		t.Error("expected untruncated output without a limit")
+	}
+}
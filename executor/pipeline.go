@@ -0,0 +1,213 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// ErrEmptyPipeline is returned when a pipeline has no stages
+var ErrEmptyPipeline = errors.New("pipeline has no stages")
+
+// Pipeline connects the stdout of each command to the stdin of the next,
+// like a shell pipeline but without invoking a shell, so arguments are never
+// reinterpreted. Data streams between the processes through OS pipes.
+//
+// The pipeline fails if any stage fails (pipefail). Cancelling the context
+// stops every stage. Retries, per-attempt timeouts and PTY mode do not apply
+// to pipelines.
+type Pipeline struct {
+	stages []*CommandExecutor
+}
+
+// NewPipeline creates a pipeline from the given commands, in order
+func NewPipeline(stages ...*CommandExecutor) *Pipeline {
+	return &Pipeline{stages: stages}
+}
+
+// Pipe appends a command to the pipeline
+func (p *Pipeline) Pipe(stage *CommandExecutor) *Pipeline {
+	p.stages = append(p.stages, stage)
+	return p
+}
+
+// Execute implements the Executor interface
+func (p *Pipeline) Execute(ctx context.Context, opts ...Option) (*Result, error) {
+	return p.ExecuteWithInput(ctx, "", opts...)
+}
+
+// ExecuteWithInput implements the Executor interface. The input is written to
+// the first stage. Options apply to every stage on top of its own options.
+//
+// The returned Result carries the last stage's output, the exit code and
+// error of the rightmost failed stage, and every stage's Result in Stages.
+func (p *Pipeline) ExecuteWithInput(ctx context.Context, input string, opts ...Option) (*Result, error) {
+	if len(p.stages) == 0 {
+		return &Result{ExitCode: -1, Err: ErrEmptyPipeline}, ErrEmptyPipeline
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stages, err := p.prepare(ctx, input, opts)
+	defer func() {
+		for _, stage := range stages {
+			stage.options.secrets.clear()
+		}
+	}()
+	if err != nil {
+		return &Result{ExitCode: -1, Err: err}, err
+	}
+
+	startStages(stages, cancel)
+	for _, stage := range stages {
+		stage.wait()
+	}
+
+	return pipelineResult(stages)
+}
+
+// pipelineStage is the state of a single command within a running pipeline
+type pipelineStage struct {
+	executor *CommandExecutor
+	options  *Options
+	cmd      *exec.Cmd
+	out      *outputCapture
+	cleanup  func()
+	closers  []io.Closer // Pipe ends to close in the parent once the stage started
+	started  bool
+	start    time.Time
+	duration time.Duration
+	err      error
+}
+
+// prepare builds the commands of every stage and connects them with pipes
+func (p *Pipeline) prepare(ctx context.Context, input string, opts []Option) ([]*pipelineStage, error) {
+	stages := make([]*pipelineStage, 0, len(p.stages))
+
+	for i, c := range p.stages {
+		options := c.mergeOptions(opts...)
+		options.PTY = nil
+
+		secrets, err := resolveSecrets(ctx, options)
+		if err != nil {
+			return stages, err
+		}
+		options.secrets = secrets
+
+		cmd := exec.CommandContext(ctx, c.program, c.args...)
+		if i == 0 {
+			c.setupCommand(cmd, input, options)
+		} else {
+			c.setupCommand(cmd, "", options)
+		}
+
+		stages = append(stages, &pipelineStage{
+			executor: c,
+			options:  options,
+			cmd:      cmd,
+			out:      c.setupOutputCapture(cmd, options),
+			cleanup:  c.setupTermination(ctx, cmd, options),
+		})
+	}
+
+	for i := range len(stages) - 1 {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closeAll(stages)
+			return stages, fmt.Errorf("failed to create pipe: %w", err)
+		}
+		stages[i].cmd.Stdout = w
+		stages[i].closers = append(stages[i].closers, w)
+		stages[i+1].cmd.Stdin = r
+		stages[i+1].closers = append(stages[i+1].closers, r)
+	}
+
+	return stages, nil
+}
+
+// startStages starts every stage, releasing the parent's pipe ends as it
+// goes. If a stage fails to start, the rest of the pipeline is cancelled.
+func startStages(stages []*pipelineStage, cancel context.CancelFunc) {
+	for i, stage := range stages {
+		stage.start = time.Now()
+		stage.err = stage.cmd.Start()
+		if stage.err == nil {
+			stage.started = true
+			if err := applyLimits(stage.cmd.Process.Pid, stage.options.Limits); err != nil {
+				_ = stage.cmd.Process.Kill()
+				stage.err = err
+			}
+		}
+		closeAll(stages[i : i+1])
+
+		if stage.err != nil {
+			cancel()
+			for _, rest := range stages[i+1:] {
+				rest.err = fmt.Errorf("not started: %w", context.Canceled)
+			}
+			closeAll(stages[i+1:])
+			return
+		}
+	}
+}
+
+// wait waits for a started stage to exit and flushes its output
+func (s *pipelineStage) wait() {
+	if s.started {
+		if err := s.cmd.Wait(); s.err == nil {
+			s.err = err
+		}
+		s.duration = time.Since(s.start)
+	}
+	s.cleanup()
+	s.out.Flush()
+}
+
+// result builds the Result of a finished stage
+func (s *pipelineStage) result() *Result {
+	result := s.executor.createResult(s.out, s.err)
+	result.Signal = exitSignal(s.cmd.ProcessState)
+	result.Usage = newUsage(s.cmd.ProcessState, s.duration)
+	return result
+}
+
+// pipelineResult combines the stage results with pipefail semantics
+func pipelineResult(stages []*pipelineStage) (*Result, error) {
+	results := make([]*Result, len(stages))
+	failed := -1
+	for i, stage := range stages {
+		results[i] = stage.result()
+		if stage.err != nil {
+			failed = i
+		}
+	}
+
+	result := *results[len(results)-1]
+	result.Stages = results
+	if failed < 0 {
+		return &result, nil
+	}
+
+	result.ExitCode = results[failed].ExitCode
+	result.Signal = results[failed].Signal
+	result.Err = results[failed].Err
+	return &result, fmt.Errorf(
+		"command execution failed: pipeline stage %d (%s): %w",
+		failed+1, stages[failed].executor.program, result.Err,
+	)
+}
+
+// closeAll closes the parent's pipe ends of the given stages
+func closeAll(stages []*pipelineStage) {
+	for _, stage := range stages {
+		for _, c := range stage.closers {
+			_ = c.Close()
+		}
+		stage.closers = nil
+	}
+}
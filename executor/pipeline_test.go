@@ -0,0 +1,157 @@
+package executor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+func TestPipeline(t *testing.T) {
+	pipeline := executor.NewPipeline(
+		executor.New("printf", `c\nb\na\n`),
+		executor.New("sort"),
+	).Pipe(executor.New("head", "-n", "2"))
+
+	result, err := pipeline.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Stdout != "a\nb\n" {
+		t.Errorf("expected last stage output, got %q", result.Stdout)
+	}
+	if len(result.Stages) != 3 {
+		t.Fatalf("expected 3 stage results, got %d", len(result.Stages))
+	}
+	for i, stage := range result.Stages {
+		if stage.ExitCode != 0 {
+			t.Errorf("stage %d: expected exit code 0, got %d", i, stage.ExitCode)
+		}
+	}
+}
+
+func TestPipelineArgumentsAreNotInterpreted(t *testing.T) {
+	pipeline := executor.NewPipeline(
+		executor.New("echo", "$(id) ; | *"),
+		executor.New("tr", "a-z", "A-Z"),
+	)
+
+	result, err := pipeline.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Stdout != "$(ID) ; | *\n" {
+		t.Errorf("expected literal arguments, got %q", result.Stdout)
+	}
+}
+
+func TestPipelineFail(t *testing.T) {
+	pipeline := executor.NewPipeline(
+		executor.New("sh", "-c", "echo data; echo oops >&2; exit 2"),
+		executor.New("cat"),
+	)
+
+	result, err := pipeline.Execute(context.Background())
+	if err == nil {
+		t.Fatal("expected error from failing stage")
+	}
+
+	if result.ExitCode != 2 {
+		t.Errorf("expected exit code of failed stage, got %d", result.ExitCode)
+	}
+	if result.Stdout != "data\n" {
+		t.Errorf("expected downstream output, got %q", result.Stdout)
+	}
+	if result.Stages[0].Stderr != "oops\n" || result.Stages[1].ExitCode != 0 {
+		t.Errorf("unexpected stage results: %+v, %+v", result.Stages[0], result.Stages[1])
+	}
+}
+
+func TestPipelineWithInput(t *testing.T) {
+	pipeline := executor.NewPipeline(executor.New("cat"), executor.New("wc", "-l"))
+
+	result, err := pipeline.ExecuteWithInput(context.Background(), "one\ntwo\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.TrimSpace(result.Stdout) != "2" {
+		t.Errorf("expected 2 lines, got %q", result.Stdout)
+	}
+}
+
+func TestPipelineStreamsStdin(t *testing.T) {
+	const size = 4 << 20
+	input := strings.NewReader(strings.Repeat("x", size))
+	pipeline := executor.NewPipeline(executor.New("cat"), executor.New("wc", "-c"))
+
+	result, err := pipeline.Execute(context.Background(), executor.WithStdin(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.TrimSpace(result.Stdout) != "4194304" {
+		t.Errorf("expected %d bytes, got %q", size, result.Stdout)
+	}
+}
+
+func TestPipelineCancellation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	pipeline := executor.NewPipeline(executor.New("sleep", "10"), executor.New("cat"))
+
+	start := time.Now()
+	if _, err := pipeline.Execute(ctx); err == nil {
+		t.Fatal("expected error from cancelled pipeline")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("expected cancellation to stop every stage")
+	}
+}
+
+func TestPipelineStartFailure(t *testing.T) {
+	pipeline := executor.NewPipeline(
+		executor.New("sleep", "10"),
+		executor.New("nonexistent-command-12345"),
+		executor.New("cat"),
+	)
+
+	start := time.Now()
+	result, err := pipeline.Execute(context.Background())
+	if err == nil {
+		t.Fatal("expected error for missing program")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("expected the rest of the pipeline to be cancelled")
+	}
+	if len(result.Stages) != 3 || result.Stages[1].ExitCode != -1 {
+		t.Errorf("unexpected stage results: %+v", result.Stages)
+	}
+}
+
+func TestEmptyPipeline(t *testing.T) {
+	_, err := executor.NewPipeline().Execute(context.Background())
+	if !errors.Is(err, executor.ErrEmptyPipeline) {
+		t.Errorf("expected ErrEmptyPipeline, got %v", err)
+	}
+}
+
+func TestWithStdin(t *testing.T) {
+	result, err := executor.New("cat").Execute(context.Background(), executor.WithStdin(strings.NewReader("streamed")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Stdout != "streamed" {
+		t.Errorf("expected stdin to be streamed, got %q", result.Stdout)
+	}
+
+	fake := executor.NewFake()
+	fake.On("cat").Return(executor.FakeResponse{})
+	_, _ = fake.Command("cat").Execute(context.Background(), executor.WithStdin(strings.NewReader("recorded")))
+	if got := fake.Invocations()[0].Input; got != "recorded" {
+		t.Errorf("expected fake to record streamed stdin, got %q", got)
+	}
+}
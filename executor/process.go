@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// setupTermination configures how the command is stopped when ctx is cancelled.
+// With a grace period, SIGTERM is sent first and SIGKILL follows once the period
+// expires. With process groups, signals target the whole group instead of only
+// the direct child. The returned cleanup function must be called after the
+// command has exited.
+func (c *CommandExecutor) setupTermination(ctx context.Context, cmd *exec.Cmd, options *Options) func() {
+	if !options.ProcessGroup && options.GracePeriod <= 0 {
+		return func() {}
+	}
+
+	group := options.ProcessGroup
+	if group {
+		setProcessGroup(cmd)
+	}
+
+	var (
+		mu        sync.Mutex
+		killTimer *time.Timer
+	)
+
+	cmd.Cancel = func() error {
+		if options.GracePeriod <= 0 {
+			return killProcess(cmd, group)
+		}
+
+		mu.Lock()
+		killTimer = time.AfterFunc(options.GracePeriod, func() {
+			_ = killProcess(cmd, group)
+		})
+		mu.Unlock()
+
+		return terminateProcess(cmd, group)
+	}
+
+	// Stop waiting on output pipes held open by descendants once the
+	// grace period has passed.
+	if options.GracePeriod > 0 {
+		cmd.WaitDelay = options.GracePeriod
+	}
+
+	return func() {
+		mu.Lock()
+		if killTimer != nil {
+			killTimer.Stop()
+		}
+		mu.Unlock()
+
+		// Make sure nothing in the group outlives a cancelled command
+		if group && ctx.Err() != nil {
+			_ = killProcess(cmd, true)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !unix
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on platforms without POSIX process groups
+func setProcessGroup(_ *exec.Cmd) {}
+
+// terminateProcess falls back to killing the process, since graceful
+// termination signals are not available on this platform
+func terminateProcess(cmd *exec.Cmd, group bool) error {
+	return killProcess(cmd, group)
+}
+
+// killProcess forcibly stops the direct child process
+func killProcess(cmd *exec.Cmd, _ bool) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to kill process: %w", err)
+	}
+	return nil
+}
+
+// exitSignal always returns nil as exit signals are not reported on this platform
+//
+//nolint:ireturn // os.Signal is the portable representation of a signal
+func exitSignal(_ *os.ProcessState) os.Signal {
+	return nil
+}
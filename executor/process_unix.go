@@ -0,0 +1,64 @@
+//go:build unix
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command as the leader of a new process group
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// terminateProcess asks the process (or its group) to exit with SIGTERM
+func terminateProcess(cmd *exec.Cmd, group bool) error {
+	return signalProcess(cmd, syscall.SIGTERM, group)
+}
+
+// killProcess forcibly stops the process (or its group) with SIGKILL
+func killProcess(cmd *exec.Cmd, group bool) error {
+	return signalProcess(cmd, syscall.SIGKILL, group)
+}
+
+// signalProcess delivers sig to the process or, if group is set, to every
+// member of its process group
+func signalProcess(cmd *exec.Cmd, sig syscall.Signal, group bool) error {
+	if cmd.Process == nil {
+		return nil
+	}
+
+	var err error
+	if group {
+		err = syscall.Kill(-cmd.Process.Pid, sig)
+	} else {
+		err = cmd.Process.Signal(sig)
+	}
+
+	// The process may already be gone, which is what we wanted anyway
+	if err != nil && !errors.Is(err, syscall.ESRCH) && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to send %s: %w", sig, err)
+	}
+	return nil
+}
+
+// exitSignal returns the signal that terminated the process, if any
+//
+//nolint:ireturn // os.Signal is the portable representation of a signal
+func exitSignal(state *os.ProcessState) os.Signal {
+	if state == nil {
+		return nil
+	}
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return nil
+	}
+	return status.Signal()
+}
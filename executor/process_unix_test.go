@@ -0,0 +1,98 @@
+//go:build unix
+
+package executor_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+func TestGracePeriodSendsSIGTERM(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	cmd := executor.New("sleep", "5")
+	result, err := cmd.Execute(ctx, executor.WithGracePeriod(2*time.Second))
+	if err == nil {
+		t.Fatal("expected error after cancellation")
+	}
+
+	if result.Signal != syscall.SIGTERM {
+		t.Errorf("expected SIGTERM, got %v", result.Signal)
+	}
+}
+
+func TestGracePeriodEscalatesToSIGKILL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The trap only applies to the shell, so loop on short sleeps to keep it alive
+	cmd := executor.New("sh", "-c", `trap "" TERM; while :; do sleep 0.05; done`)
+	start := time.Now()
+	result, err := cmd.Execute(
+		ctx,
+		executor.WithProcessGroup(true),
+		executor.WithGracePeriod(200*time.Millisecond),
+	)
+	if err == nil {
+		t.Fatal("expected error after cancellation")
+	}
+
+	if result.Signal != syscall.SIGKILL {
+		t.Errorf("expected SIGKILL, got %v", result.Signal)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected termination shortly after grace period, took %v", elapsed)
+	}
+}
+
+func TestProcessGroupKillsDescendants(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pids := make(chan int, 1)
+	cmd := executor.New("sh", "-c", "sleep 30 & echo $!; wait")
+	_, err := cmd.Execute(
+		ctx,
+		executor.WithProcessGroup(true),
+		executor.WithGracePeriod(time.Second),
+		executor.WithLineHandler(func(ev executor.LineEvent) {
+			if pid, convErr := strconv.Atoi(ev.Line); convErr == nil {
+				pids <- pid
+				cancel()
+			}
+		}),
+	)
+	if err == nil {
+		t.Fatal("expected error after cancellation")
+	}
+
+	pid := <-pids
+	deadline := time.Now().Add(2 * time.Second)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("grandchild process %d still running after cancellation", pid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// processAlive reports whether pid is running, treating zombies as exited
+func processAlive(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat))
+	return len(fields) < 3 || fields[2] != "Z"
+}
@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// DefaultWindowSize is used for any zero dimension of a requested WindowSize
+var DefaultWindowSize = WindowSize{Rows: 24, Cols: 80}
+
+// terminalDrainTimeout bounds how long output is read after the command has
+// exited, in case a background descendant keeps the terminal open
+const terminalDrainTimeout = time.Second
+
+// WindowSize is the size of a pseudo-terminal in character cells
+type WindowSize struct {
+	Rows uint16
+	Cols uint16
+}
+
+// terminal is a pseudo-terminal attached to a running command
+type terminal struct {
+	master *os.File
+	slave  *os.File
+	copied chan struct{}
+}
+
+// startTerminal opens a pseudo-terminal, attaches it to the command's stdout
+// and stderr (and stdin, unless input was given) and copies everything the
+// command writes to it into w
+func startTerminal(cmd *exec.Cmd, size WindowSize, w io.Writer) (*terminal, error) {
+	if size.Rows == 0 {
+		size.Rows = DefaultWindowSize.Rows
+	}
+	if size.Cols == 0 {
+		size.Cols = DefaultWindowSize.Cols
+	}
+
+	master, slave, err := openTerminal(size)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd.Stdout = slave
+	cmd.Stderr = slave
+	if cmd.Stdin == nil {
+		cmd.Stdin = slave
+	}
+	setControllingTerminal(cmd)
+
+	t := &terminal{master: master, slave: slave, copied: make(chan struct{})}
+	go func() {
+		defer close(t.copied)
+		// Reading fails with EIO once every copy of the slave side is closed
+		_, _ = io.Copy(w, t.master)
+	}()
+
+	return t, nil
+}
+
+// Close reads any output still buffered in the terminal and releases it.
+// It must only be called after the command has exited.
+func (t *terminal) Close() {
+	if t == nil {
+		return
+	}
+
+	_ = t.slave.Close()
+	select {
+	case <-t.copied:
+	case <-time.After(terminalDrainTimeout):
+	}
+	_ = t.master.Close()
+	<-t.copied
+}
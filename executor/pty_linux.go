@@ -0,0 +1,103 @@
+//go:build linux
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// winsize mirrors struct winsize from <sys/ioctl.h>
+type winsize struct {
+	Rows   uint16
+	Cols   uint16
+	XPixel uint16
+	YPixel uint16
+}
+
+// openTerminal allocates a pseudo-terminal pair of the given size
+func openTerminal(size WindowSize) (master, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open pseudo-terminal: %w", err)
+	}
+
+	name, err := unlockTerminal(master, size)
+	if err != nil {
+		_ = master.Close()
+		return nil, nil, err
+	}
+
+	slave, err = os.OpenFile(name, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		_ = master.Close()
+		return nil, nil, fmt.Errorf("failed to open pseudo-terminal: %w", err)
+	}
+
+	return master, slave, nil
+}
+
+// unlockTerminal unlocks the slave side of the terminal, sets its size and
+// returns its path. The file descriptor is accessed through SyscallConn so
+// the master stays in non-blocking mode and Close can interrupt reads.
+func unlockTerminal(master *os.File, size WindowSize) (string, error) {
+	conn, err := master.SyscallConn()
+	if err != nil {
+		return "", fmt.Errorf("failed to access pseudo-terminal: %w", err)
+	}
+
+	var (
+		number   uint32
+		ioctlErr error
+	)
+	err = conn.Control(func(fd uintptr) {
+		unlock := int32(0)
+		if ioctlErr = ioctl(fd, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); ioctlErr != nil {
+			ioctlErr = fmt.Errorf("failed to unlock pseudo-terminal: %w", ioctlErr)
+			return
+		}
+		if ioctlErr = ioctl(fd, syscall.TIOCGPTN, unsafe.Pointer(&number)); ioctlErr != nil {
+			ioctlErr = fmt.Errorf("failed to get pseudo-terminal number: %w", ioctlErr)
+			return
+		}
+		ws := winsize{Rows: size.Rows, Cols: size.Cols}
+		if ioctlErr = ioctl(fd, syscall.TIOCSWINSZ, unsafe.Pointer(&ws)); ioctlErr != nil {
+			ioctlErr = fmt.Errorf("failed to set pseudo-terminal size: %w", ioctlErr)
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to access pseudo-terminal: %w", err)
+	}
+	if ioctlErr != nil {
+		return "", ioctlErr
+	}
+
+	return "/dev/pts/" + strconv.FormatUint(uint64(number), 10), nil
+}
+
+// ioctl performs a terminal ioctl on fd
+func ioctl(fd uintptr, req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// setControllingTerminal starts the command in a new session with its stdout
+// as the controlling terminal. A new session is also a new process group, so
+// signalling the group keeps working without Setpgid, which would fail for a
+// session leader.
+func setControllingTerminal(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = false
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	cmd.SysProcAttr.Ctty = 1
+}
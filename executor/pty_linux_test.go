@@ -0,0 +1,75 @@
+//go:build linux
+
+package executor_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+func TestPTY(t *testing.T) {
+	cmd := executor.New("sh", "-c", "test -t 1 && echo tty; stty size; echo err >&2")
+	result, err := cmd.Execute(
+		context.Background(),
+		executor.WithPTY(executor.WindowSize{Rows: 40, Cols: 120}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Combined != "tty\r\n40 120\r\nerr\r\n" {
+		t.Errorf("expected terminal output in Combined, got %q", result.Combined)
+	}
+	if result.Stdout != "" || result.Stderr != "" {
+		t.Errorf("expected separate streams to be empty, got %q and %q", result.Stdout, result.Stderr)
+	}
+}
+
+func TestPTYStripANSI(t *testing.T) {
+	cmd := executor.New("sh", "-c", `printf '\033[31mred\033[0m\n'; exit 3`)
+	result, err := cmd.Execute(
+		context.Background(),
+		executor.WithPTY(executor.WindowSize{}),
+		executor.WithStripANSI(true),
+	)
+	if err == nil {
+		t.Fatal("expected error for non-zero exit")
+	}
+
+	if result.ExitCode != 3 {
+		t.Errorf("expected exit code 3, got %d", result.ExitCode)
+	}
+	if result.Combined != "red\r\n" {
+		t.Errorf("expected stripped output, got %q", result.Combined)
+	}
+}
+
+func TestPTYWithInput(t *testing.T) {
+	cmd := executor.New("sh", "-c", "test -t 0 || echo piped; test -t 1 && echo tty; cat")
+	result, err := cmd.ExecuteWithInput(context.Background(), "hello", executor.WithPTY(executor.WindowSize{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(result.Combined, "piped\r\ntty\r\nhello") {
+		t.Errorf("expected piped input with terminal output, got %q", result.Combined)
+	}
+}
+
+func TestPTYProcessGroup(t *testing.T) {
+	cmd := executor.New("sh", "-c", "echo started")
+	result, err := cmd.Execute(
+		context.Background(),
+		executor.WithPTY(executor.WindowSize{}),
+		executor.WithProcessGroup(true),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Combined != "started\r\n" {
+		t.Errorf("unexpected output: %q", result.Combined)
+	}
+}
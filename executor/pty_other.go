@@ -0,0 +1,18 @@
+//go:build !linux
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// openTerminal fails, as pseudo-terminals are only supported on Linux
+func openTerminal(_ WindowSize) (master, slave *os.File, err error) {
+	return nil, nil, fmt.Errorf("pseudo-terminals: %w", errors.ErrUnsupported)
+}
+
+// setControllingTerminal is never reached on this platform
+func setControllingTerminal(_ *exec.Cmd) {}
@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Session is an ordered list of recorded invocations and their responses.
+// It can be serialized to JSON and replayed with Fake.Replay.
+type Session struct {
+	Entries []SessionEntry `json:"entries"`
+}
+
+// SessionEntry pairs a recorded invocation with the response it produced
+type SessionEntry struct {
+	Invocation Invocation   `json:"invocation"`
+	Response   FakeResponse `json:"response"`
+}
+
+// WriteTo writes the session as indented JSON
+func (s *Session) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode session: %w", err)
+	}
+	n, err := w.Write(append(data, '\n'))
+	if err != nil {
+		return int64(n), fmt.Errorf("failed to write session: %w", err)
+	}
+	return int64(n), nil
+}
+
+// ReadSession decodes a session previously written with Session.WriteTo
+func ReadSession(r io.Reader) (*Session, error) {
+	var s Session
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	return &s, nil
+}
+
+// Recorder is a Commander that runs real processes and records every
+// invocation and its outcome, so the session can later be replayed by a Fake.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu        sync.Mutex
+	commander Commander
+	entries   []SessionEntry
+}
+
+// NewRecorder creates a Recorder that runs commands through commander.
+// If commander is nil, real processes are run with default options.
+func NewRecorder(commander Commander) *Recorder {
+	if commander == nil {
+		commander = NewSystemCommander()
+	}
+	return &Recorder{commander: commander}
+}
+
+// Command implements Commander
+//
+//nolint:ireturn // returning the interface is the purpose of Commander
+func (r *Recorder) Command(program string, args ...string) Executor {
+	return &recordingCommand{
+		recorder: r,
+		program:  program,
+		args:     args,
+		inner:    r.commander.Command(program, args...),
+	}
+}
+
+// Session returns a snapshot of everything recorded so far
+func (r *Recorder) Session() *Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Session{Entries: slices.Clone(r.entries)}
+}
+
+// recordingCommand is the Executor returned by Recorder.Command
+type recordingCommand struct {
+	recorder *Recorder
+	program  string
+	args     []string
+	inner    Executor
+}
+
+// Execute implements the Executor interface
+func (c *recordingCommand) Execute(ctx context.Context, opts ...Option) (*Result, error) {
+	return c.ExecuteWithInput(ctx, "", opts...)
+}
+
+// ExecuteWithInput implements the Executor interface
+func (c *recordingCommand) ExecuteWithInput(ctx context.Context, input string, opts ...Option) (*Result, error) {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Keep a copy of streamed stdin so it can be recorded
+	var stdin bytes.Buffer
+	if options.Stdin != nil && input == "" {
+		opts = append(slices.Clone(opts), WithStdin(io.TeeReader(options.Stdin, &stdin)))
+	}
+
+	result, err := c.inner.ExecuteWithInput(ctx, input, opts...)
+	if stdin.Len() > 0 {
+		input = stdin.String()
+	}
+
+	entry := SessionEntry{
+		Invocation: Invocation{
+			Program:    c.program,
+			Args:       slices.Clone(c.args),
+			Input:      input,
+			WorkingDir: options.WorkingDir,
+			Env:        maps.Clone(options.Env),
+			Options:    options,
+		},
+	}
+	if result != nil {
+		// Keep combined output replayable by treating it as stdout
+		entry.Response = FakeResponse{
+			Stdout:   result.Stdout + result.Combined,
+			Stderr:   result.Stderr,
+			ExitCode: result.ExitCode,
+		}
+	}
+
+	c.recorder.mu.Lock()
+	c.recorder.entries = append(c.recorder.entries, entry)
+	c.recorder.mu.Unlock()
+
+	return result, err //nolint:wrapcheck // errors are passed through unchanged
+}
@@ -0,0 +1,150 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Attempt records the outcome of a single execution attempt
+type Attempt struct {
+	Number   int           // 1-based attempt number
+	ExitCode int           // Exit code of the attempt
+	Err      error         // Error returned by the attempt, nil on success
+	Start    time.Time     // When the attempt started
+	Duration time.Duration // How long the attempt ran
+	Delay    time.Duration // Wait before the next attempt, zero if none followed
+}
+
+// Backoff computes the delay before a retry.
+// retry is 1 for the first retry, and prev is the delay used before the
+// previous retry (zero for the first retry).
+type Backoff func(retry int, prev time.Duration) time.Duration
+
+// ConstantBackoff waits the same delay before every retry
+func ConstantBackoff(delay time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration {
+		return delay
+	}
+}
+
+// ExponentialBackoff doubles the delay before every retry, starting at
+// initial and never exceeding maxDelay
+func ExponentialBackoff(initial, maxDelay time.Duration) Backoff {
+	return func(retry int, _ time.Duration) time.Duration {
+		delay := initial
+		for i := 1; i < retry && delay < maxDelay; i++ {
+			delay *= 2
+		}
+		return min(delay, maxDelay)
+	}
+}
+
+// DecorrelatedJitterBackoff picks a random delay between base and three times
+// the previous delay, capped at maxDelay. This spreads out retries from many
+// clients while still growing the delay over time.
+func DecorrelatedJitterBackoff(base, maxDelay time.Duration) Backoff {
+	return func(_ int, prev time.Duration) time.Duration {
+		upper := max(prev*3, base)
+		return min(base+randDuration(upper-base), maxDelay)
+	}
+}
+
+// FullJitter randomizes the delays of another backoff to between zero and
+// the computed delay
+func FullJitter(b Backoff) Backoff {
+	return func(retry int, prev time.Duration) time.Duration {
+		return randDuration(b(retry, prev))
+	}
+}
+
+// randDuration returns a random duration in [0, d]
+func randDuration(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d + 1) //nolint:gosec // jitter does not need a secure source
+}
+
+// RetryOnExitCodes returns a result condition that retries only when the
+// command exited with one of the given codes
+func RetryOnExitCodes(codes ...int) func(*Result) bool {
+	return func(r *Result) bool {
+		return slices.Contains(codes, r.ExitCode)
+	}
+}
+
+// RetryOnStderrMatch returns a result condition that retries only when the
+// captured stderr matches the pattern
+func RetryOnStderrMatch(pattern *regexp.Regexp) func(*Result) bool {
+	return func(r *Result) bool {
+		return pattern.MatchString(r.Stderr) || pattern.MatchString(r.Combined)
+	}
+}
+
+// shouldRetry reports whether a failed attempt should be retried according
+// to the configured conditions. Both conditions must allow the retry when set.
+func shouldRetry(options *Options, result *Result, err error) bool {
+	if options.RetryOn != nil && !options.RetryOn(err) {
+		return false
+	}
+	if options.RetryOnResult != nil && !options.RetryOnResult(result) {
+		return false
+	}
+	return true
+}
+
+// executeWithRetry runs the command until it succeeds, the retry conditions
+// reject the failure, or the retry budget is exhausted. The returned result
+// carries the history of every attempt.
+func (c *CommandExecutor) executeWithRetry(
+	ctx context.Context,
+	input string,
+	options *Options,
+) (*Result, error) {
+	backoff := options.RetryBackoff
+	if backoff == nil {
+		backoff = ConstantBackoff(options.RetryDelay)
+	}
+
+	maxAttempts := options.MaxRetries + 1
+	firstStart := time.Now()
+	var attempts []Attempt
+	var delay time.Duration
+
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		result, err := c.executeOnce(ctx, input, options)
+		attempts = append(attempts, Attempt{
+			Number:   attempt,
+			ExitCode: result.ExitCode,
+			Err:      result.Err,
+			Start:    start,
+			Duration: time.Since(start),
+		})
+		result.Attempts = attempts
+
+		// Success or non-retryable error
+		if err == nil || attempt == maxAttempts || !shouldRetry(options, result, err) {
+			return result, err
+		}
+
+		// Give up if the next attempt would start past the elapsed budget
+		delay = backoff(attempt, delay)
+		if options.RetryMaxElapsed > 0 && time.Since(firstStart)+delay > options.RetryMaxElapsed {
+			return result, err
+		}
+		attempts[len(attempts)-1].Delay = delay
+
+		// Wait before retry
+		select {
+		case <-ctx.Done():
+			return result, fmt.Errorf("context cancelled during retry: %w", ctx.Err())
+		case <-time.After(delay):
+			// Continue to next attempt
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package executor_test
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+func TestBackoffPolicies(t *testing.T) {
+	exp := executor.ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	expected := []time.Duration{10, 20, 40, 50, 50}
+	for i, want := range expected {
+		if got := exp(i+1, 0); got != want*time.Millisecond {
+			t.Errorf("exponential retry %d: expected %v, got %v", i+1, want*time.Millisecond, got)
+		}
+	}
+
+	jitter := executor.DecorrelatedJitterBackoff(10*time.Millisecond, 100*time.Millisecond)
+	prev := time.Duration(0)
+	for i := 1; i <= 20; i++ {
+		d := jitter(i, prev)
+		if d < 10*time.Millisecond || d > 100*time.Millisecond {
+			t.Fatalf("decorrelated jitter retry %d out of bounds: %v", i, d)
+		}
+		prev = d
+	}
+
+	full := executor.FullJitter(executor.ConstantBackoff(5 * time.Millisecond))
+	for i := 1; i <= 20; i++ {
+		if d := full(i, 0); d < 0 || d > 5*time.Millisecond {
+			t.Fatalf("full jitter retry %d out of bounds: %v", i, d)
+		}
+	}
+}
+
+func TestRetryAttemptHistory(t *testing.T) {
+	// Fails with exit code 3 until the marker file exists
+	marker := filepath.Join(t.TempDir(), "marker")
+	script := `if [ -f "$1" ]; then echo ok; else touch "$1"; echo transient >&2; exit 3; fi`
+
+	cmd := executor.New("sh", "-c", script, "sh", marker)
+	result, err := cmd.Execute(
+		context.Background(),
+		executor.WithRetry(3, 0),
+		executor.WithRetryBackoff(executor.ConstantBackoff(time.Millisecond)),
+		executor.WithRetryResultCondition(executor.RetryOnExitCodes(3)),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(result.Attempts))
+	}
+	first := result.Attempts[0]
+	if first.ExitCode != 3 || first.Err == nil || first.Delay != time.Millisecond {
+		t.Errorf("unexpected first attempt: %+v", first)
+	}
+	if last := result.Attempts[1]; last.ExitCode != 0 || last.Err != nil || last.Number != 2 {
+		t.Errorf("unexpected last attempt: %+v", last)
+	}
+}
+
+func TestRetryResultConditionStopsRetrying(t *testing.T) {
+	cmd := executor.New("sh", "-c", "echo permission denied >&2; exit 1")
+	result, err := cmd.Execute(
+		context.Background(),
+		executor.WithRetry(3, time.Millisecond),
+		executor.WithRetryResultCondition(executor.RetryOnStderrMatch(regexp.MustCompile(`timed out`))),
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(result.Attempts) != 1 {
+		t.Errorf("expected no retries for non-matching stderr, got %d attempts", len(result.Attempts))
+	}
+}
+
+func TestRetryMaxElapsed(t *testing.T) {
+	cmd := executor.New("false")
+	start := time.Now()
+	result, err := cmd.Execute(
+		context.Background(),
+		executor.WithRetry(100, 0),
+		executor.WithRetryBackoff(executor.ConstantBackoff(20*time.Millisecond)),
+		executor.WithRetryMaxElapsed(100*time.Millisecond),
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected retries to stop within the elapsed budget, took %v", elapsed)
+	}
+	if n := len(result.Attempts); n < 2 || n > 6 {
+		t.Errorf("expected a handful of attempts within budget, got %d", n)
+	}
+}
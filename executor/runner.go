@@ -0,0 +1,381 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidGraph is returned when tasks have duplicate names, unknown
+// dependencies or dependency cycles
+var ErrInvalidGraph = errors.New("invalid task graph")
+
+// ErrTaskFailed is returned by Runner.Run when one or more tasks failed
+var ErrTaskFailed = errors.New("task failed")
+
+// Task is a named command run by a Runner once all of its dependencies succeed
+type Task struct {
+	Name      string
+	Executor  Executor
+	Input     string   // Optional stdin input
+	Options   []Option // Applied after the runner's shared options
+	DependsOn []string // Names of tasks that must succeed first
+}
+
+// TaskStatus describes the outcome of a task
+type TaskStatus int
+
+const (
+	// TaskSucceeded indicates the task ran and exited successfully
+	TaskSucceeded TaskStatus = iota
+
+	// TaskFailed indicates the task ran and failed
+	TaskFailed
+
+	// TaskSkipped indicates the task did not run because a dependency failed
+	TaskSkipped
+
+	// TaskCancelled indicates the task did not run because the run was aborted
+	TaskCancelled
+)
+
+// String returns a human-readable representation of the status
+func (s TaskStatus) String() string {
+	switch s {
+	case TaskSucceeded:
+		return "succeeded"
+	case TaskFailed:
+		return "failed"
+	case TaskSkipped:
+		return "skipped"
+	case TaskCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// TaskResult holds the outcome of a single task
+type TaskResult struct {
+	Name     string
+	Status   TaskStatus
+	Result   *Result // nil if the task did not run
+	Err      error
+	Start    time.Time
+	Duration time.Duration
+}
+
+// RunSummary holds the outcome of every task in a run
+type RunSummary struct {
+	Results   map[string]*TaskResult
+	Order     []string // Task names in the order they finished
+	Succeeded int
+	Failed    int
+	Skipped   int
+	Cancelled int
+	Duration  time.Duration
+}
+
+// Runner executes a graph of tasks with bounded parallelism
+type Runner struct {
+	parallelism int
+	failFast    bool
+	options     []Option
+}
+
+// RunnerOption is a function that modifies a Runner
+type RunnerOption func(*Runner)
+
+// NewRunner creates a Runner. By default it runs up to runtime.NumCPU()
+// tasks at once and continues with independent tasks after a failure.
+func NewRunner(opts ...RunnerOption) *Runner {
+	r := &Runner{parallelism: runtime.NumCPU()}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithParallelism sets the maximum number of tasks running at once
+func WithParallelism(n int) RunnerOption {
+	return func(r *Runner) {
+		r.parallelism = max(n, 1)
+	}
+}
+
+// WithFailFast cancels running tasks and starts no new ones after the first failure
+func WithFailFast(failFast bool) RunnerOption {
+	return func(r *Runner) {
+		r.failFast = failFast
+	}
+}
+
+// WithTaskOptions sets options applied to every task before its own options
+func WithTaskOptions(opts ...Option) RunnerOption {
+	return func(r *Runner) {
+		r.options = append(r.options, opts...)
+	}
+}
+
+// Run executes the tasks, starting each one once all of its dependencies
+// have succeeded. Tasks whose dependencies failed are skipped. It returns
+// ErrTaskFailed if any task failed, alongside the full summary.
+func (r *Runner) Run(ctx context.Context, tasks []Task) (*RunSummary, error) {
+	graph, err := newTaskGraph(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	run := &taskRun{
+		runner:  r,
+		graph:   graph,
+		summary: &RunSummary{Results: make(map[string]*TaskResult, len(tasks))},
+		done:    make(chan *TaskResult),
+	}
+	start := time.Now()
+	run.execute(runCtx, cancel)
+	run.summary.Duration = time.Since(start)
+
+	if err := run.err(); err != nil {
+		return run.summary, err
+	}
+	if err := ctx.Err(); err != nil {
+		return run.summary, fmt.Errorf("run cancelled: %w", err)
+	}
+	return run.summary, nil
+}
+
+// taskGraph is a validated set of tasks with their dependency edges
+type taskGraph struct {
+	tasks      []Task
+	index      map[string]int
+	dependents map[string][]string
+}
+
+// newTaskGraph validates the tasks and builds the dependency graph
+func newTaskGraph(tasks []Task) (*taskGraph, error) {
+	g := &taskGraph{
+		tasks:      tasks,
+		index:      make(map[string]int, len(tasks)),
+		dependents: make(map[string][]string, len(tasks)),
+	}
+
+	for i, task := range tasks {
+		if task.Name == "" {
+			return nil, fmt.Errorf("%w: task %d has no name", ErrInvalidGraph, i)
+		}
+		if task.Executor == nil {
+			return nil, fmt.Errorf("%w: task %q has no executor", ErrInvalidGraph, task.Name)
+		}
+		if _, exists := g.index[task.Name]; exists {
+			return nil, fmt.Errorf("%w: duplicate task %q", ErrInvalidGraph, task.Name)
+		}
+		g.index[task.Name] = i
+	}
+
+	for _, task := range tasks {
+		for _, dep := range task.DependsOn {
+			if _, ok := g.index[dep]; !ok {
+				return nil, fmt.Errorf("%w: task %q depends on unknown task %q", ErrInvalidGraph, task.Name, dep)
+			}
+			g.dependents[dep] = append(g.dependents[dep], task.Name)
+		}
+	}
+
+	if cycle := g.findCycle(); cycle != nil {
+		return nil, fmt.Errorf("%w: dependency cycle %s", ErrInvalidGraph, strings.Join(cycle, " -> "))
+	}
+
+	return g, nil
+}
+
+// findCycle returns the tasks forming a dependency cycle, or nil if there is none
+func (g *taskGraph) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(g.tasks))
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range g.tasks[g.index[name]].DependsOn {
+			switch state[dep] {
+			case visiting:
+				for i, p := range path {
+					if p == dep {
+						return append(append([]string(nil), path[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, task := range g.tasks {
+		if state[task.Name] == unvisited {
+			if cycle := visit(task.Name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// taskRun holds the scheduling state of a single Runner.Run call
+type taskRun struct {
+	runner  *Runner
+	graph   *taskGraph
+	summary *RunSummary
+	done    chan *TaskResult
+	errs    []error
+}
+
+// execute schedules tasks until every task has a result
+func (t *taskRun) execute(ctx context.Context, cancel context.CancelFunc) {
+	remaining := make(map[string]int, len(t.graph.tasks))
+	var ready []string
+	for _, task := range t.graph.tasks {
+		remaining[task.Name] = len(task.DependsOn)
+		if len(task.DependsOn) == 0 {
+			ready = append(ready, task.Name)
+		}
+	}
+
+	var wg sync.WaitGroup
+	running := 0
+	aborted := false
+
+	for {
+		// Start as many ready tasks as parallelism allows
+		for len(ready) > 0 && running < t.runner.parallelism && !aborted && ctx.Err() == nil {
+			name := ready[0]
+			ready = ready[1:]
+			running++
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				t.done <- t.runTask(ctx, t.graph.tasks[t.graph.index[name]])
+			}()
+		}
+
+		if running == 0 {
+			break
+		}
+
+		result := <-t.done
+		running--
+
+		// Tasks interrupted by an abort or parent cancellation did not fail on their own
+		if result.Status == TaskFailed && ctx.Err() != nil {
+			result.Status = TaskCancelled
+		}
+		t.record(result)
+
+		switch {
+		case result.Status == TaskCancelled:
+			continue
+		case result.Status == TaskFailed && t.runner.failFast:
+			aborted = true
+			cancel()
+			continue
+		case result.Status == TaskFailed:
+			t.skipDependents(result.Name)
+			continue
+		}
+
+		for _, dependent := range t.graph.dependents[result.Name] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 && t.summary.Results[dependent] == nil {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	wg.Wait()
+
+	// Anything left never started because the run was aborted or cancelled
+	for _, task := range t.graph.tasks {
+		if t.summary.Results[task.Name] == nil {
+			t.record(&TaskResult{Name: task.Name, Status: TaskCancelled})
+		}
+	}
+}
+
+// runTask executes a single task with the runner's shared options
+func (t *taskRun) runTask(ctx context.Context, task Task) *TaskResult {
+	opts := append(append([]Option(nil), t.runner.options...), task.Options...)
+
+	start := time.Now()
+	result, err := task.Executor.ExecuteWithInput(ctx, task.Input, opts...)
+
+	status := TaskSucceeded
+	if err != nil {
+		status = TaskFailed
+	}
+	return &TaskResult{
+		Name:     task.Name,
+		Status:   status,
+		Result:   result,
+		Err:      err,
+		Start:    start,
+		Duration: time.Since(start),
+	}
+}
+
+// skipDependents marks every transitive dependent of a failed task as skipped
+func (t *taskRun) skipDependents(name string) {
+	for _, dependent := range t.graph.dependents[name] {
+		if t.summary.Results[dependent] != nil {
+			continue
+		}
+		t.record(&TaskResult{
+			Name:   dependent,
+			Status: TaskSkipped,
+			Err:    fmt.Errorf("dependency %q did not succeed", name),
+		})
+		t.skipDependents(dependent)
+	}
+}
+
+// record stores a task result and updates the summary counters
+func (t *taskRun) record(result *TaskResult) {
+	t.summary.Results[result.Name] = result
+	t.summary.Order = append(t.summary.Order, result.Name)
+
+	switch result.Status {
+	case TaskSucceeded:
+		t.summary.Succeeded++
+	case TaskFailed:
+		t.summary.Failed++
+		t.errs = append(t.errs, fmt.Errorf("task %q: %w", result.Name, result.Err))
+	case TaskSkipped:
+		t.summary.Skipped++
+	case TaskCancelled:
+		t.summary.Cancelled++
+	}
+}
+
+// err returns ErrTaskFailed joined with every task failure, or nil
+func (t *taskRun) err() error {
+	if len(t.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrTaskFailed, errors.Join(t.errs...))
+}
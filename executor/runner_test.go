@@ -0,0 +1,158 @@
+package executor_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+func TestRunnerDependencyOrder(t *testing.T) {
+	fake := executor.NewFake()
+	fake.OnMatch(func(string, []string) bool { return true }).
+		Return(executor.FakeResponse{Stdout: "ok", Delay: 10 * time.Millisecond})
+
+	tasks := []executor.Task{
+		{Name: "test", Executor: fake.Command("go", "test"), DependsOn: []string{"build"}},
+		{Name: "build", Executor: fake.Command("go", "build"), DependsOn: []string{"generate"}},
+		{Name: "generate", Executor: fake.Command("go", "generate")},
+		{Name: "lint", Executor: fake.Command("golangci-lint", "run")},
+	}
+
+	summary, err := executor.NewRunner(executor.WithParallelism(2)).Run(context.Background(), tasks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if summary.Succeeded != 4 {
+		t.Errorf("expected 4 successful tasks, got %+v", summary)
+	}
+	order := summary.Order
+	if slices.Index(order, "generate") > slices.Index(order, "build") ||
+		slices.Index(order, "build") > slices.Index(order, "test") {
+		t.Errorf("tasks finished out of dependency order: %v", order)
+	}
+	if summary.Results["test"].Result.Stdout != "ok" {
+		t.Errorf("expected per-task result, got %+v", summary.Results["test"])
+	}
+}
+
+func TestRunnerBoundedParallelism(t *testing.T) {
+	var current, peak atomic.Int32
+	tasks := make([]executor.Task, 6)
+	for i := range tasks {
+		tasks[i] = executor.Task{
+			Name:     string(rune('a' + i)),
+			Executor: &countingExecutor{inner: executor.New("sleep", "0.05"), current: &current, peak: &peak},
+		}
+	}
+
+	if _, err := executor.NewRunner(executor.WithParallelism(2)).Run(context.Background(), tasks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent tasks, saw %d", peak.Load())
+	}
+}
+
+func TestRunnerContinueOnError(t *testing.T) {
+	fake := executor.NewFake()
+	fake.On("fail").Return(executor.FakeResponse{ExitCode: 1})
+	fake.On("ok").Return(executor.FakeResponse{})
+
+	tasks := []executor.Task{
+		{Name: "broken", Executor: fake.Command("fail")},
+		{Name: "dependent", Executor: fake.Command("ok"), DependsOn: []string{"broken"}},
+		{Name: "transitive", Executor: fake.Command("ok"), DependsOn: []string{"dependent"}},
+		{Name: "independent", Executor: fake.Command("ok")},
+	}
+
+	summary, err := executor.NewRunner().Run(context.Background(), tasks)
+	if !errors.Is(err, executor.ErrTaskFailed) {
+		t.Fatalf("expected ErrTaskFailed, got %v", err)
+	}
+
+	want := map[string]executor.TaskStatus{
+		"broken":      executor.TaskFailed,
+		"dependent":   executor.TaskSkipped,
+		"transitive":  executor.TaskSkipped,
+		"independent": executor.TaskSucceeded,
+	}
+	for name, status := range want {
+		if got := summary.Results[name].Status; got != status {
+			t.Errorf("task %s: expected %s, got %s", name, status, got)
+		}
+	}
+}
+
+func TestRunnerFailFast(t *testing.T) {
+	tasks := []executor.Task{
+		{Name: "broken", Executor: executor.New("false")},
+		{Name: "slow", Executor: executor.New("sleep", "5")},
+		{Name: "later", Executor: executor.New("true"), DependsOn: []string{"slow"}},
+	}
+
+	start := time.Now()
+	summary, err := executor.NewRunner(executor.WithFailFast(true)).Run(context.Background(), tasks)
+	if !errors.Is(err, executor.ErrTaskFailed) {
+		t.Fatalf("expected ErrTaskFailed, got %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Error("expected fail-fast to cancel running tasks")
+	}
+	if summary.Failed != 1 || summary.Cancelled != 2 {
+		t.Errorf("expected 1 failed and 2 cancelled tasks, got %+v", summary)
+	}
+}
+
+func TestRunnerInvalidGraph(t *testing.T) {
+	ok := executor.New("true")
+	cases := map[string][]executor.Task{
+		"duplicate": {{Name: "a", Executor: ok}, {Name: "a", Executor: ok}},
+		"unknown":   {{Name: "a", Executor: ok, DependsOn: []string{"missing"}}},
+		"cycle": {
+			{Name: "a", Executor: ok, DependsOn: []string{"b"}},
+			{Name: "b", Executor: ok, DependsOn: []string{"a"}},
+		},
+	}
+
+	for name, tasks := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := executor.NewRunner().Run(context.Background(), tasks)
+			if !errors.Is(err, executor.ErrInvalidGraph) {
+				t.Errorf("expected ErrInvalidGraph, got %v", err)
+			}
+		})
+	}
+}
+
+// countingExecutor tracks how many executions are in flight
+type countingExecutor struct {
+	inner   executor.Executor
+	current *atomic.Int32
+	peak    *atomic.Int32
+}
+
+func (c *countingExecutor) Execute(ctx context.Context, opts ...executor.Option) (*executor.Result, error) {
+	return c.ExecuteWithInput(ctx, "", opts...)
+}
+
+func (c *countingExecutor) ExecuteWithInput(
+	ctx context.Context,
+	input string,
+	opts ...executor.Option,
+) (*executor.Result, error) {
+	n := c.current.Add(1)
+	defer c.current.Add(-1)
+	for {
+		p := c.peak.Load()
+		if n <= p || c.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	return c.inner.ExecuteWithInput(ctx, input, opts...)
+}
@@ -0,0 +1,196 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// RedactionMask replaces every occurrence of a secret value in command output
+const RedactionMask = "***"
+
+// SecretSource resolves a secret value just in time.
+// A *core.SecretString from the secrets/core module satisfies this interface
+// directly; an already resolved *core.Secret can be adapted with FromSecret.
+type SecretSource interface {
+	Bytes(ctx context.Context) ([]byte, error)
+}
+
+// FromSecret adapts an already resolved secret (such as *core.Secret) to a SecretSource
+//
+//nolint:ireturn // adapters return the interface they adapt to
+func FromSecret(s interface{ Bytes() []byte }) SecretSource {
+	return resolvedSecret{s: s}
+}
+
+// resolvedSecret adapts a value exposing Bytes() to SecretSource
+type resolvedSecret struct {
+	s interface{ Bytes() []byte }
+}
+
+// Bytes implements SecretSource
+func (r resolvedSecret) Bytes(context.Context) ([]byte, error) {
+	return r.s.Bytes(), nil
+}
+
+// resolvedSecrets holds secret values for the duration of a single execution
+type resolvedSecrets struct {
+	env    map[string][]byte
+	input  []byte
+	masked [][]byte
+}
+
+// resolveSecrets resolves every secret referenced by the options once, so
+// one-time-use secrets survive retries
+func resolveSecrets(ctx context.Context, options *Options) (*resolvedSecrets, error) {
+	if len(options.SecretEnv) == 0 && options.SecretInput == nil && len(options.Redact) == 0 {
+		return nil, nil
+	}
+
+	resolved := &resolvedSecrets{env: make(map[string][]byte, len(options.SecretEnv))}
+
+	for key, source := range options.SecretEnv {
+		value, err := source.Bytes(ctx)
+		if err != nil {
+			resolved.clear()
+			return nil, fmt.Errorf("failed to resolve secret for env var %s: %w", key, err)
+		}
+		resolved.env[key] = value
+		resolved.mask(value)
+	}
+
+	if options.SecretInput != nil {
+		value, err := options.SecretInput.Bytes(ctx)
+		if err != nil {
+			resolved.clear()
+			return nil, fmt.Errorf("failed to resolve secret input: %w", err)
+		}
+		resolved.input = value
+		resolved.mask(value)
+	}
+
+	for _, value := range options.Redact {
+		resolved.mask([]byte(value))
+	}
+
+	// Prefer the longest match when secrets overlap
+	sort.SliceStable(resolved.masked, func(i, j int) bool {
+		return len(resolved.masked[i]) > len(resolved.masked[j])
+	})
+
+	return resolved, nil
+}
+
+// mask registers a value to be redacted from output
+func (s *resolvedSecrets) mask(value []byte) {
+	if len(value) > 0 {
+		s.masked = append(s.masked, value)
+	}
+}
+
+// hasEnv reports whether any secret environment variables were resolved
+func (s *resolvedSecrets) hasEnv() bool {
+	return s != nil && len(s.env) > 0
+}
+
+// clear zeroes every resolved value
+func (s *resolvedSecrets) clear() {
+	if s == nil {
+		return
+	}
+	for _, value := range s.env {
+		clear(value)
+	}
+	clear(s.input)
+	for _, value := range s.masked {
+		clear(value)
+	}
+}
+
+// redactingWriter masks secret values before forwarding output to w.
+// Output that could be the start of a secret is held back until the next
+// write (or Flush) shows whether it is.
+type redactingWriter struct {
+	w       io.Writer
+	secrets [][]byte
+	pending []byte
+}
+
+// newRedactingWriter wraps w, or returns nil if there is nothing to redact
+func newRedactingWriter(w io.Writer, secrets [][]byte) *redactingWriter {
+	if w == nil || len(secrets) == 0 {
+		return nil
+	}
+	return &redactingWriter{w: w, secrets: secrets}
+}
+
+// Write implements io.Writer
+func (r *redactingWriter) Write(p []byte) (int, error) {
+	data := append(r.pending, p...)
+	out, held := r.redact(data)
+	r.pending = slices.Clone(held)
+
+	if len(out) > 0 {
+		if _, err := r.w.Write(out); err != nil {
+			return 0, fmt.Errorf("failed to write redacted output: %w", err)
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes any held back output, which can no longer be part of a secret
+func (r *redactingWriter) Flush() error {
+	if len(r.pending) == 0 {
+		return nil
+	}
+	pending := r.pending
+	r.pending = nil
+	if _, err := r.w.Write(pending); err != nil {
+		return fmt.Errorf("failed to write redacted output: %w", err)
+	}
+	return nil
+}
+
+// redact masks complete secrets in data and splits off a trailing part that
+// may be the beginning of a secret
+func (r *redactingWriter) redact(data []byte) (out, held []byte) {
+	out = make([]byte, 0, len(data))
+
+	for i := 0; i < len(data); {
+		if n := r.matchAt(data[i:]); n > 0 {
+			out = append(out, RedactionMask...)
+			i += n
+			continue
+		}
+		if r.partialAt(data[i:]) {
+			return out, data[i:]
+		}
+		out = append(out, data[i])
+		i++
+	}
+
+	return out, nil
+}
+
+// matchAt returns the length of the secret that data starts with, or 0
+func (r *redactingWriter) matchAt(data []byte) int {
+	for _, secret := range r.secrets {
+		if bytes.HasPrefix(data, secret) {
+			return len(secret)
+		}
+	}
+	return 0
+}
+
+// partialAt reports whether data is a proper prefix of any secret
+func (r *redactingWriter) partialAt(data []byte) bool {
+	for _, secret := range r.secrets {
+		if len(data) < len(secret) && bytes.HasPrefix(secret, data) {
+			return true
+		}
+	}
+	return false
+}
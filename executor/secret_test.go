@@ -0,0 +1,98 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+// oneTimeSecret mimics a one-time-use core.SecretString
+type oneTimeSecret struct {
+	value    string
+	resolved int
+}
+
+func (s *oneTimeSecret) Bytes(context.Context) ([]byte, error) {
+	s.resolved++
+	if s.resolved > 1 {
+		return nil, errors.New("secret has already been consumed")
+	}
+	return []byte(s.value), nil
+}
+
+// staticSecret mimics an already resolved core.Secret
+type staticSecret struct {
+	value []byte
+}
+
+func (s *staticSecret) Bytes() []byte {
+	return append([]byte(nil), s.value...)
+}
+
+func TestSecretEnvIsRedacted(t *testing.T) {
+	secret := &oneTimeSecret{value: "s3cr3t-token"}
+	var custom bytes.Buffer
+	var lines []string
+
+	cmd := executor.New("sh", "-c", `echo "token=$API_TOKEN"; echo "$API_TOKEN" >&2; exit 1`)
+	result, err := cmd.Execute(
+		context.Background(),
+		executor.WithSecretEnv("API_TOKEN", secret),
+		executor.WithStdoutWriter(&custom),
+		executor.WithLineHandler(func(ev executor.LineEvent) {
+			lines = append(lines, ev.Line)
+		}),
+		executor.WithRetry(1, time.Millisecond),
+	)
+	if err == nil {
+		t.Fatal("expected error from failing command")
+	}
+
+	if secret.resolved != 1 {
+		t.Errorf("expected secret to be resolved once across retries, got %d", secret.resolved)
+	}
+	if len(result.Attempts) != 2 {
+		t.Errorf("expected both attempts to run with the secret, got %d", len(result.Attempts))
+	}
+
+	outputs := []string{result.Stdout, result.Stderr, custom.String(), strings.Join(lines, "\n")}
+	for _, out := range outputs {
+		if strings.Contains(out, "s3cr3t") {
+			t.Errorf("secret leaked into output: %q", out)
+		}
+	}
+	if !strings.Contains(result.Stdout, "token="+executor.RedactionMask) {
+		t.Errorf("expected masked value in stdout, got %q", result.Stdout)
+	}
+}
+
+func TestSecretSplitAcrossWrites(t *testing.T) {
+	cmd := executor.New("sh", "-c", `printf "before tok"; sleep 0.05; printf "en-123 after"`)
+	result, err := cmd.Execute(context.Background(), executor.WithRedact("token-123"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Stdout != "before "+executor.RedactionMask+" after" {
+		t.Errorf("unexpected redacted output %q", result.Stdout)
+	}
+}
+
+func TestSecretInput(t *testing.T) {
+	secret := executor.FromSecret(&staticSecret{value: []byte("hunter2")})
+
+	cmd := executor.New("cat")
+	result, err := cmd.ExecuteWithInput(context.Background(), "ignored", executor.WithSecretInput(secret))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Stdout != executor.RedactionMask {
+		t.Errorf("expected stdin secret to be echoed masked, got %q", result.Stdout)
+	}
+}
@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// Stream identifies the output stream a line was read from
+type Stream int
+
+const (
+	// StreamStdout identifies lines written to standard output
+	StreamStdout Stream = iota
+
+	// StreamStderr identifies lines written to standard error
+	StreamStderr
+)
+
+// String returns the conventional name of the stream
+func (s Stream) String() string {
+	switch s {
+	case StreamStdout:
+		return "stdout"
+	case StreamStderr:
+		return "stderr"
+	default:
+		return "unknown"
+	}
+}
+
+// LineEvent is a single line of output emitted while a command is running
+type LineEvent struct {
+	// Stream is the stream the line was written to
+	Stream Stream
+
+	// Line is the line content without the trailing newline
+	Line string
+
+	// Time is when the line was completed by the process
+	Time time.Time
+}
+
+// LineHandler receives line events as they are produced.
+// Calls are serialized, so a handler never runs concurrently with itself.
+type LineHandler func(LineEvent)
+
+// lineStreamer splits stdout and stderr into lines and dispatches them
+// to a LineHandler in the order they are completed.
+type lineStreamer struct {
+	mu      sync.Mutex
+	handler LineHandler
+	stdout  *lineWriter
+	stderr  *lineWriter
+}
+
+// newLineStreamer creates a streamer for the handler, or nil if no handler is set
+func newLineStreamer(handler LineHandler) *lineStreamer {
+	if handler == nil {
+		return nil
+	}
+
+	s := &lineStreamer{handler: handler}
+	s.stdout = &lineWriter{streamer: s, stream: StreamStdout}
+	s.stderr = &lineWriter{streamer: s, stream: StreamStderr}
+	return s
+}
+
+// emit dispatches a single line to the handler
+func (s *lineStreamer) emit(stream Stream, line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handler(LineEvent{
+		Stream: stream,
+		Line:   string(line),
+		Time:   time.Now(),
+	})
+}
+
+// Flush emits any trailing output that was not terminated by a newline.
+// It must only be called after the process has finished writing.
+func (s *lineStreamer) Flush() {
+	s.stdout.flush()
+	s.stderr.flush()
+}
+
+// lineWriter is an io.Writer that buffers partial lines for a single stream
+type lineWriter struct {
+	streamer *lineStreamer
+	stream   Stream
+	buf      []byte
+}
+
+// Write implements io.Writer, emitting every completed line
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		w.streamer.emit(w.stream, w.buf[:idx])
+		w.buf = w.buf[idx+1:]
+	}
+
+	return len(p), nil
+}
+
+// flush emits the remaining partial line, if any
+func (w *lineWriter) flush() {
+	if len(w.buf) == 0 {
+		return
+	}
+	w.streamer.emit(w.stream, w.buf)
+	w.buf = nil
+}
@@ -0,0 +1,76 @@
+package executor_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/input-output-hk/catalyst-forge-libs/executor"
+)
+
+func TestLineHandler(t *testing.T) {
+	var events []executor.LineEvent
+
+	cmd := executor.New("sh", "-c", "echo one; echo two >&2; printf three")
+	result, err := cmd.Execute(
+		context.Background(),
+		executor.WithLineHandler(func(ev executor.LineEvent) {
+			events = append(events, ev)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 line events, got %d: %+v", len(events), events)
+	}
+
+	got := map[string]executor.Stream{}
+	for _, ev := range events {
+		if ev.Time.IsZero() {
+			t.Errorf("expected timestamp on event %q", ev.Line)
+		}
+		got[ev.Line] = ev.Stream
+	}
+
+	if got["one"] != executor.StreamStdout {
+		t.Errorf("expected 'one' on stdout, got %v", got["one"])
+	}
+	if got["two"] != executor.StreamStderr {
+		t.Errorf("expected 'two' on stderr, got %v", got["two"])
+	}
+	if _, ok := got["three"]; !ok {
+		t.Errorf("expected unterminated trailing line to be flushed")
+	}
+
+	// Result is still populated after streaming
+	if !strings.Contains(result.Stdout, "one") || !strings.Contains(result.Stderr, "two") {
+		t.Errorf("expected buffered output in result, got stdout=%q stderr=%q", result.Stdout, result.Stderr)
+	}
+}
+
+func TestLineChannel(t *testing.T) {
+	ch := make(chan executor.LineEvent)
+	done := make(chan []string)
+
+	go func() {
+		var lines []string
+		for ev := range ch {
+			lines = append(lines, ev.Line)
+		}
+		done <- lines
+	}()
+
+	cmd := executor.New("sh", "-c", "printf 'a\\r\\nb\\n'")
+	_, err := cmd.Execute(context.Background(), executor.WithLineChannel(ch))
+	close(ch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := <-done
+	if strings.Join(lines, ",") != "a,b" {
+		t.Errorf("expected lines [a b], got %q", lines)
+	}
+}
@@ -0,0 +1,128 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains affected-path detection for monorepos.
+package git
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// AffectedOpts configures affected root detection.
+type AffectedOpts struct {
+	// MergeBase compares head against the merge base of base and head instead
+	// of base itself, like "git diff base...head". Use it to find what a
+	// branch changed since it forked from its target branch.
+	MergeBase bool
+
+	// Filters restrict the changes considered, e.g., NotFilter(ExtensionFilter(".md")).
+	// A change must pass all filters to affect a root.
+	Filters []ChangeFilter
+}
+
+// Affected returns the roots with changes between base and head.
+// Roots are slash-separated directories relative to the repository root;
+// "" or "." is the repository root itself and matches every change.
+//
+// A change affects every root containing its old or new path, so renames
+// across roots affect both and deletions affect the root they were deleted
+// from. Roots are returned cleaned ("services/api/" as "services/api", the
+// repository root as ".") in the order given, without duplicates.
+//
+// Returns ErrResolveFailed if a revision cannot be resolved or, with
+// opts.MergeBase, if the revisions have no common ancestor.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Affected(ctx context.Context, base, head string, roots []string, opts AffectedOpts) ([]string, error) {
+	if err := validateDiffInputs(base, head); err != nil {
+		return nil, err
+	}
+
+	from, err := r.commitOf(base)
+	if err != nil {
+		return nil, err
+	}
+	to, err := r.commitOf(head)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.MergeBase {
+		bases, err := from.MergeBase(to)
+		if err != nil {
+			return nil, WrapError(err, "failed to find merge base")
+		}
+		if len(bases) == 0 {
+			return nil, WrapErrorf(ErrResolveFailed, "no common ancestor of %q and %q", base, head)
+		}
+		from = bases[0]
+	}
+
+	changes, err := r.changesBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	changes = applyChangeFilters(changes, opts.Filters)
+
+	var affected []string
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		root = cleanRoot(root)
+		if seen[root] {
+			continue
+		}
+		seen[root] = true
+
+		inRoot := DirFilter(root)
+		for _, change := range changes {
+			if inRoot(change) {
+				affected = append(affected, root)
+				break
+			}
+		}
+	}
+
+	return affected, nil
+}
+
+// changesBetween returns the changes between two commits, detecting renames.
+func (r *Repo) changesBetween(ctx context.Context, from, to *object.Commit) (object.Changes, error) {
+	treeA, err := from.Tree()
+	if err != nil {
+		return nil, WrapError(err, "failed to get tree")
+	}
+	treeB, err := to.Tree()
+	if err != nil {
+		return nil, WrapError(err, "failed to get tree")
+	}
+
+	changes, err := object.DiffTreeWithOptions(ctx, treeA, treeB, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, WrapError(err, "failed to compute changes")
+	}
+
+	return changes, nil
+}
+
+// cleanRoot normalizes a root directory, returning "." for the repository root.
+func cleanRoot(root string) string {
+	root = strings.TrimPrefix(path.Clean("/"+root), "/")
+	if root == "" {
+		return "."
+	}
+	return root
+}
+
+// inDir checks if a slash-separated path is dir or inside it.
+// An empty dir or "." contains every path.
+func inDir(name, dir string) bool {
+	if name == "" {
+		return false
+	}
+	if dir == "" || dir == "." {
+		return true
+	}
+	return name == dir || strings.HasPrefix(name, dir+"/")
+}
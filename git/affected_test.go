@@ -0,0 +1,140 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupMonorepo creates a repository with several project roots, returning
+// the SHA of the commit that created them
+func setupMonorepo(t *testing.T) (*testRepo, string) {
+	t.Helper()
+
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.fs.MkdirAll("services/api", 0o755))
+	require.NoError(t, tr.fs.MkdirAll("services/api2", 0o755))
+	require.NoError(t, tr.fs.MkdirAll("libs/shared", 0o755))
+	tr.commitFile(t, "services/api/main.go", "package main\n")
+	tr.commitFile(t, "services/api2/main.go", "package main\n")
+	base := tr.commitFile(t, "libs/shared/util.go", "package shared\n")
+
+	return tr, base
+}
+
+// TestAffected tests mapping changes between revisions to project roots
+func TestAffected(t *testing.T) {
+	roots := []string{"services/api", "services/api2", "libs/shared", "docs"}
+
+	t.Run("modified file", func(t *testing.T) {
+		tr, base := setupMonorepo(t)
+		tr.commitFile(t, "services/api2/main.go", "package main\n\nfunc main() {}\n")
+
+		affected, err := tr.repo.Affected(tr.ctx, base, "HEAD", roots, AffectedOpts{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"services/api2"}, affected, "services/api must not match services/api2")
+	})
+
+	t.Run("rename across roots", func(t *testing.T) {
+		tr, base := setupMonorepo(t)
+		require.NoError(t, tr.fs.Rename("libs/shared/util.go", "services/api/util.go"))
+		require.NoError(t, tr.repo.Add(tr.ctx, "services/api/util.go"))
+		require.NoError(t, tr.repo.Remove(tr.ctx, "libs/shared/util.go"))
+		_, err := tr.repo.Commit(tr.ctx, "move util", Signature{Name: "Test", Email: "test@example.com", When: time.Now()}, CommitOpts{})
+		require.NoError(t, err)
+
+		affected, err := tr.repo.Affected(tr.ctx, base, "HEAD", roots, AffectedOpts{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"services/api", "libs/shared"}, affected)
+	})
+
+	t.Run("deletion", func(t *testing.T) {
+		tr, base := setupMonorepo(t)
+		require.NoError(t, tr.repo.Remove(tr.ctx, "libs/shared/util.go"))
+		_, err := tr.repo.Commit(tr.ctx, "drop shared", Signature{Name: "Test", Email: "test@example.com", When: time.Now()}, CommitOpts{})
+		require.NoError(t, err)
+
+		affected, err := tr.repo.Affected(tr.ctx, base, "HEAD", roots, AffectedOpts{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"libs/shared"}, affected)
+	})
+
+	t.Run("root normalization and repository root", func(t *testing.T) {
+		tr, base := setupMonorepo(t)
+		tr.commitFile(t, "services/api/main.go", "package api\n")
+
+		affected, err := tr.repo.Affected(tr.ctx, base, "HEAD",
+			[]string{"./services/api/", "services/api", "", "libs"}, AffectedOpts{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"services/api", "."}, affected)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		tr, base := setupMonorepo(t)
+		tr.commitFile(t, "services/api/README.md", "# API\n")
+		tr.commitFile(t, "libs/shared/util.go", "package shared\n\n// Util\n")
+
+		affected, err := tr.repo.Affected(tr.ctx, base, "HEAD", roots, AffectedOpts{
+			Filters: []ChangeFilter{NotFilter(ExtensionFilter(".md"))},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"libs/shared"}, affected)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		tr, base := setupMonorepo(t)
+
+		affected, err := tr.repo.Affected(tr.ctx, base, base, roots, AffectedOpts{})
+		require.NoError(t, err)
+		assert.Empty(t, affected)
+	})
+
+	t.Run("invalid revisions", func(t *testing.T) {
+		tr, _ := setupMonorepo(t)
+
+		_, err := tr.repo.Affected(tr.ctx, "", "HEAD", roots, AffectedOpts{})
+		require.ErrorIs(t, err, ErrInvalidRef)
+		_, err = tr.repo.Affected(tr.ctx, "nonexistent", "HEAD", roots, AffectedOpts{})
+		require.ErrorIs(t, err, ErrResolveFailed)
+	})
+}
+
+// TestAffectedMergeBase tests comparing a branch against its fork point
+func TestAffectedMergeBase(t *testing.T) {
+	tr, _ := setupMonorepo(t)
+	tr.createTestBranch(t, "feature")
+
+	// Advance master after the fork
+	tr.commitFile(t, "libs/shared/util.go", "package shared\n\n// On master\n")
+
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "feature", false, false))
+	tr.commitFile(t, "services/api/main.go", "package api\n")
+
+	roots := []string{"services/api", "libs/shared"}
+
+	affected, err := tr.repo.Affected(tr.ctx, "master", "feature", roots, AffectedOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, roots, affected, "a two-dot diff includes master's own changes")
+
+	affected, err = tr.repo.Affected(tr.ctx, "master", "feature", roots, AffectedOpts{MergeBase: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"services/api"}, affected)
+}
+
+// TestDirFilter tests directory-boundary aware path filtering
+func TestDirFilter(t *testing.T) {
+	tr, base := setupMonorepo(t)
+	tr.commitFile(t, "services/api2/main.go", "package api2\n")
+	tr.commitFile(t, "services/api/main.go", "package api\n")
+
+	diff, err := tr.repo.Diff(tr.ctx, base, "HEAD", DirFilter("services/api/"))
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 1)
+	assert.Equal(t, "services/api/main.go", diff.Files[0].Path())
+
+	diff, err = tr.repo.Diff(tr.ctx, base, "HEAD", DirFilter("services"))
+	require.NoError(t, err)
+	assert.Len(t, diff.Files, 2)
+}
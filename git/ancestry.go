@@ -0,0 +1,349 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains ancestry queries (merge base, reachability, counting).
+package git
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// MergeBase returns the SHA of the best common ancestor of two revisions,
+// like `git merge-base`. When there are several equally good candidates
+// (criss-cross merges), the first one found is returned.
+//
+// Returns ErrResolveFailed if a revision cannot be resolved or the revisions
+// have no common ancestor.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) MergeBase(ctx context.Context, a, b string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", WrapError(err, "context cancelled")
+	}
+
+	ca, err := r.commitOf(a)
+	if err != nil {
+		return "", err
+	}
+	cb, err := r.commitOf(b)
+	if err != nil {
+		return "", err
+	}
+
+	bases, err := ca.MergeBase(cb)
+	if err != nil {
+		return "", WrapError(err, "failed to find merge base")
+	}
+	if len(bases) == 0 {
+		return "", WrapErrorf(ErrResolveFailed, "%q and %q have no common ancestor", a, b)
+	}
+
+	return bases[0].Hash.String(), nil
+}
+
+// IsAncestor reports whether ancestor is reachable from descendant, like
+// `git merge-base --is-ancestor`. A commit is its own ancestor.
+//
+// Returns ErrResolveFailed if a revision cannot be resolved.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) IsAncestor(ctx context.Context, ancestor, descendant string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, WrapError(err, "context cancelled")
+	}
+
+	ca, err := r.commitOf(ancestor)
+	if err != nil {
+		return false, err
+	}
+	cd, err := r.commitOf(descendant)
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := ca.IsAncestor(cd)
+	if err != nil {
+		return false, WrapError(err, "failed to walk commit history")
+	}
+
+	return ok, nil
+}
+
+// CountCommits returns the number of commits reachable from to but not from
+// from, like `git rev-list --count from..to`. An empty from counts every
+// commit reachable from to.
+//
+// Returns ErrResolveFailed if a revision cannot be resolved.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) CountCommits(ctx context.Context, from, to string) (int, error) {
+	toCommit, err := r.commitOf(to)
+	if err != nil {
+		return 0, err
+	}
+	var excluded []plumbing.Hash
+	if from != "" {
+		fromCommit, err := r.commitOf(from)
+		if err != nil {
+			return 0, err
+		}
+		excluded = append(excluded, fromCommit.Hash)
+	}
+
+	walk, err := r.newRevWalk(ctx, []plumbing.Hash{toCommit.Hash}, excluded)
+	if err != nil {
+		return 0, err
+	}
+	defer walk.Close()
+
+	count := 0
+	err = walk.ForEach(func(*object.Commit) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, WrapError(err, "failed to walk commit history")
+	}
+
+	return count, nil
+}
+
+// isAncestor reports whether ancestor is reachable from descendant. It walks
+// the commits reachable from ancestor but not from descendant, which is empty
+// exactly when ancestor is reachable, so only the history newer than ancestor
+// is read.
+func (r *Repo) isAncestor(ctx context.Context, ancestor, descendant plumbing.Hash) (bool, error) {
+	walk, err := r.newRevWalk(ctx, []plumbing.Hash{ancestor}, []plumbing.Hash{descendant})
+	if err != nil {
+		return false, err
+	}
+	defer walk.Close()
+
+	_, err = walk.Next()
+	if errors.Is(err, io.EOF) {
+		return true, nil
+	}
+	if err != nil {
+		return false, WrapError(err, "failed to walk commit history")
+	}
+
+	return false, nil
+}
+
+// revWalk iterates over the commits reachable from a set of tips but not from
+// a set of excluded commits, newest first by committer time, like
+// `git rev-list tips ^excluded`. Exclusion spreads to parents as the walk goes
+// back in time, and the walk stops once every queued commit is excluded and no
+// newer than the commits found, so only the history that differs is read.
+// Like git, the walk completes before the first commit is returned, since a
+// commit found early can still turn out to be reachable from an excluded one
+// committed at the same time.
+type revWalk struct {
+	ctx    context.Context
+	r      *Repo
+	queue  commitQueue
+	states map[plumbing.Hash]*revState
+
+	// interesting is the number of queued commits that are not excluded
+	interesting int
+
+	// found are the commits walked that were not excluded at the time
+	found   []*object.Commit
+	limited bool
+	next    int
+}
+
+// revState tracks a commit seen by a revWalk.
+type revState struct {
+	queued        bool
+	uninteresting bool
+
+	// parents are set once the commit is walked
+	parents []plumbing.Hash
+}
+
+// newRevWalk returns a revWalk over the commits reachable from tips but not
+// from excluded.
+func (r *Repo) newRevWalk(ctx context.Context, tips, excluded []plumbing.Hash) (*revWalk, error) {
+	w := &revWalk{ctx: ctx, r: r, states: make(map[plumbing.Hash]*revState)}
+	for _, hash := range excluded {
+		if err := w.add(hash, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, hash := range tips {
+		if err := w.add(hash, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// add queues a commit not seen yet, or excludes a seen commit.
+// Missing commits (the boundary of a shallow clone) are skipped.
+func (w *revWalk) add(hash plumbing.Hash, uninteresting bool) error {
+	if _, ok := w.states[hash]; ok {
+		if uninteresting {
+			w.exclude(hash)
+		}
+		return nil
+	}
+
+	commit, err := w.r.repo.CommitObject(hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return WrapErrorf(err, "failed to get commit %s", hash)
+	}
+
+	w.states[hash] = &revState{queued: true, uninteresting: uninteresting}
+	if !uninteresting {
+		w.interesting++
+	}
+	heap.Push(&w.queue, commit)
+
+	return nil
+}
+
+// exclude marks a seen commit as excluded, along with the ancestors already
+// walked from it.
+func (w *revWalk) exclude(hash plumbing.Hash) {
+	pending := []plumbing.Hash{hash}
+	for len(pending) > 0 {
+		st, ok := w.states[pending[len(pending)-1]]
+		pending = pending[:len(pending)-1]
+		if !ok || st.uninteresting {
+			continue
+		}
+
+		st.uninteresting = true
+		if st.queued {
+			w.interesting--
+		} else {
+			pending = append(pending, st.parents...)
+		}
+	}
+}
+
+// limit walks the history until only excluded commits older than the
+// commits found are left.
+func (w *revWalk) limit() error {
+	var oldest time.Time
+	for w.queue.Len() > 0 {
+		if w.interesting == 0 && (len(w.found) == 0 || w.queue.commits[0].Committer.When.Before(oldest)) {
+			break
+		}
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+
+		commit, _ := heap.Pop(&w.queue).(*object.Commit)
+		st := w.states[commit.Hash]
+		st.queued = false
+		st.parents = commit.ParentHashes
+		if !st.uninteresting {
+			w.interesting--
+			w.found = append(w.found, commit)
+			oldest = commit.Committer.When
+		}
+
+		for _, parent := range commit.ParentHashes {
+			if err := w.add(parent, st.uninteresting); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Next returns the next commit in the walk, or io.EOF when done
+func (w *revWalk) Next() (*object.Commit, error) {
+	if !w.limited {
+		if err := w.limit(); err != nil {
+			return nil, err
+		}
+		w.limited = true
+	}
+
+	for w.next < len(w.found) {
+		commit := w.found[w.next]
+		w.next++
+		if !w.states[commit.Hash].uninteresting {
+			return commit, nil
+		}
+	}
+
+	return nil, io.EOF
+}
+
+// ForEach executes the function for each commit in the walk
+func (w *revWalk) ForEach(fn func(*object.Commit) error) error {
+	for {
+		commit, err := w.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(commit); err != nil {
+			if errors.Is(err, storer.ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// Close releases the walked commits
+func (w *revWalk) Close() {
+	w.queue = commitQueue{}
+	w.found = nil
+}
+
+// commitQueue is a heap of commits, the most recently committed first and
+// the first queued first among commits with the same time, so children come
+// before parents committed in the same second
+type commitQueue struct {
+	commits []queuedCommit
+	pushed  int
+}
+
+// queuedCommit is a commit in a commitQueue with its queueing order
+type queuedCommit struct {
+	*object.Commit
+	order int
+}
+
+func (q commitQueue) Len() int { return len(q.commits) }
+
+func (q commitQueue) Less(i, j int) bool {
+	a, b := q.commits[i], q.commits[j]
+	if !a.Committer.When.Equal(b.Committer.When) {
+		return a.Committer.When.After(b.Committer.When)
+	}
+	return a.order < b.order
+}
+
+func (q commitQueue) Swap(i, j int) { q.commits[i], q.commits[j] = q.commits[j], q.commits[i] }
+
+func (q *commitQueue) Push(x any) {
+	commit, _ := x.(*object.Commit)
+	q.commits = append(q.commits, queuedCommit{Commit: commit, order: q.pushed})
+	q.pushed++
+}
+
+func (q *commitQueue) Pop() any {
+	last := q.commits[len(q.commits)-1]
+	q.commits = q.commits[:len(q.commits)-1]
+	return last.Commit
+}
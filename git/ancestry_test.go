@@ -0,0 +1,209 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ancestryCommits names the commits created by setupAncestryRepo
+type ancestryCommits struct {
+	initial, base, c, d, e string
+}
+
+// setupAncestryRepo creates a repository where feature branches from master
+// at base and adds c and d, while master adds e, in that commit order
+func setupAncestryRepo(t *testing.T) (*testRepo, ancestryCommits) {
+	t.Helper()
+
+	tr := setupTestRepoWithCommit(t)
+	head, err := tr.repo.Resolve(tr.ctx, "HEAD")
+	require.NoError(t, err)
+
+	when := time.Now().Add(-time.Hour)
+	commit := func(path string) string {
+		when = when.Add(time.Minute)
+		require.NoError(t, tr.fs.WriteFile(path, []byte(path), 0o644))
+		require.NoError(t, tr.repo.Add(tr.ctx, path))
+		sha, err := tr.repo.Commit(tr.ctx, "add "+path, Signature{Name: "Test", Email: "test@example.com", When: when}, CommitOpts{})
+		require.NoError(t, err)
+		return sha
+	}
+
+	commits := ancestryCommits{initial: head.Hash}
+	commits.base = commit("base.txt")
+	tr.createTestBranch(t, "feature")
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "feature", false, false))
+	commits.c = commit("c.txt")
+	commits.d = commit("d.txt")
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "master", false, false))
+	commits.e = commit("e.txt")
+
+	return tr, commits
+}
+
+// TestMergeBase tests finding the common ancestor of two revisions
+func TestMergeBase(t *testing.T) {
+	tr, commits := setupAncestryRepo(t)
+
+	base, err := tr.repo.MergeBase(tr.ctx, "master", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, commits.base, base)
+
+	base, err = tr.repo.MergeBase(tr.ctx, commits.c, "feature")
+	require.NoError(t, err)
+	assert.Equal(t, commits.c, base)
+
+	_, err = tr.repo.MergeBase(tr.ctx, "master", "nonexistent")
+	require.ErrorIs(t, err, ErrResolveFailed)
+}
+
+// TestIsAncestor tests reachability checks between revisions
+func TestIsAncestor(t *testing.T) {
+	tr, commits := setupAncestryRepo(t)
+
+	tests := []struct {
+		ancestor   string
+		descendant string
+		want       bool
+	}{
+		{ancestor: commits.base, descendant: "feature", want: true},
+		{ancestor: commits.initial, descendant: "master", want: true},
+		{ancestor: "master", descendant: "master", want: true},
+		{ancestor: "feature", descendant: "master", want: false},
+		{ancestor: "master", descendant: commits.base, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ancestor+"_"+tt.descendant, func(t *testing.T) {
+			got, err := tr.repo.IsAncestor(tr.ctx, tt.ancestor, tt.descendant)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := tr.repo.IsAncestor(tr.ctx, "", "master")
+	require.ErrorIs(t, err, ErrInvalidRef)
+}
+
+// TestCountCommits tests counting the commits between revisions
+func TestCountCommits(t *testing.T) {
+	tr, commits := setupAncestryRepo(t)
+
+	tests := []struct {
+		from string
+		to   string
+		want int
+	}{
+		{from: "master", to: "feature", want: 2},
+		{from: "feature", to: "master", want: 1},
+		{from: "", to: "feature", want: 4},
+		{from: "feature", to: commits.base, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+".."+tt.to, func(t *testing.T) {
+			got, err := tr.repo.CountCommits(tr.ctx, tt.from, tt.to)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := tr.repo.CountCommits(tr.ctx, "nonexistent", "master")
+	require.ErrorIs(t, err, ErrResolveFailed)
+}
+
+// TestRevWalkStopsEarly tests that range walks do not read the shared history
+func TestRevWalkStopsEarly(t *testing.T) {
+	tr, commits := setupAncestryRepo(t)
+
+	walk, err := tr.repo.newRevWalk(tr.ctx,
+		[]plumbing.Hash{plumbing.NewHash(commits.d)}, []plumbing.Hash{plumbing.NewHash(commits.e)})
+	require.NoError(t, err)
+	defer walk.Close()
+
+	var got []string
+	require.NoError(t, walk.ForEach(func(c *object.Commit) error {
+		got = append(got, c.Hash.String())
+		return nil
+	}))
+	assert.Equal(t, []string{commits.d, commits.c}, got)
+	assert.Contains(t, walk.states, plumbing.NewHash(commits.base))
+	assert.NotContains(t, walk.states, plumbing.NewHash(commits.initial), "history below the merge base is not read")
+}
+
+// TestRevWalkSameTime tests excluding commits reached late through commits
+// committed in the same second
+func TestRevWalkSameTime(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	_, head, err := tr.repo.headCommit()
+	require.NoError(t, err)
+
+	sig := object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+	commit := func(message string, parents ...plumbing.Hash) plumbing.Hash {
+		c := &object.Commit{Author: sig, Committer: sig, Message: message, TreeHash: head.TreeHash, ParentHashes: parents}
+		obj := tr.repo.repo.Storer.NewEncodedObject()
+		require.NoError(t, c.Encode(obj))
+		hash, err := tr.repo.repo.Storer.SetEncodedObject(obj)
+		require.NoError(t, err)
+		return hash
+	}
+
+	// x reaches p through a longer chain than the merge y
+	p := commit("p", head.Hash)
+	q := commit("q", head.Hash)
+	y := commit("y", p, q)
+	x := commit("x", commit("x1", commit("x2", p)))
+
+	walk, err := tr.repo.newRevWalk(tr.ctx, []plumbing.Hash{y}, []plumbing.Hash{x})
+	require.NoError(t, err)
+	defer walk.Close()
+
+	var got []plumbing.Hash
+	require.NoError(t, walk.ForEach(func(c *object.Commit) error {
+		got = append(got, c.Hash)
+		return nil
+	}))
+	assert.Equal(t, []plumbing.Hash{y, q}, got)
+}
+
+// TestLogRange tests walking revision ranges with Log
+func TestLogRange(t *testing.T) {
+	tr, commits := setupAncestryRepo(t)
+
+	tests := []struct {
+		name   string
+		filter LogFilter
+		want   []string
+	}{
+		{name: "single revision", filter: LogFilter{Range: commits.base}, want: []string{commits.base, commits.initial}},
+		{name: "two dots", filter: LogFilter{Range: "master..feature"}, want: []string{commits.d, commits.c}},
+		{name: "omitted side is HEAD", filter: LogFilter{Range: "feature.."}, want: []string{commits.e}},
+		{name: "three dots", filter: LogFilter{Range: "master...feature"}, want: []string{commits.e, commits.d, commits.c}},
+		{name: "with path", filter: LogFilter{Range: "master...feature", Path: []string{"c.txt"}}, want: []string{commits.c}},
+		{name: "with max count", filter: LogFilter{Range: "feature...master", MaxCount: 2}, want: []string{commits.e, commits.d}},
+		{name: "empty", filter: LogFilter{Range: "feature..feature"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iter, err := tr.repo.Log(tr.ctx, tt.filter)
+			require.NoError(t, err)
+			defer iter.Close()
+
+			var got []string
+			require.NoError(t, iter.ForEach(func(c *object.Commit) error {
+				got = append(got, c.Hash.String())
+				return nil
+			}))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := tr.repo.Log(tr.ctx, LogFilter{Range: "master..nonexistent"})
+	require.ErrorIs(t, err, ErrResolveFailed)
+}
@@ -33,7 +33,8 @@ func (r *Repo) CurrentBranch(ctx context.Context) (string, error) {
 
 // CreateBranch creates a new branch from the specified revision.
 // It supports creating branches from any valid revision (commit hash, branch name, tag, etc.).
-// If trackRemote is true, it sets up remote tracking configuration.
+// If trackRemote is true, startRev must be a remote-tracking branch (e.g., "origin/main"),
+// which is configured as the upstream of the new branch.
 // If force is true, it overwrites any existing branch with the same name.
 //
 // Context timeout/cancellation is honored during the operation.
@@ -57,6 +58,18 @@ func (r *Repo) CreateBranch(ctx context.Context, name, startRev string, trackRem
 		return WrapError(ErrResolveFailed, "failed to resolve start revision")
 	}
 
+	// Determine the upstream before creating anything
+	var upstream *Upstream
+	if trackRemote {
+		upstream, err = r.remoteBranchOf(startRev)
+		if err != nil {
+			return err
+		}
+		if upstream == nil {
+			return WrapErrorf(ErrInvalidRef, "cannot track %q: not a remote-tracking branch", startRev)
+		}
+	}
+
 	// Check if branch already exists
 	branchRefName := plumbing.NewBranchReferenceName(name)
 	_, err = r.repo.Reference(branchRefName, true)
@@ -71,9 +84,9 @@ func (r *Repo) CreateBranch(ctx context.Context, name, startRev string, trackRem
 		return WrapError(err, "failed to create branch reference")
 	}
 
-	// TODO: Implement remote tracking configuration when trackRemote is true
-	// For now, remote tracking setup is not implemented but the parameter is accepted for API compatibility
-	_ = trackRemote
+	if upstream != nil {
+		return r.SetUpstream(ctx, name, upstream.Remote, upstream.Branch)
+	}
 
 	return nil
 }
@@ -151,7 +164,7 @@ func (r *Repo) CheckoutBranch(ctx context.Context, name string, createIfMissing,
 	return nil
 }
 
-// DeleteBranch deletes the specified local branch.
+// DeleteBranch deletes the specified local branch and its upstream configuration.
 // It prevents deletion of the currently checked out branch.
 //
 // Context timeout/cancellation is honored during the operation.
@@ -181,10 +194,11 @@ func (r *Repo) DeleteBranch(ctx context.Context, name string) error {
 		return WrapError(err, "failed to delete branch")
 	}
 
-	return nil
+	return r.removeBranchConfig(name)
 }
 
 // CheckoutRemoteBranch creates a local branch from a remote branch and optionally sets up tracking.
+// If track is true, the remote branch is configured as the upstream of the local branch.
 // If localName is empty, it uses the same name as the remote branch.
 //
 // Context timeout/cancellation is honored during the operation.
@@ -218,9 +232,11 @@ func (r *Repo) CheckoutRemoteBranch(ctx context.Context, remote, remoteBranch, l
 		return WrapError(err, "failed to create local branch")
 	}
 
-	// TODO: Implement remote tracking configuration when track is true
-	// For now, remote tracking setup is not implemented but the parameter is accepted for API compatibility
-	_ = track
+	if track {
+		if err := r.SetUpstream(ctx, localName, remote, remoteBranch); err != nil {
+			return err
+		}
+	}
 
 	// Checkout the newly created local branch
 	checkoutOpts := &git.CheckoutOptions{
@@ -0,0 +1,427 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains conventional commit parsing and changelog generation.
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var (
+	// conventionalHeader matches "type(scope)!: description".
+	conventionalHeader = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*)(?:\(([^()]*)\))?(!)?:\s*(.*\S)\s*$`)
+
+	// footerToken matches the first line of a footer ("Key: value" or "Key #value").
+	footerToken = regexp.MustCompile(`^(BREAKING CHANGE|[A-Za-z][A-Za-z0-9-]*)(?:: | #)(.*)$`)
+)
+
+// Trailer is a "Key: value" footer of a commit message (e.g., "Signed-off-by").
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ConventionalCommit is a commit message parsed according to the
+// Conventional Commits specification (https://www.conventionalcommits.org).
+type ConventionalCommit struct {
+	// Type is the lowercase commit type (e.g., "feat", "fix").
+	Type string `json:"type"`
+
+	// Scope is the optional scope in parentheses (e.g., "auth").
+	Scope string `json:"scope,omitempty"`
+
+	// Description is the header text after the colon.
+	Description string `json:"description"`
+
+	// Body is the free-form text between the header and the footers.
+	Body string `json:"body,omitempty"`
+
+	// Breaking is true if the header has "!" or a BREAKING CHANGE line appears
+	// in the body or footers.
+	Breaking bool `json:"breaking"`
+
+	// BreakingNote is the BREAKING CHANGE text, or the description when
+	// the change is only marked with "!".
+	BreakingNote string `json:"breakingNote,omitempty"`
+
+	// Trailers are the footers in order of appearance, including BREAKING CHANGE.
+	Trailers []Trailer `json:"trailers,omitempty"`
+}
+
+// ParseConventionalCommit parses a commit message. It reports false if the
+// header does not follow the "type(scope)!: description" format.
+func ParseConventionalCommit(message string) (ConventionalCommit, bool) {
+	header, rest, _ := strings.Cut(strings.TrimSpace(message), "\n")
+
+	m := conventionalHeader.FindStringSubmatch(strings.TrimSpace(header))
+	if m == nil {
+		return ConventionalCommit{}, false
+	}
+
+	cc := ConventionalCommit{
+		Type:        strings.ToLower(m[1]),
+		Scope:       strings.TrimSpace(m[2]),
+		Description: m[4],
+		Breaking:    m[3] == "!",
+	}
+
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(rest, "\r\n", "\n")), "\n\n")
+	if trailers, ok := parseTrailers(paragraphs[len(paragraphs)-1]); ok {
+		cc.Trailers = trailers
+		paragraphs = paragraphs[:len(paragraphs)-1]
+	}
+	cc.Body = strings.TrimSpace(strings.Join(paragraphs, "\n\n"))
+
+	for _, trailer := range cc.Trailers {
+		if isBreakingKey(trailer.Key) {
+			cc.Breaking = true
+			cc.BreakingNote = trailer.Value
+		}
+	}
+
+	// A BREAKING CHANGE line anywhere in the body also marks the commit
+	for _, line := range strings.Split(cc.Body, "\n") {
+		if key, note, ok := strings.Cut(line, ":"); ok && isBreakingKey(key) {
+			cc.Breaking = true
+			if cc.BreakingNote == "" {
+				cc.BreakingNote = strings.TrimSpace(note)
+			}
+		}
+	}
+	if cc.Breaking && cc.BreakingNote == "" {
+		cc.BreakingNote = cc.Description
+	}
+
+	return cc, true
+}
+
+// parseTrailers splits a footer paragraph into trailers. It reports false if
+// the paragraph is not a footer block: every line must start a footer, except
+// continuation lines that are indented or extend a BREAKING CHANGE note.
+func parseTrailers(paragraph string) ([]Trailer, bool) {
+	var trailers []Trailer
+	for _, line := range strings.Split(paragraph, "\n") {
+		if m := footerToken.FindStringSubmatch(line); m != nil {
+			trailers = append(trailers, Trailer{Key: m[1], Value: strings.TrimSpace(m[2])})
+			continue
+		}
+		if len(trailers) == 0 {
+			return nil, false
+		}
+		last := &trailers[len(trailers)-1]
+		if !isBreakingKey(last.Key) && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			return nil, false
+		}
+		last.Value += "\n" + strings.TrimSpace(line)
+	}
+
+	return trailers, true
+}
+
+// isBreakingKey reports whether a footer key announces a breaking change.
+func isBreakingKey(key string) bool {
+	return key == "BREAKING CHANGE" || key == "BREAKING-CHANGE"
+}
+
+// ChangelogOpts configures changelog generation.
+type ChangelogOpts struct {
+	// From is the exclusive start of the range (e.g., the previous release tag).
+	// If empty, the changelog covers the entire history of To.
+	From string
+
+	// To is the inclusive end of the range. Defaults to HEAD.
+	To string
+
+	// Path limits the changelog to commits that modified these paths,
+	// matched like LogFilter.Path.
+	Path []string
+
+	// Types limits the changelog to these commit types (e.g., "feat", "fix").
+	// If empty, all types are included.
+	Types []string
+
+	// IncludeOther includes commits without a conventional header under
+	// "Other Changes". They are omitted by default.
+	IncludeOther bool
+
+	// Title is rendered as the Markdown heading (e.g., the release version).
+	Title string
+}
+
+// ChangelogEntry is a single commit in a changelog.
+type ChangelogEntry struct {
+	ConventionalCommit
+
+	// Hash is the full commit hash.
+	Hash string `json:"hash"`
+
+	// Author and Email identify the commit author.
+	Author string `json:"author"`
+	Email  string `json:"email"`
+
+	// When is the author timestamp.
+	When time.Time `json:"when"`
+}
+
+// ChangelogGroup holds the entries of one type and scope.
+type ChangelogGroup struct {
+	// Type is the commit type, or empty for non-conventional commits.
+	Type string `json:"type"`
+
+	// Scope is the commit scope, or empty for unscoped commits.
+	Scope string `json:"scope,omitempty"`
+
+	// Title is the human-readable section name of the type (e.g., "Features").
+	Title string `json:"title"`
+
+	// Entries are the commits in history order, newest first.
+	Entries []ChangelogEntry `json:"entries"`
+}
+
+// Changelog is a list of changes grouped by commit type and scope.
+type Changelog struct {
+	// Title is the changelog heading from ChangelogOpts.Title.
+	Title string `json:"title,omitempty"`
+
+	// From and To are the revisions the changelog covers.
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+
+	// Groups are ordered by type (features, fixes, ...) and then scope.
+	Groups []ChangelogGroup `json:"groups"`
+
+	// Breaking lists the entries with breaking changes, newest first.
+	// They also appear in their groups.
+	Breaking []ChangelogEntry `json:"breaking,omitempty"`
+}
+
+// changelogTypes orders the well-known commit types and names their sections.
+var changelogTypes = []struct {
+	typ   string
+	title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"revert", "Reverts"},
+	{"docs", "Documentation"},
+	{"style", "Styles"},
+	{"refactor", "Code Refactoring"},
+	{"test", "Tests"},
+	{"build", "Build System"},
+	{"ci", "Continuous Integration"},
+	{"chore", "Chores"},
+}
+
+// otherChangesTitle is the section name for non-conventional commits.
+const otherChangesTitle = "Other Changes"
+
+// Changelog builds a changelog from the commits reachable from opts.To but not
+// from opts.From. Merge commits are skipped.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Changelog(ctx context.Context, opts ChangelogOpts) (*Changelog, error) {
+	if opts.To == "" {
+		opts.To = "HEAD"
+	}
+
+	// The walk stops at the history shared with From
+	filter := LogFilter{Range: opts.To, Path: opts.Path}
+	if opts.From != "" {
+		filter.Range = opts.From + ".." + opts.To
+	}
+
+	iter, err := r.log(ctx, plumbing.ZeroHash, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var entries []ChangelogEntry
+	err = iter.ForEach(func(c *object.Commit) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if c.NumParents() > 1 {
+			return nil
+		}
+		if entry, ok := changelogEntry(c, opts); ok {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildChangelog(opts, entries), nil
+}
+
+// changelogEntry converts a commit, reporting false if opts exclude it.
+func changelogEntry(c *object.Commit, opts ChangelogOpts) (ChangelogEntry, bool) {
+	cc, ok := ParseConventionalCommit(c.Message)
+	if !ok {
+		if !opts.IncludeOther {
+			return ChangelogEntry{}, false
+		}
+		header, body, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		cc = ConventionalCommit{Description: strings.TrimSpace(header), Body: strings.TrimSpace(body)}
+	}
+
+	if ok && len(opts.Types) > 0 && !containsFold(opts.Types, cc.Type) {
+		return ChangelogEntry{}, false
+	}
+
+	return ChangelogEntry{
+		ConventionalCommit: cc,
+		Hash:               c.Hash.String(),
+		Author:             c.Author.Name,
+		Email:              c.Author.Email,
+		When:               c.Author.When,
+	}, true
+}
+
+// buildChangelog groups entries by type and scope.
+func buildChangelog(opts ChangelogOpts, entries []ChangelogEntry) *Changelog {
+	cl := &Changelog{Title: opts.Title, From: opts.From, To: opts.To, Groups: []ChangelogGroup{}}
+
+	index := map[[2]string]int{}
+	for _, entry := range entries {
+		key := [2]string{entry.Type, entry.Scope}
+		i, ok := index[key]
+		if !ok {
+			i = len(cl.Groups)
+			index[key] = i
+			cl.Groups = append(cl.Groups, ChangelogGroup{
+				Type:  entry.Type,
+				Scope: entry.Scope,
+				Title: changelogTitle(entry.Type),
+			})
+		}
+		cl.Groups[i].Entries = append(cl.Groups[i].Entries, entry)
+
+		if entry.Breaking {
+			cl.Breaking = append(cl.Breaking, entry)
+		}
+	}
+
+	sort.SliceStable(cl.Groups, func(i, j int) bool {
+		a, b := cl.Groups[i], cl.Groups[j]
+		if ra, rb := changelogRank(a.Type), changelogRank(b.Type); ra != rb {
+			return ra < rb
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Scope < b.Scope
+	})
+
+	return cl
+}
+
+// Markdown renders the changelog as Markdown, with a section per type,
+// breaking changes first and scopes in bold.
+func (c *Changelog) Markdown() string {
+	var b strings.Builder
+
+	if c.Title != "" {
+		fmt.Fprintf(&b, "## %s\n\n", c.Title)
+	}
+
+	if len(c.Breaking) > 0 {
+		b.WriteString("### BREAKING CHANGES\n\n")
+		for _, entry := range c.Breaking {
+			writeMarkdownEntry(&b, entry, entry.BreakingNote)
+		}
+		b.WriteString("\n")
+	}
+
+	for i, group := range c.Groups {
+		if i == 0 || c.Groups[i-1].Type != group.Type {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "### %s\n\n", group.Title)
+		}
+		for _, entry := range group.Entries {
+			writeMarkdownEntry(&b, entry, entry.Description)
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// JSON renders the changelog as indented JSON.
+func (c *Changelog) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, WrapError(err, "failed to encode changelog")
+	}
+
+	return data, nil
+}
+
+// writeMarkdownEntry writes a list item for an entry with the given text.
+func writeMarkdownEntry(b *strings.Builder, entry ChangelogEntry, text string) {
+	b.WriteString("- ")
+	if entry.Scope != "" {
+		fmt.Fprintf(b, "**%s:** ", entry.Scope)
+	}
+
+	lines := strings.Split(text, "\n")
+	b.WriteString(lines[0])
+	fmt.Fprintf(b, " (%s)\n", entry.Hash[:7])
+	for _, line := range lines[1:] {
+		fmt.Fprintf(b, "  %s\n", line)
+	}
+}
+
+// changelogTitle returns the section name of a commit type.
+func changelogTitle(typ string) string {
+	if typ == "" {
+		return otherChangesTitle
+	}
+
+	for _, t := range changelogTypes {
+		if t.typ == typ {
+			return t.title
+		}
+	}
+
+	return typ
+}
+
+// changelogRank orders well-known types first, then other types, then
+// non-conventional commits.
+func changelogRank(typ string) int {
+	if typ == "" {
+		return len(changelogTypes) + 1
+	}
+
+	for i, t := range changelogTypes {
+		if t.typ == typ {
+			return i
+		}
+	}
+
+	return len(changelogTypes)
+}
+
+// containsFold reports whether values contains s, ignoring case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,214 @@
+package git
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitChange writes a file and commits it with the given message, returning the commit SHA
+func (tr *testRepo) commitChange(t *testing.T, path, message string) string {
+	t.Helper()
+
+	require.NoError(t, tr.fs.WriteFile(path, []byte(message), 0o644))
+	require.NoError(t, tr.repo.Add(tr.ctx, path))
+
+	sha, err := tr.repo.Commit(tr.ctx, message, Signature{
+		Name:  "Test",
+		Email: "test@example.com",
+		When:  time.Now(),
+	}, CommitOpts{})
+	require.NoError(t, err)
+
+	return sha
+}
+
+// TestParseConventionalCommit tests parsing headers, bodies and footers
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    ConventionalCommit
+		ok      bool
+	}{
+		{
+			name:    "header only",
+			message: "feat: add login\n",
+			want:    ConventionalCommit{Type: "feat", Description: "add login"},
+			ok:      true,
+		},
+		{
+			name:    "scope and breaking marker",
+			message: "Fix(api)!: drop v1 endpoints",
+			want: ConventionalCommit{
+				Type: "fix", Scope: "api", Description: "drop v1 endpoints",
+				Breaking: true, BreakingNote: "drop v1 endpoints",
+			},
+			ok: true,
+		},
+		{
+			name: "body and trailers",
+			message: "fix(parser): handle empty input\n\nThe parser crashed on empty files.\n\nIt now returns an error.\n\n" +
+				"Refs #42\nSigned-off-by: Jane Doe <jane@example.com>\n",
+			want: ConventionalCommit{
+				Type: "fix", Scope: "parser", Description: "handle empty input",
+				Body: "The parser crashed on empty files.\n\nIt now returns an error.",
+				Trailers: []Trailer{
+					{Key: "Refs", Value: "42"},
+					{Key: "Signed-off-by", Value: "Jane Doe <jane@example.com>"},
+				},
+			},
+			ok: true,
+		},
+		{
+			name:    "breaking change footer with continuation",
+			message: "refactor: rename config keys\n\nBREAKING CHANGE: `timeout` is now `timeoutSeconds`\nand `retries` was removed\nReviewed-by: Bob",
+			want: ConventionalCommit{
+				Type: "refactor", Description: "rename config keys",
+				Breaking: true, BreakingNote: "`timeout` is now `timeoutSeconds`\nand `retries` was removed",
+				Trailers: []Trailer{
+					{Key: "BREAKING CHANGE", Value: "`timeout` is now `timeoutSeconds`\nand `retries` was removed"},
+					{Key: "Reviewed-by", Value: "Bob"},
+				},
+			},
+			ok: true,
+		},
+		{
+			name:    "breaking change in the body",
+			message: "fix: rename flag\n\nBREAKING CHANGE: --foo is now --bar\n\nSee the migration guide.",
+			want: ConventionalCommit{
+				Type: "fix", Description: "rename flag",
+				Body:     "BREAKING CHANGE: --foo is now --bar\n\nSee the migration guide.",
+				Breaking: true, BreakingNote: "--foo is now --bar",
+			},
+			ok: true,
+		},
+		{
+			name:    "final paragraph that is not a footer block",
+			message: "docs: explain retries\n\nNote: retries back off exponentially\nup to one minute.",
+			want: ConventionalCommit{
+				Type: "docs", Description: "explain retries",
+				Body: "Note: retries back off exponentially\nup to one minute.",
+			},
+			ok: true,
+		},
+		{
+			name:    "indented footer continuation",
+			message: "feat: add flag\n\nCo-authored-by: Jane Doe\n  <jane@example.com>",
+			want: ConventionalCommit{
+				Type: "feat", Description: "add flag",
+				Trailers: []Trailer{{Key: "Co-authored-by", Value: "Jane Doe\n<jane@example.com>"}},
+			},
+			ok: true,
+		},
+		{name: "not conventional", message: "Update README"},
+		{name: "merge commit", message: "Merge branch 'feature': fix"},
+		{name: "empty description", message: "feat: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseConventionalCommit(tt.message)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+// TestChangelog tests generating a changelog for a revision range
+func TestChangelog(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.commitChange(t, "old.txt", "feat: before the release")
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v1.0.0", "HEAD", "", false))
+
+	tr.commitChange(t, "api/handler.go", "feat(api): add pagination")
+	tr.commitChange(t, "docs/guide.md", "docs: explain pagination")
+	tr.commitChange(t, "cli/main.go", "fix(cli): exit code on error")
+	breaking := tr.commitChange(t, "api/routes.go", "feat(api)!: remove legacy routes\n\nBREAKING CHANGE: /v1 routes are gone")
+	tr.commitChange(t, "notes.txt", "Tidy up")
+
+	cl, err := tr.repo.Changelog(tr.ctx, ChangelogOpts{From: "v1.0.0", Title: "v2.0.0"})
+	require.NoError(t, err)
+
+	require.Len(t, cl.Groups, 3)
+	assert.Equal(t, "feat", cl.Groups[0].Type)
+	assert.Equal(t, "api", cl.Groups[0].Scope)
+	assert.Equal(t, "Features", cl.Groups[0].Title)
+	require.Len(t, cl.Groups[0].Entries, 2)
+	assert.Equal(t, "remove legacy routes", cl.Groups[0].Entries[0].Description, "newest first")
+	assert.Equal(t, "add pagination", cl.Groups[0].Entries[1].Description)
+	assert.Equal(t, "fix", cl.Groups[1].Type)
+	assert.Equal(t, "docs", cl.Groups[2].Type)
+
+	require.Len(t, cl.Breaking, 1)
+	assert.Equal(t, breaking, cl.Breaking[0].Hash)
+	assert.Equal(t, "/v1 routes are gone", cl.Breaking[0].BreakingNote)
+
+	want := "## v2.0.0\n\n" +
+		"### BREAKING CHANGES\n\n" +
+		"- **api:** /v1 routes are gone (" + breaking[:7] + ")\n\n" +
+		"### Features\n\n" +
+		"- **api:** remove legacy routes (" + breaking[:7] + ")\n" +
+		"- **api:** add pagination (" + cl.Groups[0].Entries[1].Hash[:7] + ")\n\n" +
+		"### Bug Fixes\n\n" +
+		"- **cli:** exit code on error (" + cl.Groups[1].Entries[0].Hash[:7] + ")\n\n" +
+		"### Documentation\n\n" +
+		"- explain pagination (" + cl.Groups[2].Entries[0].Hash[:7] + ")\n"
+	assert.Equal(t, want, cl.Markdown())
+
+	data, err := cl.JSON()
+	require.NoError(t, err)
+	var decoded Changelog
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "v1.0.0", decoded.From)
+	assert.Equal(t, "HEAD", decoded.To)
+	assert.Equal(t, "add pagination", decoded.Groups[0].Entries[1].Description)
+	assert.True(t, decoded.Breaking[0].Breaking)
+}
+
+// TestChangelogShallow tests that only the range is read in a shallow clone
+func TestChangelogShallow(t *testing.T) {
+	tr, shas := setupShallowClone(t, 2, nil, "chore: init", "fix: first", "feat: released", "feat: new")
+
+	cl, err := tr.repo.Changelog(tr.ctx, ChangelogOpts{From: "HEAD~1"})
+	require.NoError(t, err)
+	require.Len(t, cl.Groups, 1)
+	require.Len(t, cl.Groups[0].Entries, 1)
+	assert.Equal(t, shas[3], cl.Groups[0].Entries[0].Hash)
+}
+
+// TestChangelogFilters tests path, type and non-conventional commit filtering
+func TestChangelogFilters(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.commitChange(t, "services/api/main.go", "feat(api): add endpoint")
+	tr.commitChange(t, "services/web/app.js", "feat(web): add page")
+	tr.commitChange(t, "services/api/util.go", "chore(api): tidy")
+	tr.commitChange(t, "services/api/db.go", "Quick fix for db")
+
+	cl, err := tr.repo.Changelog(tr.ctx, ChangelogOpts{Path: []string{"services/api/"}})
+	require.NoError(t, err)
+	require.Len(t, cl.Groups, 2)
+	assert.Equal(t, "add endpoint", cl.Groups[0].Entries[0].Description)
+	assert.Equal(t, "chore", cl.Groups[1].Type)
+
+	cl, err = tr.repo.Changelog(tr.ctx, ChangelogOpts{Path: []string{"services/api/"}, Types: []string{"feat", "fix"}})
+	require.NoError(t, err)
+	require.Len(t, cl.Groups, 1)
+	assert.Equal(t, "feat", cl.Groups[0].Type)
+
+	cl, err = tr.repo.Changelog(tr.ctx, ChangelogOpts{Path: []string{"services/api/"}, IncludeOther: true})
+	require.NoError(t, err)
+	require.Len(t, cl.Groups, 3)
+	other := cl.Groups[2]
+	assert.Empty(t, other.Type)
+	assert.Equal(t, "Other Changes", other.Title)
+	assert.Equal(t, "Quick fix for db", other.Entries[0].Description)
+
+	_, err = tr.repo.Changelog(tr.ctx, ChangelogOpts{From: "nonexistent"})
+	require.ErrorIs(t, err, ErrResolveFailed)
+}
@@ -0,0 +1,207 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains helpers that check out files into the index and working
+// tree, shared by merges, stashes and resets.
+package git
+
+import (
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+)
+
+// readIndex returns the merged entries of the index keyed by path and the
+// paths with unresolved conflicts.
+func (r *Repo) readIndex() (map[string]treeFile, []string, error) {
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return nil, nil, WrapError(err, "failed to read index")
+	}
+
+	files := make(map[string]treeFile, len(idx.Entries))
+	var conflicted []string
+	for _, e := range idx.Entries {
+		if e.Stage != 0 {
+			if len(conflicted) == 0 || conflicted[len(conflicted)-1] != e.Name {
+				conflicted = append(conflicted, e.Name)
+			}
+			continue
+		}
+		files[e.Name] = treeFile{hash: e.Hash, mode: e.Mode}
+	}
+
+	return files, conflicted, nil
+}
+
+// stageFiles sets the index entries of the given paths, removing the paths
+// mapped to nil.
+func (r *Repo) stageFiles(files map[string]*treeFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return WrapError(err, "failed to read index")
+	}
+
+	for p, f := range files {
+		if f == nil {
+			_, _ = idx.Remove(p)
+			continue
+		}
+		e, err := idx.Entry(p)
+		if err != nil {
+			e = idx.Add(p)
+		}
+		e.Hash = f.hash
+		e.Mode = f.mode
+		e.ModifiedAt = time.Time{}
+		e.Size = 0
+	}
+
+	if err := r.repo.Storer.SetIndex(idx); err != nil {
+		return WrapError(err, "failed to write index")
+	}
+
+	return nil
+}
+
+// checkUntrackedFiles returns ErrDirtyWorktree if checking out the to files
+// over the from files would overwrite a file or directory that from does not
+// track, like `git checkout` refusing to clobber untracked files.
+func (r *Repo) checkUntrackedFiles(from, to map[string]treeFile) error {
+	wfs := r.worktree.Filesystem
+
+	for p, f := range to {
+		if _, tracked := from[p]; tracked || f.mode == filemode.Submodule {
+			continue
+		}
+		if _, err := wfs.Lstat(p); err == nil {
+			return WrapErrorf(ErrDirtyWorktree, "untracked file %q would be overwritten", p)
+		}
+
+		// An untracked file where a new directory is needed
+		for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+			if _, tracked := from[dir]; tracked {
+				break
+			}
+			if info, err := wfs.Lstat(dir); err == nil && !info.IsDir() {
+				return WrapErrorf(ErrDirtyWorktree, "untracked file %q would be overwritten", dir)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkoutFiles updates the working tree from the from files to the to files,
+// removing files that are only in from and writing changed files. Removals go
+// first so a file can replace a directory and the other way around.
+func (r *Repo) checkoutFiles(from, to map[string]treeFile) error {
+	for p := range from {
+		if _, ok := to[p]; !ok {
+			if err := r.removeWorktreeFile(p); err != nil {
+				return err
+			}
+		}
+	}
+
+	for p, f := range to {
+		if f.mode == filemode.Submodule {
+			continue
+		}
+		if old, ok := from[p]; ok && old == f {
+			continue
+		}
+		if err := r.writeWorktreeFile(p, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeWorktreeFile writes the blob of f to the working tree at p.
+func (r *Repo) writeWorktreeFile(p string, f treeFile) error {
+	wfs := r.worktree.Filesystem
+
+	content, err := r.readBlob(f.hash)
+	if err != nil {
+		return err
+	}
+	if dir := path.Dir(p); dir != "." {
+		if err := wfs.MkdirAll(dir, 0o755); err != nil {
+			return WrapErrorf(err, "failed to create directory %q", dir)
+		}
+	}
+	if err := wfs.Remove(p); err != nil && !os.IsNotExist(err) {
+		return WrapErrorf(err, "failed to replace %q", p)
+	}
+
+	switch f.mode {
+	case filemode.Symlink:
+		err = wfs.Symlink(string(content), p)
+	case filemode.Executable:
+		err = util.WriteFile(wfs, p, content, 0o755)
+	default:
+		err = util.WriteFile(wfs, p, content, 0o644)
+	}
+	if err != nil {
+		return WrapErrorf(err, "failed to write %q", p)
+	}
+
+	return nil
+}
+
+// removeWorktreeFile deletes p from the working tree along with any parent
+// directories left empty.
+func (r *Repo) removeWorktreeFile(p string) error {
+	wfs := r.worktree.Filesystem
+
+	if err := wfs.Remove(p); err != nil && !os.IsNotExist(err) {
+		return WrapErrorf(err, "failed to remove %q", p)
+	}
+
+	for dir := path.Dir(p); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		entries, err := wfs.ReadDir(dir)
+		if err != nil || len(entries) > 0 {
+			break
+		}
+		if err := wfs.Remove(dir); err != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+// changedFiles returns the paths that differ between two sets of files, sorted.
+func changedFiles(a, b map[string]treeFile) []string {
+	var changed []string
+	for p := range unionKeys(a, b) {
+		x, inA := a[p]
+		y, inB := b[p]
+		if inA != inB || x != y {
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed
+}
+
+// unionKeys returns the set of keys present in either map.
+func unionKeys(a, b map[string]treeFile) map[string]struct{} {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	return keys
+}
@@ -0,0 +1,300 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains describe operations (nearest tag for version stamping).
+package git
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	// defaultAbbrev is the default length of abbreviated hashes.
+	defaultAbbrev = 7
+
+	// describeCandidates is the number of tags considered by Describe, in the
+	// order they are found walking back from the described commit, like the
+	// default of `git describe --candidates`.
+	describeCandidates = 10
+)
+
+// DescribeOpts configures Describe.
+type DescribeOpts struct {
+	// AnnotatedOnly ignores lightweight tags, like `git describe` without --tags.
+	AnnotatedOnly bool
+
+	// Filters limits the tags considered to those passing all filters.
+	Filters []TagFilter
+
+	// Dirty checks the working tree for changes to tracked files when
+	// describing HEAD, like `git describe --dirty`.
+	Dirty bool
+
+	// Always falls back to the abbreviated hash when no tag is reachable,
+	// like `git describe --always`.
+	Always bool
+
+	// Abbrev is the length of the abbreviated hash. Defaults to 7.
+	Abbrev int
+}
+
+// Description identifies a commit relative to its nearest tag.
+type Description struct {
+	// Tag is the nearest tag, empty if none is reachable (with Always).
+	Tag string
+
+	// Distance is the number of commits between Tag and the described commit.
+	Distance int
+
+	// Commit is the SHA of the described commit.
+	Commit string
+
+	// Hash is the abbreviated SHA of the described commit.
+	Hash string
+
+	// Dirty indicates the working tree has changes to tracked files.
+	Dirty bool
+}
+
+// String formats the description like `git describe`, e.g., "v1.2.0",
+// "v1.2.0-3-gabc1234" or "v1.2.0-3-gabc1234-dirty".
+func (d Description) String() string {
+	s := d.Hash
+	switch {
+	case d.Tag != "" && d.Distance > 0:
+		s = fmt.Sprintf("%s-%d-g%s", d.Tag, d.Distance, d.Hash)
+	case d.Tag != "":
+		s = d.Tag
+	}
+	if d.Dirty {
+		s += "-dirty"
+	}
+	return s
+}
+
+// describeTag is a tag considered by Describe.
+type describeTag struct {
+	name      string
+	annotated bool
+}
+
+// describeCandidate is a tagged commit found by describeWalk.
+type describeCandidate struct {
+	commit   plumbing.Hash
+	distance int
+}
+
+// Describe finds the tag nearest to rev, i.e., reachable from rev with the
+// fewest commits in between, like `git describe --tags`. Lightweight tags are
+// considered unless opts.AnnotatedOnly is set. When several tags point to the
+// same commit, annotated tags are preferred, then the first by name. An empty
+// rev means HEAD.
+//
+// Returns ErrTagMissing if no tag is reachable and opts.Always is not set,
+// and ErrInvalidRef if opts.Dirty is set for a bare repository or a revision
+// other than HEAD.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Describe(ctx context.Context, rev string, opts DescribeOpts) (*Description, error) {
+	if opts.Dirty && r.worktree == nil {
+		return nil, WrapError(ErrInvalidRef, "cannot check for local changes in bare repository")
+	}
+	if opts.Dirty && rev != "" && rev != gitHead {
+		return nil, WrapErrorf(ErrInvalidRef, "cannot check for local changes when describing %q", rev)
+	}
+	if rev == "" {
+		rev = gitHead
+	}
+
+	commit, err := r.commitOf(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	abbrev := opts.Abbrev
+	if abbrev <= 0 {
+		abbrev = defaultAbbrev
+	}
+	sha := commit.Hash.String()
+	desc := &Description{Commit: sha, Hash: sha[:min(abbrev, len(sha))]}
+
+	if opts.Dirty {
+		status, err := r.Status(ctx, StatusOpts{ExcludeUntracked: true})
+		if err != nil {
+			return nil, err
+		}
+		desc.Dirty = !status.IsClean()
+	}
+
+	tags, err := r.describeTags(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	best, err := r.describeWalk(ctx, commit.Hash, tags)
+	if err != nil {
+		return nil, err
+	}
+	if best == nil {
+		if opts.Always {
+			return desc, nil
+		}
+		return nil, WrapErrorf(ErrTagMissing, "no tag reachable from %q", rev)
+	}
+
+	desc.Tag = tags[best.commit][0].name
+	desc.Distance = best.distance
+
+	return desc, nil
+}
+
+// describeTags returns the tags selected by opts keyed by the commit they
+// point to, each sorted by preference.
+func (r *Repo) describeTags(ctx context.Context, opts DescribeOpts) (map[plumbing.Hash][]describeTag, error) {
+	refs, err := r.repo.Tags()
+	if err != nil {
+		return nil, WrapError(err, "failed to get tags")
+	}
+
+	tags := make(map[plumbing.Hash][]describeTag)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		name := ref.Name().Short()
+		if !shouldIncludeTag(name, ref, opts.Filters) {
+			return nil
+		}
+
+		_, tagErr := r.repo.TagObject(ref.Hash())
+		annotated := tagErr == nil
+		if opts.AnnotatedOnly && !annotated {
+			return nil
+		}
+
+		commit, ok := r.peelTag(ref)
+		if !ok {
+			return nil
+		}
+
+		tags[commit] = append(tags[commit], describeTag{name: name, annotated: annotated})
+		return nil
+	})
+	if err != nil {
+		return nil, WrapError(err, "failed to iterate tags")
+	}
+
+	for _, named := range tags {
+		sort.Slice(named, func(i, j int) bool {
+			if named[i].annotated != named[j].annotated {
+				return named[i].annotated
+			}
+			return named[i].name < named[j].name
+		})
+	}
+
+	return tags, nil
+}
+
+// describeWalk walks back from head by commit time in a single pass, like
+// `git describe`, and returns the nearest tagged commit, nil if none is
+// reachable. The first tagged commits found become candidates, each flagging
+// the commits reachable from it, and a candidate's distance counts the walked
+// commits it does not flag. The walk stops at head itself if it is tagged, or
+// once every queued commit is flagged by the best candidate, as no commit left
+// can change its distance or yield a nearer tag.
+func (r *Repo) describeWalk(ctx context.Context, head plumbing.Hash, tags map[plumbing.Hash][]describeTag) (*describeCandidate, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	if _, ok := tags[head]; ok {
+		return &describeCandidate{commit: head}, nil
+	}
+
+	start, err := r.repo.CommitObject(head)
+	if err != nil {
+		return nil, WrapError(err, "failed to walk commit history")
+	}
+
+	var (
+		candidates []describeCandidate
+		queue      commitQueue
+		flags      = map[plumbing.Hash]uint16{head: 0}
+		walked     int
+	)
+	heap.Push(&queue, start)
+
+	for queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, WrapError(err, "failed to walk commit history")
+		}
+
+		c, _ := heap.Pop(&queue).(*object.Commit)
+		walked++
+
+		if _, ok := tags[c.Hash]; ok && len(candidates) < describeCandidates {
+			// Every commit walked so far is not reachable from the tag
+			candidates = append(candidates, describeCandidate{commit: c.Hash, distance: walked - 1})
+			flags[c.Hash] |= 1 << (len(candidates) - 1)
+		}
+		for i := range candidates {
+			if flags[c.Hash]&(1<<i) == 0 {
+				candidates[i].distance++
+			}
+		}
+
+		for _, parent := range c.ParentHashes {
+			if _, seen := flags[parent]; !seen {
+				p, err := r.repo.CommitObject(parent)
+				if errors.Is(err, plumbing.ErrObjectNotFound) {
+					continue // Boundary of a shallow clone
+				}
+				if err != nil {
+					return nil, WrapError(err, "failed to walk commit history")
+				}
+				heap.Push(&queue, p)
+			}
+			flags[parent] |= flags[c.Hash]
+		}
+
+		if len(candidates) > 0 && describeDone(queue, flags, candidates) {
+			break
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	best := bestDescribeCandidate(candidates)
+	return &candidates[best], nil
+}
+
+// bestDescribeCandidate returns the index of the candidate with the smallest
+// distance, the first found on ties.
+func bestDescribeCandidate(candidates []describeCandidate) int {
+	best := 0
+	for i, candidate := range candidates {
+		if candidate.distance < candidates[best].distance {
+			best = i
+		}
+	}
+	return best
+}
+
+// describeDone reports whether every queued commit is reachable from the
+// best candidate.
+func describeDone(queue commitQueue, flags map[plumbing.Hash]uint16, candidates []describeCandidate) bool {
+	bit := uint16(1) << bestDescribeCandidate(candidates)
+	for _, c := range queue.commits {
+		if flags[c.Hash]&bit == 0 {
+			return false
+		}
+	}
+	return true
+}
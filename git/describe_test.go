@@ -0,0 +1,131 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDescribe tests describing commits relative to the nearest tag
+func TestDescribe(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v1.0.0", "HEAD", "", false))
+	a := tr.commitFile(t, "a.txt", "a")
+	b := tr.commitFile(t, "b.txt", "b")
+
+	desc, err := tr.repo.Describe(tr.ctx, "", DescribeOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, Description{Tag: "v1.0.0", Distance: 2, Commit: b, Hash: b[:7]}, *desc)
+	assert.Equal(t, "v1.0.0-2-g"+b[:7], desc.String())
+
+	// The nearest tag wins
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v1.1.0", a, "Release 1.1.0", true))
+	desc, err = tr.repo.Describe(tr.ctx, "HEAD", DescribeOpts{Abbrev: 12})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0-1-g"+b[:12], desc.String())
+
+	desc, err = tr.repo.Describe(tr.ctx, "", DescribeOpts{Filters: []TagFilter{TagPatternFilter("v1.0.*")}})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0-2-g"+b[:7], desc.String())
+
+	// Exact matches prefer annotated tags
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "a-light", "HEAD", "", false))
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "z-annotated", "HEAD", "Annotated", true))
+	desc, err = tr.repo.Describe(tr.ctx, "", DescribeOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, "z-annotated", desc.String())
+	assert.Equal(t, 0, desc.Distance)
+
+	desc, err = tr.repo.Describe(tr.ctx, "v1.0.0", DescribeOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", desc.String())
+}
+
+// TestDescribeMerge tests counting the commits of every merged branch
+func TestDescribeMerge(t *testing.T) {
+	tr, commits := setupAncestryRepo(t)
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v1.0.0", commits.base, "Release 1.0.0", true))
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "feature-start", commits.c, "", false))
+	merge, err := tr.repo.Merge(tr.ctx, "feature", mergeSig, MergeOpts{})
+	require.NoError(t, err)
+
+	// Not reachable from c: the merge, d and e
+	desc, err := tr.repo.Describe(tr.ctx, "", DescribeOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, "feature-start-3-g"+merge[:7], desc.String())
+
+	// Not reachable from base: the merge, c, d and e
+	desc, err = tr.repo.Describe(tr.ctx, "", DescribeOpts{AnnotatedOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0-4-g"+merge[:7], desc.String())
+
+	desc, err = tr.repo.Describe(tr.ctx, commits.e, DescribeOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0-1-g"+commits.e[:7], desc.String())
+}
+
+// TestDescribeAnnotatedOnly tests ignoring lightweight tags and the hash fallback
+func TestDescribeAnnotatedOnly(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v1.0.0", "HEAD", "", false))
+	sha := tr.commitFile(t, "a.txt", "a")
+
+	_, err := tr.repo.Describe(tr.ctx, "", DescribeOpts{AnnotatedOnly: true})
+	require.ErrorIs(t, err, ErrTagMissing)
+
+	desc, err := tr.repo.Describe(tr.ctx, "", DescribeOpts{AnnotatedOnly: true, Always: true})
+	require.NoError(t, err)
+	assert.Equal(t, sha[:7], desc.String())
+	assert.Empty(t, desc.Tag)
+
+	_, err = tr.repo.Describe(tr.ctx, "nonexistent", DescribeOpts{})
+	require.ErrorIs(t, err, ErrResolveFailed)
+}
+
+// TestDescribeDirty tests marking working trees with local changes
+func TestDescribeDirty(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v1.0.0", "HEAD", "", false))
+
+	// Untracked files do not make the tree dirty
+	require.NoError(t, tr.fs.WriteFile("scratch.txt", []byte("scratch"), 0o644))
+	desc, err := tr.repo.Describe(tr.ctx, "", DescribeOpts{Dirty: true})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", desc.String())
+
+	tr.modifyTestFile(t, "modified")
+	desc, err = tr.repo.Describe(tr.ctx, "", DescribeOpts{Dirty: true})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0-dirty", desc.String())
+
+	desc, err = tr.repo.Describe(tr.ctx, "", DescribeOpts{})
+	require.NoError(t, err)
+	assert.False(t, desc.Dirty, "only checked when requested")
+
+	_, err = tr.repo.Describe(tr.ctx, "v1.0.0", DescribeOpts{Dirty: true})
+	require.ErrorIs(t, err, ErrInvalidRef)
+
+	bare := setupTestRepo(t, true)
+	_, err = bare.repo.Describe(bare.ctx, "", DescribeOpts{Dirty: true})
+	require.ErrorIs(t, err, ErrInvalidRef)
+}
+
+// TestDescription_String tests formatting descriptions like git describe
+func TestDescription_String(t *testing.T) {
+	tests := []struct {
+		desc Description
+		want string
+	}{
+		{Description{Tag: "v1.2.0", Hash: "abc1234"}, "v1.2.0"},
+		{Description{Tag: "v1.2.0", Distance: 3, Hash: "abc1234"}, "v1.2.0-3-gabc1234"},
+		{Description{Tag: "v1.2.0", Distance: 3, Hash: "abc1234", Dirty: true}, "v1.2.0-3-gabc1234-dirty"},
+		{Description{Hash: "abc1234", Dirty: true}, "abc1234-dirty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.desc.String())
+		})
+	}
+}
@@ -4,13 +4,21 @@ package git
 
 import (
 	"context"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
 	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/input-output-hk/catalyst-forge-libs/git/internal/diff"
 )
 
+// diffContextLines is the number of unchanged lines shown around changes in hunks.
+const diffContextLines = 3
+
 // PatchText represents unified diff text between two revisions.
 // It contains the formatted diff output that can be displayed to users
 // or processed by other tools.
@@ -24,6 +32,128 @@ type PatchText struct {
 
 	// FileCount indicates the number of files that have changes.
 	FileCount int
+
+	// Files describes each changed file.
+	Files []FileDiff
+
+	// Additions and Deletions are the total added and deleted lines of text files.
+	Additions int
+	Deletions int
+}
+
+// ChangeType describes how a file changed between two revisions.
+type ChangeType int8
+
+const (
+	// ChangeModified means the file content or mode changed.
+	ChangeModified ChangeType = iota
+
+	// ChangeAdded means the file was added.
+	ChangeAdded
+
+	// ChangeDeleted means the file was deleted.
+	ChangeDeleted
+
+	// ChangeRenamed means the file was moved, possibly with changes.
+	ChangeRenamed
+)
+
+// String returns a human-readable string representation of the ChangeType.
+func (c ChangeType) String() string {
+	switch c {
+	case ChangeModified:
+		return "modified"
+	case ChangeAdded:
+		return "added"
+	case ChangeDeleted:
+		return "deleted"
+	case ChangeRenamed:
+		return "renamed"
+	default:
+		return "unknown"
+	}
+}
+
+// FileDiff describes the changes to a single file.
+type FileDiff struct {
+	// Type is how the file changed.
+	Type ChangeType
+
+	// OldPath and NewPath are the paths before and after the change.
+	// OldPath is empty for added files and NewPath for deleted files.
+	OldPath string
+	NewPath string
+
+	// OldMode and NewMode are the file modes before and after the change,
+	// or 0 when the file does not exist on that side.
+	OldMode os.FileMode
+	NewMode os.FileMode
+
+	// OldHash and NewHash are the blob hashes, or empty when absent.
+	OldHash string
+	NewHash string
+
+	// IsBinary is true if either version has binary content
+	// (a NUL byte in the first 8000 bytes, like git).
+	IsBinary bool
+
+	// Additions and Deletions are the added and deleted lines (0 for binary files).
+	Additions int
+	Deletions int
+
+	// Hunks are the changed regions with 3 lines of context (none for binary files).
+	Hunks []Hunk
+}
+
+// Path returns the new path of the file, or the old path if it was deleted.
+func (f FileDiff) Path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// Hunk is a changed region of a file, like a unified diff
+// "@@ -OldStart,OldLines +NewStart,NewLines @@" section.
+type Hunk struct {
+	// OldStart and OldLines are the first line and line count in the old file.
+	OldStart int
+	OldLines int
+
+	// NewStart and NewLines are the first line and line count in the new file.
+	NewStart int
+	NewLines int
+
+	// Lines are the context, added and deleted lines of the hunk.
+	Lines []DiffLine
+}
+
+// LineOp is the kind of a line in a hunk.
+type LineOp int8
+
+const (
+	// LineContext is an unchanged line.
+	LineContext LineOp = iota
+
+	// LineAdded is a line only in the new file.
+	LineAdded
+
+	// LineDeleted is a line only in the old file.
+	LineDeleted
+)
+
+// DiffLine is a single line of a hunk.
+type DiffLine struct {
+	// Op is whether the line is context, added or deleted.
+	Op LineOp
+
+	// Content is the line text without its line ending.
+	Content string
+
+	// OldLine and NewLine are 1-based line numbers in the old and new file,
+	// or 0 when the line does not exist on that side.
+	OldLine int
+	NewLine int
 }
 
 // ChangeFilter is a predicate function for filtering changes in diffs.
@@ -31,6 +161,18 @@ type PatchText struct {
 // Filters are applied progressively - if any filter returns false, the change is excluded.
 type ChangeFilter func(*object.Change) bool
 
+// DiffOpts configures DiffWith.
+type DiffOpts struct {
+	// DetectRenames pairs deleted and added files with similar content into
+	// ChangeRenamed entries, like `git diff -M`. Without it a moved file is a
+	// deletion and an addition, as with Diff.
+	DetectRenames bool
+
+	// Filters are applied like the filters of Diff. A renamed file is seen by
+	// the filters as a single change with both From and To set.
+	Filters []ChangeFilter
+}
+
 // Diff computes the diff between two revisions and returns unified diff text.
 // The revisions 'a' and 'b' can be any valid git revision specifiers (commit hashes,
 // branch names, tags, etc.).
@@ -42,8 +184,19 @@ type ChangeFilter func(*object.Change) bool
 // the result accordingly. The returned PatchText contains the unified diff text
 // that can be displayed to users or processed by other tools.
 //
+// Renames are not detected, so a moved file is reported as a deletion and an
+// addition. Use DiffWith to detect renames.
+//
 // Context timeout/cancellation is honored during the operation.
 func (r *Repo) Diff(ctx context.Context, a, b string, filters ...ChangeFilter) (*PatchText, error) {
+	return r.DiffWith(ctx, a, b, DiffOpts{Filters: filters})
+}
+
+// DiffWith computes the diff between two revisions like Diff, with the
+// options in opts.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) DiffWith(ctx context.Context, a, b string, opts DiffOpts) (*PatchText, error) {
 	// Validate inputs
 	if err := validateDiffInputs(a, b); err != nil {
 		return nil, err
@@ -55,23 +208,27 @@ func (r *Repo) Diff(ctx context.Context, a, b string, filters ...ChangeFilter) (
 		return nil, err
 	}
 
-	// Get all changes between the trees
-	changes, err := treeA.Diff(treeB)
+	// Get all changes between the trees, detecting renames if requested
+	treeOpts := &object.DiffTreeOptions{}
+	if opts.DetectRenames {
+		treeOpts = object.DefaultDiffTreeOptions
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, treeA, treeB, treeOpts)
 	if err != nil {
 		return nil, WrapError(err, "failed to compute changes")
 	}
 
 	// Apply filters and get filtered changes
-	filteredChanges := applyChangeFilters(changes, filters)
+	filteredChanges := applyChangeFilters(changes, opts.Filters)
 
 	// Generate patch from filtered changes
-	patch, err := filteredChanges.Patch()
+	patch, err := filteredChanges.PatchContext(ctx)
 	if err != nil {
 		return nil, WrapError(err, "failed to generate patch")
 	}
 
 	// Build and return the result
-	return buildPatchResult(patch.String(), filteredChanges), nil
+	return buildPatchResult(patch), nil
 }
 
 // validateDiffInputs validates the revision inputs for diff
@@ -143,41 +300,105 @@ func shouldIncludeChange(change *object.Change, filters []ChangeFilter) bool {
 	return true
 }
 
-// buildPatchResult builds the PatchText result from the diff text and changes
-func buildPatchResult(diffText string, changes object.Changes) *PatchText {
-	return &PatchText{
-		Text:      diffText,
-		IsBinary:  containsBinaryChanges(changes),
-		FileCount: len(changes),
+// buildPatchResult builds the PatchText result from a patch
+func buildPatchResult(patch *object.Patch) *PatchText {
+	result := &PatchText{Text: patch.String()}
+
+	for _, fp := range patch.FilePatches() {
+		file := buildFileDiff(fp)
+		result.Files = append(result.Files, file)
+		result.IsBinary = result.IsBinary || file.IsBinary
+		result.Additions += file.Additions
+		result.Deletions += file.Deletions
 	}
+	result.FileCount = len(result.Files)
+
+	return result
 }
 
-// containsBinaryChanges checks if any of the changes are for binary files
-func containsBinaryChanges(changes object.Changes) bool {
-	for _, change := range changes {
-		if isBinaryPath(change.From.Name) || isBinaryPath(change.To.Name) {
-			return true
+// buildFileDiff converts a go-git file patch into a FileDiff
+func buildFileDiff(fp fdiff.FilePatch) FileDiff {
+	from, to := fp.Files()
+	file := FileDiff{IsBinary: fp.IsBinary()}
+
+	if from != nil {
+		file.OldPath = from.Path()
+		file.OldMode = osFileMode(from.Mode())
+		file.OldHash = from.Hash().String()
+	}
+	if to != nil {
+		file.NewPath = to.Path()
+		file.NewMode = osFileMode(to.Mode())
+		file.NewHash = to.Hash().String()
+	}
+
+	switch {
+	case from == nil:
+		file.Type = ChangeAdded
+	case to == nil:
+		file.Type = ChangeDeleted
+	case file.OldPath != file.NewPath:
+		file.Type = ChangeRenamed
+	default:
+		file.Type = ChangeModified
+	}
+
+	chunks := make([]diff.Chunk, 0, len(fp.Chunks()))
+	for _, chunk := range fp.Chunks() {
+		op := diff.Equal
+		switch chunk.Type() {
+		case fdiff.Add:
+			op = diff.Add
+		case fdiff.Delete:
+			op = diff.Delete
+		case fdiff.Equal:
 		}
+		chunks = append(chunks, diff.Chunk{Op: op, Content: chunk.Content()})
 	}
-	return false
+
+	file.Additions, file.Deletions = diff.Count(chunks)
+	for _, h := range diff.Hunks(chunks, diffContextLines) {
+		hunk := Hunk{OldStart: h.OldStart, OldLines: h.OldLines, NewStart: h.NewStart, NewLines: h.NewLines}
+		for _, line := range h.Lines {
+			hunk.Lines = append(hunk.Lines, DiffLine{
+				Op:      LineOp(line.Op),
+				Content: line.Text,
+				OldLine: line.OldLine,
+				NewLine: line.NewLine,
+			})
+		}
+		file.Hunks = append(file.Hunks, hunk)
+	}
+
+	return file
 }
 
-// isBinaryPath checks if a file path likely represents a binary file based on extension
-func isBinaryPath(path string) bool {
-	if path == "" {
+// osFileMode converts a git file mode, returning 0 for unknown modes
+func osFileMode(mode filemode.FileMode) os.FileMode {
+	m, err := mode.ToOSFileMode()
+	if err != nil {
+		return 0
+	}
+	return m
+}
+
+// isBinaryChange checks if either side of a change has binary content
+func isBinaryChange(change *object.Change) bool {
+	from, to, err := change.Files()
+	if err != nil {
 		return false
 	}
 
-	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
-	binaryExts := map[string]bool{
-		"png": true, "jpg": true, "jpeg": true, "gif": true, "ico": true,
-		"pdf": true, "zip": true, "tar": true, "gz": true, "bz2": true,
-		"exe": true, "dll": true, "so": true, "dylib": true, "bin": true,
-		"mp3": true, "mp4": true, "avi": true, "mov": true, "wav": true,
-		"ttf": true, "otf": true, "woff": true, "woff2": true, "eot": true,
+	for _, f := range []*object.File{from, to} {
+		if f == nil {
+			continue
+		}
+		if binary, err := f.IsBinary(); err == nil && binary {
+			return true
+		}
 	}
 
-	return binaryExts[ext]
+	return false
 }
 
 // Common ChangeFilter implementations for convenience
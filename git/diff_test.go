@@ -2,6 +2,8 @@ package git
 
 import (
 	"context"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -111,3 +113,79 @@ func TestDiff(t *testing.T) {
 		})
 	}
 }
+
+// TestDiffFiles tests the structured per-file diff model
+func TestDiffFiles(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	lines := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	tr.commitFile(t, "numbers.txt", lines)
+	tr.commitFile(t, "moved.txt", strings.Repeat("unchanged content line\n", 20))
+	tr.commitFile(t, "gone.txt", "bye\n")
+
+	require.NoError(t, tr.fs.WriteFile("numbers.txt", []byte(strings.Replace(lines, "five\n", "FIVE\n", 1)+"eleven\n"), 0o644))
+	require.NoError(t, tr.fs.Rename("moved.txt", "renamed.txt"))
+	require.NoError(t, tr.fs.Remove("gone.txt"))
+	require.NoError(t, tr.fs.WriteFile("data.txt", []byte("text\x00with nul"), 0o644))
+	require.NoError(t, tr.fs.WriteFile("image.png", []byte("not really an image\n"), 0o644))
+	require.NoError(t, tr.repo.Add(tr.ctx, "numbers.txt", "renamed.txt", "data.txt", "image.png"))
+	require.NoError(t, tr.repo.Remove(tr.ctx, "moved.txt", "gone.txt"))
+	_, err := tr.repo.Commit(tr.ctx, "changes", Signature{Name: "Test", Email: "test@example.com", When: time.Now()}, CommitOpts{})
+	require.NoError(t, err)
+
+	// Without rename detection a move is a deletion and an addition
+	plain, err := tr.repo.Diff(tr.ctx, "HEAD~1", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, 6, plain.FileCount)
+	for _, f := range plain.Files {
+		assert.NotEqual(t, ChangeRenamed, f.Type, f.Path())
+	}
+
+	patch, err := tr.repo.DiffWith(tr.ctx, "HEAD~1", "HEAD", DiffOpts{DetectRenames: true})
+	require.NoError(t, err)
+
+	files := map[string]FileDiff{}
+	for _, f := range patch.Files {
+		files[f.Path()] = f
+	}
+	require.Len(t, files, 5)
+	assert.Equal(t, 5, patch.FileCount)
+
+	numbers := files["numbers.txt"]
+	assert.Equal(t, ChangeModified, numbers.Type)
+	assert.Equal(t, "numbers.txt", numbers.OldPath)
+	assert.Equal(t, os.FileMode(0o644), numbers.NewMode)
+	assert.Equal(t, 2, numbers.Additions)
+	assert.Equal(t, 1, numbers.Deletions)
+	require.Len(t, numbers.Hunks, 1)
+	hunk := numbers.Hunks[0]
+	assert.Equal(t, []int{2, 9, 2, 10}, []int{hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines})
+	assert.Equal(t, DiffLine{Op: LineDeleted, Content: "five", OldLine: 5}, hunk.Lines[3])
+	assert.Equal(t, DiffLine{Op: LineAdded, Content: "FIVE", NewLine: 5}, hunk.Lines[4])
+	assert.Equal(t, DiffLine{Op: LineAdded, Content: "eleven", NewLine: 11}, hunk.Lines[len(hunk.Lines)-1])
+
+	renamed := files["renamed.txt"]
+	assert.Equal(t, ChangeRenamed, renamed.Type)
+	assert.Equal(t, "moved.txt", renamed.OldPath)
+	assert.Equal(t, renamed.OldHash, renamed.NewHash)
+	assert.Empty(t, renamed.Hunks)
+
+	gone := files["gone.txt"]
+	assert.Equal(t, ChangeDeleted, gone.Type)
+	assert.Empty(t, gone.NewPath)
+	assert.Equal(t, 1, gone.Deletions)
+
+	// Binary detection is based on content, not extension
+	assert.True(t, files["data.txt"].IsBinary)
+	assert.Equal(t, ChangeAdded, files["data.txt"].Type)
+	assert.Empty(t, files["data.txt"].Hunks)
+	assert.False(t, files["image.png"].IsBinary)
+	assert.True(t, patch.IsBinary)
+
+	assert.Equal(t, 3, patch.Additions, "binary files are not counted")
+	assert.Equal(t, 2, patch.Deletions)
+
+	filtered, err := tr.repo.DiffWith(tr.ctx, "HEAD~1", "HEAD", DiffOpts{DetectRenames: true, Filters: []ChangeFilter{NonBinaryFilter()}})
+	require.NoError(t, err)
+	assert.False(t, filtered.IsBinary)
+	assert.Equal(t, 4, filtered.FileCount)
+}
@@ -196,7 +196,7 @@
 // This package intentionally does not support:
 //   - Interactive operations (rebase -i, add -i)
 //   - Complex merge conflict resolution
-//   - Submodule management (may be added later)
+//   - Adding, moving or removing submodules (listing and updating are supported)
 //   - Direct git CLI invocation
 //
 // For advanced use cases not covered by this facade, the underlying
@@ -29,6 +29,12 @@ var ErrBranchExists = errors.New("branch already exists")
 // ErrBranchMissing is returned when attempting to operate on a branch that does not exist.
 var ErrBranchMissing = errors.New("branch does not exist")
 
+// ErrRemoteExists is returned when attempting to add a remote that already exists.
+var ErrRemoteExists = errors.New("remote already exists")
+
+// ErrRemoteMissing is returned when attempting to operate on a remote that does not exist.
+var ErrRemoteMissing = errors.New("remote does not exist")
+
 // ErrTagExists is returned when attempting to create a tag that already exists
 // and force creation was not requested.
 var ErrTagExists = errors.New("tag already exists")
@@ -36,6 +42,12 @@ var ErrTagExists = errors.New("tag already exists")
 // ErrTagMissing is returned when attempting to operate on a tag that does not exist.
 var ErrTagMissing = errors.New("tag does not exist")
 
+// ErrStashMissing is returned when a stash entry does not exist.
+var ErrStashMissing = errors.New("stash entry does not exist")
+
+// ErrNoVersion is returned when no semantic version tag matches a query.
+var ErrNoVersion = errors.New("no version tag found")
+
 // ErrNotFastForward is returned when a push or pull operation cannot be performed
 // as a fast-forward merge and requires manual conflict resolution.
 var ErrNotFastForward = errors.New("not a fast-forward")
@@ -56,6 +68,23 @@ var ErrResolveFailed = errors.New("cannot resolve revision")
 // and AllowEmpty is false.
 var ErrEmptyCommit = errors.New("cannot create empty commit")
 
+// ErrNoUpstream is returned when a branch has no upstream tracking branch configured.
+var ErrNoUpstream = errors.New("no upstream configured")
+
+// ErrDirtyWorktree is returned when an operation that rewrites the working tree
+// is attempted while tracked files have uncommitted changes.
+var ErrDirtyWorktree = errors.New("working tree has uncommitted changes")
+
+// ErrReadOnly is returned when attempting to write to a read-only view of a commit tree.
+var ErrReadOnly = errors.New("filesystem is read-only")
+
+// ErrNotSigned is returned when verifying a commit or tag that has no signature.
+var ErrNotSigned = errors.New("object is not signed")
+
+// ErrInvalidSignature is returned when a signature does not match the signed
+// object or was made by a key that is not trusted.
+var ErrInvalidSignature = errors.New("invalid signature")
+
 // WrapError wraps an error with additional context while preserving
 // the ability to check against sentinel errors using errors.Is().
 func WrapError(err error, msg string) error {
@@ -23,10 +23,19 @@ func TestSentinelErrors_Is(t *testing.T) {
 		{"ErrBranchMissing direct", ErrBranchMissing, ErrBranchMissing, true},
 		{"ErrTagExists direct", ErrTagExists, ErrTagExists, true},
 		{"ErrTagMissing direct", ErrTagMissing, ErrTagMissing, true},
+		{"ErrStashMissing direct", ErrStashMissing, ErrStashMissing, true},
+		{"ErrNoVersion direct", ErrNoVersion, ErrNoVersion, true},
 		{"ErrNotFastForward direct", ErrNotFastForward, ErrNotFastForward, true},
 		{"ErrMergeConflict direct", ErrMergeConflict, ErrMergeConflict, true},
 		{"ErrInvalidRef direct", ErrInvalidRef, ErrInvalidRef, true},
 		{"ErrResolveFailed direct", ErrResolveFailed, ErrResolveFailed, true},
+		{"ErrNoUpstream direct", ErrNoUpstream, ErrNoUpstream, true},
+		{"ErrDirtyWorktree direct", ErrDirtyWorktree, ErrDirtyWorktree, true},
+		{"ErrReadOnly direct", ErrReadOnly, ErrReadOnly, true},
+		{"ErrNotSigned direct", ErrNotSigned, ErrNotSigned, true},
+		{"ErrInvalidSignature direct", ErrInvalidSignature, ErrInvalidSignature, true},
+		{"ErrRemoteExists direct", ErrRemoteExists, ErrRemoteExists, true},
+		{"ErrRemoteMissing direct", ErrRemoteMissing, ErrRemoteMissing, true},
 
 		// Wrapped errors
 		{"ErrAlreadyUpToDate wrapped", WrapError(ErrAlreadyUpToDate, "context"), ErrAlreadyUpToDate, true},
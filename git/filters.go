@@ -39,6 +39,25 @@ func PathPrefixFilter(prefix string) ChangeFilter {
 	}
 }
 
+// DirFilter creates a filter that includes changes to files inside any of the given
+// directories. Unlike PathPrefixFilter, "services/api" does not match "services/api2".
+// Both the old and new file names are checked to handle renames and deletions.
+func DirFilter(dirs ...string) ChangeFilter {
+	cleaned := make([]string, len(dirs))
+	for i, dir := range dirs {
+		cleaned[i] = cleanRoot(dir)
+	}
+
+	return func(change *object.Change) bool {
+		for _, dir := range cleaned {
+			if inDir(change.From.Name, dir) || inDir(change.To.Name, dir) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 // ExtensionFilter creates a filter that includes changes for files with the given extensions.
 // Extensions should include the dot (e.g., ".go", ".js").
 func ExtensionFilter(extensions ...string) ChangeFilter {
@@ -67,14 +86,11 @@ func ExtensionFilter(extensions ...string) ChangeFilter {
 }
 
 // NonBinaryFilter creates a filter that excludes binary files.
-// It uses file extension heuristics to identify binary files.
+// Binary files are detected from their content, like git: a NUL byte in the
+// first 8000 bytes of either version.
 func NonBinaryFilter() ChangeFilter {
 	return func(change *object.Change) bool {
-		// Check if either file is likely binary
-		if isBinaryPath(change.From.Name) || isBinaryPath(change.To.Name) {
-			return false
-		}
-		return true
+		return !isBinaryChange(change)
 	}
 }
 
@@ -60,6 +60,12 @@ type Options struct {
 	// If > 0, operations will be shallow with the specified depth.
 	// If 0, full clone/fetch operations are performed.
 	ShallowDepth int
+
+	// RecurseSubmodules makes Clone initialize and update all submodules
+	// recursively after cloning (like `git clone --recurse-submodules`).
+	// Authentication for each submodule URL is resolved through Auth, and
+	// submodules are fetched with ShallowDepth like the repository itself.
+	RecurseSubmodules bool
 }
 
 // Validate checks that the Options are properly configured.
@@ -230,6 +236,7 @@ func Open(ctx context.Context, opts *Options) (*Repo, error) {
 //
 // The remoteURL should be a valid git URL (https://, ssh://, or file:// for local repos).
 // For shallow clones, set ShallowDepth > 0 to limit the clone depth.
+// Submodules are cloned as well when RecurseSubmodules is set, with the same depth.
 // Authentication is handled via the AuthProvider if credentials are required.
 //
 // Context timeout/cancellation is honored during the clone operation.
@@ -308,6 +315,13 @@ func Clone(ctx context.Context, remoteURL string, opts *Options) (*Repo, error)
 			return nil, WrapError(err, "failed to get worktree")
 		}
 		r.worktree = worktree
+
+		if opts.RecurseSubmodules {
+			err := r.UpdateSubmodules(ctx, SubmoduleUpdateOpts{Init: true, Recursive: true, Depth: opts.ShallowDepth})
+			if err != nil {
+				return nil, WrapError(err, "failed to update submodules")
+			}
+		}
 	}
 
 	return r, nil
@@ -349,6 +363,10 @@ type CommitOpts struct {
 	// Amend amends the tip of the current branch with this commit.
 	// This replaces the current commit rather than creating a new one.
 	Amend bool
+
+	// Signer signs the commit (e.g., an OpenPGPSigner or SSHSigner).
+	// A nil value creates an unsigned commit.
+	Signer Signer
 }
 
 // Repo represents a git repository and provides high-level operations.
@@ -298,11 +298,13 @@ func TestClone(t *testing.T) {
 type mockAuthProvider struct {
 	auth   transport.AuthMethod
 	called bool
+	urls   []string
 }
 
 //nolint:ireturn // transport.AuthMethod is an interface required by go-git
 func (m *mockAuthProvider) Method(remoteURL string) (transport.AuthMethod, error) {
 	m.called = true
+	m.urls = append(m.urls, remoteURL)
 	return m.auth, nil
 }
 
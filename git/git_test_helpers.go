@@ -132,3 +132,32 @@ func (tr *testRepo) verifyBranchNotExists(t *testing.T, branchName string) {
 	_, err := tr.repo.repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
 	require.Error(t, err, "branch should not exist: %s", branchName)
 }
+
+// setupShallowClone commits the messages in order to a repository on disk and
+// returns a clone of the given depth, with the commits at the indexes in tags
+// tagged, and the SHAs of the commits
+func setupShallowClone(t *testing.T, depth int, tags map[string]int, messages ...string) (*testRepo, []string) {
+	t.Helper()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	src, err := Init(ctx, &Options{FS: fsb.NewOSFS(dir), Workdir: "."})
+	require.NoError(t, err)
+
+	when := time.Now().Add(-time.Hour)
+	shas := make([]string, len(messages))
+	for i, message := range messages {
+		when = when.Add(time.Minute)
+		shas[i], err = src.Commit(ctx, message, Signature{Name: "Test", Email: "test@example.com", When: when}, CommitOpts{AllowEmpty: true})
+		require.NoError(t, err)
+	}
+
+	cloneFS := fsb.NewInMemoryFS()
+	repo, err := Clone(ctx, "file://"+dir, &Options{FS: cloneFS, ShallowDepth: depth})
+	require.NoError(t, err)
+	for name, i := range tags {
+		require.NoError(t, repo.CreateTag(ctx, name, shas[i], "", false))
+	}
+
+	return &testRepo{repo: repo, fs: cloneFS, ctx: ctx}, shas
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -33,6 +34,12 @@ type LogFilter struct {
 	// MaxCount limits the number of commits returned.
 	// If 0, all matching commits are returned.
 	MaxCount int
+
+	// Range selects the commits to walk using git revision range syntax.
+	// "rev" walks the commits reachable from rev, "a..b" those reachable from
+	// b but not from a, and "a...b" those reachable from either but not both.
+	// An omitted side of a range means HEAD. If empty, the log starts at HEAD.
+	Range string
 }
 
 // CommitIter represents an iterator over commits returned by Log operations.
@@ -73,6 +80,22 @@ func (ci *CommitIter) Close() {
 //
 // Context timeout/cancellation is honored during the operation.
 func (r *Repo) Log(ctx context.Context, f LogFilter) (*CommitIter, error) {
+	return r.log(ctx, plumbing.ZeroHash, f)
+}
+
+// log returns a commit iterator starting at from (HEAD if zero), or over
+// f.Range if set, with the filters applied.
+func (r *Repo) log(ctx context.Context, from plumbing.Hash, f LogFilter) (*CommitIter, error) {
+	// Resolve the range into the tips to walk and the commits to leave out
+	tips := []plumbing.Hash{from}
+	var excluded []plumbing.Hash
+	if f.Range != "" {
+		var err error
+		if tips, excluded, err = r.resolveRange(f.Range); err != nil {
+			return nil, err
+		}
+	}
+
 	// Prepare log options from the filter
 	logOpts := &git.LogOptions{}
 
@@ -106,10 +129,26 @@ func (r *Repo) Log(ctx context.Context, f LogFilter) (*CommitIter, error) {
 		logOpts.Order = git.LogOrderCommitterTime // Ensure consistent ordering
 	}
 
-	// Get the commit iterator from go-git
-	iter, err := r.repo.Log(logOpts)
-	if err != nil {
-		return nil, WrapError(err, "failed to create commit iterator")
+	// Get a commit iterator from go-git, or walk the range ourselves
+	var iter object.CommitIter
+	if len(tips) > 1 || len(excluded) > 0 {
+		walk, err := r.newRevWalk(ctx, tips, excluded)
+		if err != nil {
+			return nil, err
+		}
+		iter = walk
+		if logOpts.PathFilter != nil {
+			iter = &pathFilteredCommitIter{iter: iter, match: logOpts.PathFilter}
+		}
+		if logOpts.Since != nil || logOpts.Until != nil {
+			iter = object.NewCommitLimitIterFromIter(iter, object.LogLimitOptions{Since: logOpts.Since, Until: logOpts.Until})
+		}
+	} else {
+		logOpts.From = tips[0]
+		var err error
+		if iter, err = r.repo.Log(logOpts); err != nil {
+			return nil, WrapError(err, "failed to create commit iterator")
+		}
 	}
 
 	// Create the base iterator
@@ -242,3 +281,148 @@ func (a *authorFilteredCommitIter) ForEach(fn func(*object.Commit) error) error
 func (a *authorFilteredCommitIter) Close() {
 	a.iter.Close()
 }
+
+// pathFilteredCommitIter wraps a go-git iterator to keep the commits that
+// change a matching path compared to each of their parents, so it works on
+// walks that are not linear
+type pathFilteredCommitIter struct {
+	iter  object.CommitIter
+	match func(string) bool
+}
+
+// Next returns the next commit that changes a matching path
+func (p *pathFilteredCommitIter) Next() (*object.Commit, error) {
+	for {
+		commit, err := p.iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		ok, err := p.changesPath(commit)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return commit, nil
+		}
+	}
+}
+
+// ForEach executes the function for each commit that changes a matching path
+func (p *pathFilteredCommitIter) ForEach(fn func(*object.Commit) error) error {
+	return p.iter.ForEach(func(commit *object.Commit) error {
+		ok, err := p.changesPath(commit)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		return fn(commit)
+	})
+}
+
+// Close closes the underlying iterator
+func (p *pathFilteredCommitIter) Close() {
+	p.iter.Close()
+}
+
+// changesPath reports whether commit changes a matching path compared to
+// every parent, or adds one for a root commit.
+func (p *pathFilteredCommitIter) changesPath(commit *object.Commit) (bool, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return false, err
+	}
+
+	parents := commit.Parents()
+	defer parents.Close()
+
+	var parentTrees []*object.Tree
+	err = parents.ForEach(func(parent *object.Commit) error {
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return err
+		}
+		parentTrees = append(parentTrees, parentTree)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if len(parentTrees) == 0 {
+		parentTrees = append(parentTrees, nil)
+	}
+
+	for _, parentTree := range parentTrees {
+		changes, err := object.DiffTree(parentTree, tree)
+		if err != nil {
+			return false, err
+		}
+		changed := false
+		for _, change := range changes {
+			if p.match(change.From.Name) || p.match(change.To.Name) {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// resolveRange resolves a revision range into the tips to walk and the
+// commits whose history is left out, none for a single revision.
+func (r *Repo) resolveRange(spec string) ([]plumbing.Hash, []plumbing.Hash, error) {
+	side := func(rev string) (*object.Commit, error) {
+		if rev == "" {
+			rev = gitHead
+		}
+		return r.commitOf(rev)
+	}
+
+	if a, b, ok := strings.Cut(spec, "..."); ok {
+		ca, err := side(a)
+		if err != nil {
+			return nil, nil, err
+		}
+		cb, err := side(b)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Every common ancestor is reachable from one of the merge bases
+		bases, err := ca.MergeBase(cb)
+		if err != nil {
+			return nil, nil, WrapError(err, "failed to find merge base")
+		}
+		excluded := make([]plumbing.Hash, 0, len(bases))
+		for _, base := range bases {
+			excluded = append(excluded, base.Hash)
+		}
+
+		return []plumbing.Hash{ca.Hash, cb.Hash}, excluded, nil
+	}
+
+	if a, b, ok := strings.Cut(spec, ".."); ok {
+		ca, err := side(a)
+		if err != nil {
+			return nil, nil, err
+		}
+		cb, err := side(b)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return []plumbing.Hash{cb.Hash}, []plumbing.Hash{ca.Hash}, nil
+	}
+
+	c, err := r.commitOf(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []plumbing.Hash{c.Hash}, nil, nil
+}
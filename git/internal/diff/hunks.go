@@ -0,0 +1,155 @@
+package diff
+
+import "strings"
+
+// Op is the operation applied to a line in a diff.
+type Op int8
+
+const (
+	// Equal marks a context line present in both versions.
+	Equal Op = iota
+
+	// Add marks a line only present in the new version.
+	Add
+
+	// Delete marks a line only present in the old version.
+	Delete
+)
+
+// Chunk is a run of content with the same operation, as produced by a
+// line-based diff (e.g., go-git's diff.Chunk).
+type Chunk struct {
+	Op      Op
+	Content string
+}
+
+// Line is a single line of a hunk. OldLine and NewLine are 1-based line
+// numbers in the old and new version, or 0 when the line is absent there.
+type Line struct {
+	Op      Op
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+// Hunk is a group of changed lines with surrounding context, like a
+// unified diff "@@ -OldStart,OldLines +NewStart,NewLines @@" section.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []Line
+}
+
+// Hunks groups the lines of chunks into hunks with up to context unchanged
+// lines around each change. Changes separated by at most 2*context unchanged
+// lines share a hunk, as in git's unified diff output.
+func Hunks(chunks []Chunk, context int) []Hunk {
+	lines := numberLines(chunks)
+
+	var hunks []Hunk
+	for i := 0; i < len(lines); i++ {
+		if lines[i].Op == Equal {
+			continue
+		}
+
+		// Extend the hunk while the next change is close enough
+		start := max(0, i-context)
+		end := i
+		for j := i + 1; j < len(lines) && j-end <= 2*context+1; j++ {
+			if lines[j].Op != Equal {
+				end = j
+			}
+		}
+		stop := min(len(lines), end+context+1)
+
+		hunks = append(hunks, newHunk(lines, start, stop))
+		i = stop - 1
+	}
+
+	return hunks
+}
+
+// Count returns the number of added and deleted lines in chunks.
+func Count(chunks []Chunk) (added, deleted int) {
+	for _, chunk := range chunks {
+		n := len(splitLines(chunk.Content))
+		switch chunk.Op {
+		case Add:
+			added += n
+		case Delete:
+			deleted += n
+		case Equal:
+		}
+	}
+
+	return added, deleted
+}
+
+// newHunk builds the hunk covering lines[start:stop].
+func newHunk(lines []Line, start, stop int) Hunk {
+	h := Hunk{Lines: lines[start:stop]}
+
+	// Lines before the hunk in each version
+	oldBefore, newBefore := 0, 0
+	for _, line := range lines[:start] {
+		if line.OldLine > 0 {
+			oldBefore = line.OldLine
+		}
+		if line.NewLine > 0 {
+			newBefore = line.NewLine
+		}
+	}
+
+	for _, line := range h.Lines {
+		if line.OldLine > 0 {
+			h.OldLines++
+		}
+		if line.NewLine > 0 {
+			h.NewLines++
+		}
+	}
+
+	// An empty range starts at the line before it, as in unified diffs
+	h.OldStart, h.NewStart = oldBefore, newBefore
+	if h.OldLines > 0 {
+		h.OldStart++
+	}
+	if h.NewLines > 0 {
+		h.NewStart++
+	}
+
+	return h
+}
+
+// numberLines splits chunks into lines and assigns their line numbers.
+func numberLines(chunks []Chunk) []Line {
+	var lines []Line
+	oldLine, newLine := 0, 0
+	for _, chunk := range chunks {
+		for _, text := range splitLines(chunk.Content) {
+			line := Line{Op: chunk.Op, Text: text}
+			if chunk.Op != Add {
+				oldLine++
+				line.OldLine = oldLine
+			}
+			if chunk.Op != Delete {
+				newLine++
+				line.NewLine = newLine
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// splitLines splits content into lines without their line endings.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
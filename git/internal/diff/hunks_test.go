@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// numbered returns n lines "prefix1\n"..."prefixN\n"
+func numbered(prefix string, from, to int) string {
+	var b strings.Builder
+	for i := from; i <= to; i++ {
+		b.WriteString(prefix)
+		b.WriteString(strings.Repeat("x", i%3))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestHunks(t *testing.T) {
+	t.Run("single change with context", func(t *testing.T) {
+		hunks := Hunks([]Chunk{
+			{Equal, numbered("a", 1, 5)},
+			{Delete, "old\n"},
+			{Add, "new\nnewer\n"},
+			{Equal, numbered("b", 1, 5)},
+		}, 3)
+
+		require.Len(t, hunks, 1)
+		h := hunks[0]
+		assert.Equal(t, 3, h.OldStart)
+		assert.Equal(t, 7, h.OldLines)
+		assert.Equal(t, 3, h.NewStart)
+		assert.Equal(t, 8, h.NewLines)
+
+		require.Len(t, h.Lines, 9)
+		assert.Equal(t, Line{Op: Delete, Text: "old", OldLine: 6}, h.Lines[3])
+		assert.Equal(t, Line{Op: Add, Text: "new", NewLine: 6}, h.Lines[4])
+		assert.Equal(t, Line{Op: Add, Text: "newer", NewLine: 7}, h.Lines[5])
+		assert.Equal(t, 7, h.Lines[6].OldLine)
+		assert.Equal(t, 8, h.Lines[6].NewLine)
+	})
+
+	t.Run("distant changes are split", func(t *testing.T) {
+		hunks := Hunks([]Chunk{
+			{Add, "first\n"},
+			{Equal, numbered("a", 1, 7)},
+			{Delete, "last\n"},
+		}, 3)
+
+		require.Len(t, hunks, 2)
+		assert.Equal(t, Hunk{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 4}, withoutLines(hunks[0]))
+		assert.Equal(t, Hunk{OldStart: 5, OldLines: 4, NewStart: 6, NewLines: 3}, withoutLines(hunks[1]))
+	})
+
+	t.Run("close changes are merged", func(t *testing.T) {
+		hunks := Hunks([]Chunk{
+			{Add, "first\n"},
+			{Equal, numbered("a", 1, 6)},
+			{Delete, "last\n"},
+		}, 3)
+
+		require.Len(t, hunks, 1)
+		assert.Equal(t, Hunk{OldStart: 1, OldLines: 7, NewStart: 1, NewLines: 7}, withoutLines(hunks[0]))
+	})
+
+	t.Run("new file", func(t *testing.T) {
+		hunks := Hunks([]Chunk{{Add, "one\ntwo"}}, 3)
+
+		require.Len(t, hunks, 1)
+		assert.Equal(t, Hunk{OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 2}, withoutLines(hunks[0]))
+		assert.Equal(t, "two", hunks[0].Lines[1].Text)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		assert.Empty(t, Hunks([]Chunk{{Equal, "same\n"}}, 3))
+		assert.Empty(t, Hunks(nil, 3))
+	})
+}
+
+func TestCount(t *testing.T) {
+	added, deleted := Count([]Chunk{
+		{Equal, "a\nb\n"},
+		{Delete, "c\nd\ne\n"},
+		{Add, "f"},
+	})
+	assert.Equal(t, 1, added)
+	assert.Equal(t, 3, deleted)
+}
+
+func withoutLines(h Hunk) Hunk {
+	h.Lines = nil
+	return h
+}
@@ -1,5 +1,6 @@
 // Package diff provides utilities for analyzing diff output.
-// These utilities help detect binary files and count changed files in patch text.
+// These utilities help detect binary files and count changed files in patch text,
+// and group line changes into hunks.
 package diff
 
 import "strings"
@@ -0,0 +1,157 @@
+// Package merge provides line-based three-way merging of file contents.
+// It implements the diff3 algorithm on top of go-git's line diff.
+package merge
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// binarySniffLen is how many leading bytes are checked for NUL when detecting binary content.
+const binarySniffLen = 8000
+
+// hunk replaces the base lines [start, end) with lines.
+type hunk struct {
+	start, end int
+	lines      []string
+}
+
+// IsBinary reports whether content looks binary, using the same heuristic as git:
+// a NUL byte within the first 8000 bytes.
+func IsBinary(content []byte) bool {
+	if len(content) > binarySniffLen {
+		content = content[:binarySniffLen]
+	}
+	return bytes.IndexByte(content, 0) != -1
+}
+
+// Lines merges the changes made from base to ours and from base to theirs.
+// Changes to different regions of the file are combined. Changes that overlap
+// or touch are a conflict unless both sides made the same change, in which
+// case ok is false and merged is empty.
+func Lines(base, ours, theirs string) (merged string, ok bool) {
+	baseLines := splitLines(base)
+	oursHunks := hunks(base, ours)
+	theirsHunks := hunks(base, theirs)
+
+	var out strings.Builder
+	pos := 0
+	i, j := 0, 0
+	for i < len(oursHunks) || j < len(theirsHunks) {
+		// Start a group with the hunk that begins first
+		start := 0
+		if j >= len(theirsHunks) || (i < len(oursHunks) && oursHunks[i].start <= theirsHunks[j].start) {
+			start = oursHunks[i].start
+		} else {
+			start = theirsHunks[j].start
+		}
+
+		// Extend the group while hunks from either side overlap or touch it
+		end := start
+		oi, tj := i, j
+		for grouping := true; grouping; {
+			switch {
+			case oi < len(oursHunks) && oursHunks[oi].start <= end:
+				end = max(end, oursHunks[oi].end)
+				oi++
+			case tj < len(theirsHunks) && theirsHunks[tj].start <= end:
+				end = max(end, theirsHunks[tj].end)
+				tj++
+			default:
+				grouping = false
+			}
+		}
+
+		writeLines(&out, baseLines[pos:start])
+
+		oursText := apply(baseLines, oursHunks[i:oi], start, end)
+		theirsText := apply(baseLines, theirsHunks[j:tj], start, end)
+		switch {
+		case oi == i:
+			out.WriteString(theirsText)
+		case tj == j:
+			out.WriteString(oursText)
+		case oursText == theirsText:
+			out.WriteString(oursText)
+		default:
+			return "", false
+		}
+
+		pos = end
+		i, j = oi, tj
+	}
+	writeLines(&out, baseLines[pos:])
+
+	return out.String(), true
+}
+
+// hunks returns the changes turning base into other, ordered by base position.
+func hunks(base, other string) []hunk {
+	var result []hunk
+	var current *hunk
+	pos := 0
+
+	for _, d := range diff.Do(base, other) {
+		lines := splitLines(d.Text)
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			if current != nil {
+				result = append(result, *current)
+				current = nil
+			}
+			pos += len(lines)
+		case diffmatchpatch.DiffDelete:
+			if current == nil {
+				current = &hunk{start: pos, end: pos}
+			}
+			pos += len(lines)
+			current.end = pos
+		case diffmatchpatch.DiffInsert:
+			if current == nil {
+				current = &hunk{start: pos, end: pos}
+			}
+			current.lines = append(current.lines, lines...)
+		}
+	}
+	if current != nil {
+		result = append(result, *current)
+	}
+
+	return result
+}
+
+// apply returns the text of base lines [start, end) with the given hunks applied.
+// All hunks must lie within the range.
+func apply(base []string, hs []hunk, start, end int) string {
+	var out strings.Builder
+	pos := start
+	for _, h := range hs {
+		writeLines(&out, base[pos:h.start])
+		writeLines(&out, h.lines)
+		pos = h.end
+	}
+	writeLines(&out, base[pos:end])
+	return out.String()
+}
+
+// splitLines splits text into lines, keeping line terminators.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// writeLines writes lines to the builder.
+func writeLines(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+}
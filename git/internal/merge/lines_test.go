@@ -0,0 +1,94 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	base := "one\ntwo\nthree\nfour\nfive\n"
+
+	tests := []struct {
+		name     string
+		ours     string
+		theirs   string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "no changes",
+			ours:     base,
+			theirs:   base,
+			expected: base,
+			ok:       true,
+		},
+		{
+			name:     "only ours changed",
+			ours:     "ONE\ntwo\nthree\nfour\nfive\n",
+			theirs:   base,
+			expected: "ONE\ntwo\nthree\nfour\nfive\n",
+			ok:       true,
+		},
+		{
+			name:     "only theirs changed",
+			ours:     base,
+			theirs:   "one\ntwo\nthree\nfour\nfive\nsix\n",
+			expected: "one\ntwo\nthree\nfour\nfive\nsix\n",
+			ok:       true,
+		},
+		{
+			name:     "disjoint changes",
+			ours:     "ONE\ntwo\nthree\nfour\nfive\n",
+			theirs:   "one\ntwo\nthree\nfour\nFIVE\n",
+			expected: "ONE\ntwo\nthree\nfour\nFIVE\n",
+			ok:       true,
+		},
+		{
+			name:     "insertion and deletion",
+			ours:     "zero\none\ntwo\nthree\nfour\nfive\n",
+			theirs:   "one\ntwo\nfour\nfive\n",
+			expected: "zero\none\ntwo\nfour\nfive\n",
+			ok:       true,
+		},
+		{
+			name:     "identical changes",
+			ours:     "one\ntwo\nTHREE\nfour\nfive\n",
+			theirs:   "one\ntwo\nTHREE\nfour\nfive\n",
+			expected: "one\ntwo\nTHREE\nfour\nfive\n",
+			ok:       true,
+		},
+		{
+			name:   "conflicting changes",
+			ours:   "one\ntwo\nours\nfour\nfive\n",
+			theirs: "one\ntwo\ntheirs\nfour\nfive\n",
+			ok:     false,
+		},
+		{
+			name:   "adjacent changes conflict",
+			ours:   "one\nTWO\nthree\nfour\nfive\n",
+			theirs: "one\ntwo\nTHREE\nfour\nfive\n",
+			ok:     false,
+		},
+		{
+			name:   "insertions at the same position",
+			ours:   "one\ntwo\nthree\nfour\nfive\nours\n",
+			theirs: "one\ntwo\nthree\nfour\nfive\ntheirs\n",
+			ok:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, ok := Lines(base, tt.ours, tt.theirs)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, merged)
+		})
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	assert.False(t, IsBinary([]byte("plain text\n")))
+	assert.True(t, IsBinary([]byte{'a', 0, 'b'}))
+	assert.False(t, IsBinary(nil))
+}
@@ -0,0 +1,76 @@
+package sshsig
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func newEd25519Signer(t *testing.T) ssh.Signer {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+	return signer
+}
+
+func newRSASigner(t *testing.T) ssh.Signer {
+	t.Helper()
+
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+	return signer
+}
+
+func TestSignVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		signer func(t *testing.T) ssh.Signer
+	}{
+		{"ed25519", newEd25519Signer},
+		{"rsa", newRSASigner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := tt.signer(t)
+			message := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nmessage\n"
+
+			armored, err := Sign(signer, strings.NewReader(message), "git")
+			require.NoError(t, err)
+			assert.True(t, IsArmored(armored))
+			assert.True(t, strings.HasSuffix(string(armored), armorEnd+"\n"))
+
+			pub, err := Verify(armored, strings.NewReader(message), "git")
+			require.NoError(t, err)
+			assert.Equal(t, signer.PublicKey().Marshal(), pub.Marshal())
+		})
+	}
+}
+
+func TestVerifyFailures(t *testing.T) {
+	signer := newEd25519Signer(t)
+	armored, err := Sign(signer, strings.NewReader("original"), "git")
+	require.NoError(t, err)
+
+	_, err = Verify(armored, strings.NewReader("tampered"), "git")
+	require.Error(t, err, "modified message should not verify")
+
+	_, err = Verify(armored, strings.NewReader("original"), "file")
+	require.Error(t, err, "namespace must match")
+
+	_, err = Verify([]byte("not a signature"), strings.NewReader("original"), "git")
+	require.ErrorIs(t, err, ErrMalformed)
+
+	assert.False(t, IsArmored([]byte("-----BEGIN PGP SIGNATURE-----")))
+}
@@ -0,0 +1,762 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains three-way merge, cherry-pick and revert operations.
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/input-output-hk/catalyst-forge-libs/git/internal/merge"
+)
+
+// MergeConflict describes a path that could not be merged automatically.
+// The hashes identify the blob on each side and are empty when the path
+// does not exist on that side (e.g., modified on one side, deleted on the other).
+type MergeConflict struct {
+	// Path is the conflicting path relative to the repository root.
+	Path string
+
+	// Base is the blob hash in the common ancestor.
+	Base string
+
+	// Ours is the blob hash in the current HEAD.
+	Ours string
+
+	// Theirs is the blob hash in the commit being merged or applied.
+	Theirs string
+}
+
+// MergeConflictError is returned when a merge, cherry-pick or revert stops
+// because of conflicts. It matches ErrMergeConflict with errors.Is and its
+// details can be retrieved with errors.As.
+type MergeConflictError struct {
+	// Conflicts lists the conflicting paths, sorted by path.
+	Conflicts []MergeConflict
+}
+
+// Error implements the error interface.
+func (e *MergeConflictError) Error() string {
+	paths := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		paths[i] = c.Path
+	}
+	return fmt.Sprintf("%s in %s", ErrMergeConflict, strings.Join(paths, ", "))
+}
+
+// Unwrap returns ErrMergeConflict so the error can be checked with errors.Is.
+func (e *MergeConflictError) Unwrap() error {
+	return ErrMergeConflict
+}
+
+// MergeOpts configures Merge behavior.
+type MergeOpts struct {
+	// Message is the merge commit message.
+	// Defaults to "Merge <rev>".
+	Message string
+
+	// NoFastForward creates a merge commit even when a fast-forward is possible.
+	NoFastForward bool
+}
+
+// CherryPickOpts configures CherryPick behavior.
+type CherryPickOpts struct {
+	// AllowEmpty creates the commit even if the change is already present in HEAD.
+	// By default, ErrEmptyCommit is returned in that case.
+	AllowEmpty bool
+
+	// RecordOrigin appends "(cherry picked from commit <sha>)" to the message,
+	// like `git cherry-pick -x`.
+	RecordOrigin bool
+}
+
+// RevertOpts configures Revert behavior.
+type RevertOpts struct {
+	// AllowEmpty creates the commit even if the change is already absent from HEAD.
+	// By default, ErrEmptyCommit is returned in that case.
+	AllowEmpty bool
+
+	// Message is the revert commit message.
+	// Defaults to git's "Revert \"<subject>\"" message.
+	Message string
+}
+
+// treeFile is a non-directory tree entry.
+type treeFile struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+}
+
+// Merge merges rev into the current branch.
+// If HEAD is an ancestor of rev, the branch is fast-forwarded unless
+// NoFastForward is set. Otherwise the trees are merged against their merge
+// base, combining non-overlapping changes within text files, and a merge
+// commit with both parents is created. It returns the SHA of the new HEAD.
+//
+// Returns ErrAlreadyUpToDate if rev is already merged, ErrDirtyWorktree if the
+// working tree has uncommitted changes to tracked files, and a
+// *MergeConflictError (matching ErrMergeConflict) if the merge has conflicts.
+// HEAD, the index and the working tree are left untouched on error.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Merge(ctx context.Context, rev string, who Signature, opts MergeOpts) (string, error) {
+	if err := validateSignature(who); err != nil {
+		return "", err
+	}
+
+	head, ours, err := r.headCommit()
+	if err != nil {
+		return "", err
+	}
+
+	theirs, err := r.commitOf(rev)
+	if err != nil {
+		return "", err
+	}
+
+	merged, err := theirs.IsAncestor(ours)
+	if err != nil {
+		return "", WrapError(err, "failed to walk commit history")
+	}
+	if merged || theirs.Hash == ours.Hash {
+		return "", ErrAlreadyUpToDate
+	}
+
+	if err := r.requireCleanWorktree(); err != nil {
+		return "", err
+	}
+
+	fastForward, err := ours.IsAncestor(theirs)
+	if err != nil {
+		return "", WrapError(err, "failed to walk commit history")
+	}
+	if fastForward && !opts.NoFastForward {
+		if err := r.advanceHead(head, ours, theirs.Hash); err != nil {
+			return "", err
+		}
+		return theirs.Hash.String(), nil
+	}
+
+	bases, err := ours.MergeBase(theirs)
+	if err != nil {
+		return "", WrapError(err, "failed to find merge base")
+	}
+	if len(bases) == 0 {
+		return "", WrapErrorf(ErrResolveFailed, "no common ancestor with %q", rev)
+	}
+
+	tree, err := r.mergeCommits(ctx, bases[0], ours, theirs)
+	if err != nil {
+		return "", err
+	}
+
+	msg := opts.Message
+	if msg == "" {
+		msg = "Merge " + rev
+	}
+
+	sig := toObjectSignature(who)
+	hash, err := r.writeCommit(&object.Commit{
+		Author:       sig,
+		Committer:    sig,
+		Message:      msg,
+		TreeHash:     tree,
+		ParentHashes: []plumbing.Hash{ours.Hash, theirs.Hash},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.advanceHead(head, ours, hash); err != nil {
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+// CherryPick applies the change introduced by the commit at rev on top of HEAD
+// and commits it, keeping the original author and message. Who is recorded as
+// the committer. It returns the SHA of the new commit.
+//
+// Returns ErrInvalidRef for merge commits, ErrEmptyCommit if the change is
+// already present and AllowEmpty is false, ErrDirtyWorktree if the working tree
+// has uncommitted changes to tracked files, and a *MergeConflictError (matching
+// ErrMergeConflict) if the change does not apply cleanly.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) CherryPick(ctx context.Context, rev string, who Signature, opts CherryPickOpts) (string, error) {
+	if err := validateSignature(who); err != nil {
+		return "", err
+	}
+
+	commit, parent, err := r.singleParentCommit(rev)
+	if err != nil {
+		return "", err
+	}
+
+	msg := commit.Message
+	if opts.RecordOrigin {
+		msg = strings.TrimRight(msg, "\n") + "\n\n(cherry picked from commit " + commit.Hash.String() + ")\n"
+	}
+
+	author := commit.Author
+	return r.applyCommit(ctx, parent, commit, &author, who, msg, opts.AllowEmpty)
+}
+
+// Revert creates a commit on top of HEAD that undoes the change introduced by
+// the commit at rev. Who is recorded as both author and committer. It returns
+// the SHA of the new commit.
+//
+// Returns ErrInvalidRef for merge commits, ErrEmptyCommit if the change is
+// already absent and AllowEmpty is false, ErrDirtyWorktree if the working tree
+// has uncommitted changes to tracked files, and a *MergeConflictError (matching
+// ErrMergeConflict) if the change cannot be undone cleanly.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Revert(ctx context.Context, rev string, who Signature, opts RevertOpts) (string, error) {
+	if err := validateSignature(who); err != nil {
+		return "", err
+	}
+
+	commit, parent, err := r.singleParentCommit(rev)
+	if err != nil {
+		return "", err
+	}
+
+	msg := opts.Message
+	if msg == "" {
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		msg = fmt.Sprintf("Revert \"%s\"\n\nThis reverts commit %s.\n", subject, commit.Hash)
+	}
+
+	return r.applyCommit(ctx, commit, parent, nil, who, msg, opts.AllowEmpty)
+}
+
+// applyCommit applies the difference between from and to on top of HEAD and
+// commits the result. A nil commit stands for the empty tree. If author is nil, who
+// is used as the author.
+func (r *Repo) applyCommit(
+	ctx context.Context,
+	from, to *object.Commit,
+	author *object.Signature,
+	who Signature,
+	msg string,
+	allowEmpty bool,
+) (string, error) {
+	head, ours, err := r.headCommit()
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.requireCleanWorktree(); err != nil {
+		return "", err
+	}
+
+	tree, err := r.mergeCommits(ctx, from, ours, to)
+	if err != nil {
+		return "", err
+	}
+
+	if tree == ours.TreeHash && !allowEmpty {
+		return "", ErrEmptyCommit
+	}
+
+	committer := toObjectSignature(who)
+	if author == nil {
+		author = &committer
+	}
+
+	hash, err := r.writeCommit(&object.Commit{
+		Author:       *author,
+		Committer:    committer,
+		Message:      msg,
+		TreeHash:     tree,
+		ParentHashes: []plumbing.Hash{ours.Hash},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.advanceHead(head, ours, hash); err != nil {
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+// mergeCommits merges the trees of ours and theirs against base and writes the
+// resulting tree. A nil commit stands for the empty tree.
+func (r *Repo) mergeCommits(ctx context.Context, base, ours, theirs *object.Commit) (plumbing.Hash, error) {
+	baseFiles, err := r.commitFiles(base)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	oursFiles, err := r.commitFiles(ours)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	theirsFiles, err := r.commitFiles(theirs)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	paths := make(map[string]struct{}, len(oursFiles))
+	for _, files := range []map[string]treeFile{baseFiles, oursFiles, theirsFiles} {
+		for p := range files {
+			paths[p] = struct{}{}
+		}
+	}
+
+	result := make(map[string]treeFile, len(paths))
+	var conflicts []MergeConflict
+	for p := range paths {
+		if err := ctx.Err(); err != nil {
+			return plumbing.ZeroHash, WrapError(err, "context cancelled")
+		}
+
+		b, hasBase := baseFiles[p]
+		o, hasOurs := oursFiles[p]
+		t, hasTheirs := theirsFiles[p]
+
+		file, ok, err := r.mergeFile(
+			sideOf(b, hasBase), sideOf(o, hasOurs), sideOf(t, hasTheirs),
+		)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if !ok {
+			conflicts = append(conflicts, MergeConflict{
+				Path:   p,
+				Base:   hashOf(b, hasBase),
+				Ours:   hashOf(o, hasOurs),
+				Theirs: hashOf(t, hasTheirs),
+			})
+			continue
+		}
+		if file != nil {
+			result[p] = *file
+		}
+	}
+
+	// A file on one side and a directory on the other cannot both be kept
+	reported := make(map[string]bool)
+	for p := range result {
+		for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+			if _, ok := result[dir]; !ok || reported[dir] {
+				continue
+			}
+			reported[dir] = true
+			b, hasBase := baseFiles[dir]
+			o, hasOurs := oursFiles[dir]
+			t, hasTheirs := theirsFiles[dir]
+			conflicts = append(conflicts, MergeConflict{
+				Path:   dir,
+				Base:   hashOf(b, hasBase),
+				Ours:   hashOf(o, hasOurs),
+				Theirs: hashOf(t, hasTheirs),
+			})
+		}
+	}
+
+	if len(conflicts) > 0 {
+		sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+		return plumbing.ZeroHash, &MergeConflictError{Conflicts: conflicts}
+	}
+
+	return r.writeTree(result)
+}
+
+// mergeFile merges a single path. Nil sides mean the path does not exist.
+// It returns the merged entry (nil if the path is deleted) and whether the
+// merge succeeded.
+func (r *Repo) mergeFile(base, ours, theirs *treeFile) (*treeFile, bool, error) {
+	switch {
+	case sameFile(ours, theirs):
+		return ours, true, nil
+	case sameFile(base, ours):
+		return theirs, true, nil
+	case sameFile(base, theirs):
+		return ours, true, nil
+	case base == nil || ours == nil || theirs == nil:
+		// Added differently on both sides, or modified on one and deleted on the other
+		return nil, false, nil
+	}
+
+	mode, ok := mergeValue(base.mode, ours.mode, theirs.mode)
+	if !ok {
+		return nil, false, nil
+	}
+
+	hash, ok := mergeValue(base.hash, ours.hash, theirs.hash)
+	if ok {
+		return &treeFile{hash: hash, mode: mode}, true, nil
+	}
+
+	if !mode.IsFile() {
+		// Submodules and symlinks cannot be merged by content
+		return nil, false, nil
+	}
+
+	hash, ok, err := r.mergeBlobs(base.hash, ours.hash, theirs.hash)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	return &treeFile{hash: hash, mode: mode}, true, nil
+}
+
+// mergeBlobs performs a line-based three-way merge of text blobs and writes
+// the result. Binary content is never merged.
+func (r *Repo) mergeBlobs(base, ours, theirs plumbing.Hash) (plumbing.Hash, bool, error) {
+	contents := make([]string, 3)
+	for i, hash := range []plumbing.Hash{base, ours, theirs} {
+		data, err := r.readBlob(hash)
+		if err != nil {
+			return plumbing.ZeroHash, false, err
+		}
+		if merge.IsBinary(data) {
+			return plumbing.ZeroHash, false, nil
+		}
+		contents[i] = string(data)
+	}
+
+	merged, ok := merge.Lines(contents[0], contents[1], contents[2])
+	if !ok {
+		return plumbing.ZeroHash, false, nil
+	}
+
+	hash, err := r.writeBlob([]byte(merged))
+	if err != nil {
+		return plumbing.ZeroHash, false, err
+	}
+
+	return hash, true, nil
+}
+
+// commitFiles returns every non-directory entry of the commit's tree keyed by
+// path. A nil commit has no files.
+func (r *Repo) commitFiles(c *object.Commit) (map[string]treeFile, error) {
+	files := make(map[string]treeFile)
+	if c == nil {
+		return files, nil
+	}
+
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, WrapError(err, "failed to get commit tree")
+	}
+
+	return treeFiles(tree)
+}
+
+// treeFiles returns every non-directory entry of the tree keyed by path.
+func treeFiles(tree *object.Tree) (map[string]treeFile, error) {
+	files := make(map[string]treeFile)
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, WrapError(err, "failed to walk commit tree")
+		}
+		if entry.Mode == filemode.Dir {
+			continue
+		}
+		files[name] = treeFile{hash: entry.Hash, mode: entry.Mode}
+	}
+
+	return files, nil
+}
+
+// readBlob returns the content of a blob.
+func (r *Repo) readBlob(hash plumbing.Hash) ([]byte, error) {
+	blob, err := r.repo.BlobObject(hash)
+	if err != nil {
+		return nil, WrapError(err, "failed to get blob")
+	}
+
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, WrapError(err, "failed to read blob")
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, WrapError(err, "failed to read blob")
+	}
+
+	return data, nil
+}
+
+// writeBlob stores content as a blob object.
+func (r *Repo) writeBlob(content []byte) (plumbing.Hash, error) {
+	obj := r.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, WrapError(err, "failed to write blob")
+	}
+	if _, err := w.Write(content); err != nil {
+		_ = w.Close()
+		return plumbing.ZeroHash, WrapError(err, "failed to write blob")
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, WrapError(err, "failed to write blob")
+	}
+
+	hash, err := r.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, WrapError(err, "failed to store blob")
+	}
+
+	return hash, nil
+}
+
+// writeTree stores the nested tree objects for a set of files and returns
+// the hash of the root tree.
+func (r *Repo) writeTree(files map[string]treeFile) (plumbing.Hash, error) {
+	var entries []object.TreeEntry
+	subdirs := make(map[string]map[string]treeFile)
+
+	for p, f := range files {
+		dir, rest, nested := strings.Cut(p, "/")
+		if !nested {
+			entries = append(entries, object.TreeEntry{Name: p, Mode: f.mode, Hash: f.hash})
+			continue
+		}
+		if subdirs[dir] == nil {
+			subdirs[dir] = make(map[string]treeFile)
+		}
+		subdirs[dir][rest] = f
+	}
+
+	for dir, sub := range subdirs {
+		hash, err := r.writeTree(sub)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries = append(entries, object.TreeEntry{Name: dir, Mode: filemode.Dir, Hash: hash})
+	}
+
+	sort.Sort(object.TreeEntrySorter(entries))
+
+	obj := r.repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, WrapError(err, "failed to encode tree")
+	}
+
+	hash, err := r.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, WrapError(err, "failed to store tree")
+	}
+
+	return hash, nil
+}
+
+// writeCommit stores a commit object.
+func (r *Repo) writeCommit(c *object.Commit) (plumbing.Hash, error) {
+	obj := r.repo.Storer.NewEncodedObject()
+	if err := c.Encode(obj); err != nil {
+		return plumbing.ZeroHash, WrapError(err, "failed to encode commit")
+	}
+
+	hash, err := r.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, WrapError(err, "failed to store commit")
+	}
+
+	return hash, nil
+}
+
+// headCommit returns the resolved HEAD reference and its commit.
+func (r *Repo) headCommit() (*plumbing.Reference, *object.Commit, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return nil, nil, WrapError(ErrResolveFailed, "failed to get HEAD reference")
+	}
+
+	commit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, nil, WrapError(err, "failed to get HEAD commit")
+	}
+
+	return head, commit, nil
+}
+
+// commitOf resolves a revision to a commit.
+func (r *Repo) commitOf(rev string) (*object.Commit, error) {
+	if rev == "" {
+		return nil, WrapError(ErrInvalidRef, "revision cannot be empty")
+	}
+
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, WrapErrorf(ErrResolveFailed, "failed to resolve revision %q", rev)
+	}
+
+	commit, err := r.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, WrapErrorf(ErrResolveFailed, "revision %q is not a commit", rev)
+	}
+
+	return commit, nil
+}
+
+// singleParentCommit resolves rev to a commit and its parent, which is nil
+// for root commits. Merge commits are rejected.
+func (r *Repo) singleParentCommit(rev string) (*object.Commit, *object.Commit, error) {
+	commit, err := r.commitOf(rev)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch commit.NumParents() {
+	case 0:
+		return commit, nil, nil
+	case 1:
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, nil, WrapError(err, "failed to get parent commit")
+		}
+		return commit, parent, nil
+	default:
+		return nil, nil, WrapErrorf(ErrInvalidRef, "commit %s is a merge commit", commit.Hash)
+	}
+}
+
+// requireCleanWorktree returns ErrDirtyWorktree if tracked files have staged
+// or unstaged changes. Untracked files are allowed. Bare repositories are
+// always clean.
+func (r *Repo) requireCleanWorktree() error {
+	if r.worktree == nil {
+		return nil
+	}
+
+	status, err := r.worktree.Status()
+	if err != nil {
+		return WrapError(err, "failed to get worktree status")
+	}
+
+	for p, s := range status {
+		if s.Worktree == git.Untracked {
+			continue
+		}
+		if s.Staging != git.Unmodified || s.Worktree != git.Unmodified {
+			return WrapErrorf(ErrDirtyWorktree, "uncommitted changes in %q", p)
+		}
+	}
+
+	return nil
+}
+
+// advanceHead moves HEAD (or the branch it points to) from ours to hash. When
+// the repository has a worktree, the files that changed are updated in the
+// index and working tree; untracked files in the way are reported as
+// ErrDirtyWorktree before anything is written.
+func (r *Repo) advanceHead(head *plumbing.Reference, ours *object.Commit, hash plumbing.Hash) error {
+	if r.worktree != nil {
+		target, err := r.repo.CommitObject(hash)
+		if err != nil {
+			return WrapError(err, "failed to get target commit")
+		}
+		oursFiles, err := r.commitFiles(ours)
+		if err != nil {
+			return err
+		}
+		targetFiles, err := r.commitFiles(target)
+		if err != nil {
+			return err
+		}
+
+		if err := r.checkUntrackedFiles(oursFiles, targetFiles); err != nil {
+			return err
+		}
+		if err := r.checkoutFiles(oursFiles, targetFiles); err != nil {
+			return err
+		}
+
+		stage := make(map[string]*treeFile)
+		for _, p := range changedFiles(oursFiles, targetFiles) {
+			f, ok := targetFiles[p]
+			stage[p] = sideOf(f, ok)
+		}
+		if err := r.stageFiles(stage); err != nil {
+			return err
+		}
+	}
+
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(head.Name(), hash)); err != nil {
+		return WrapError(err, "failed to update HEAD")
+	}
+
+	return nil
+}
+
+// validateSignature checks that a signature has a name and email.
+func validateSignature(who Signature) error {
+	if who.Name == "" || who.Email == "" {
+		return WrapError(ErrInvalidRef, "committer name and email are required")
+	}
+	return nil
+}
+
+// toObjectSignature converts a Signature to a go-git signature.
+func toObjectSignature(who Signature) object.Signature {
+	return object.Signature{Name: who.Name, Email: who.Email, When: who.When}
+}
+
+// sideOf returns a pointer to f if present, nil otherwise.
+func sideOf(f treeFile, present bool) *treeFile {
+	if !present {
+		return nil
+	}
+	return &f
+}
+
+// hashOf returns the hash of f as a string if present, empty otherwise.
+func hashOf(f treeFile, present bool) string {
+	if !present {
+		return ""
+	}
+	return f.hash.String()
+}
+
+// sameFile reports whether two optional entries are identical.
+func sameFile(a, b *treeFile) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// mergeValue performs a trivial three-way merge of a single value.
+func mergeValue[T comparable](base, ours, theirs T) (T, bool) {
+	switch {
+	case ours == theirs:
+		return ours, true
+	case base == ours:
+		return theirs, true
+	case base == theirs:
+		return ours, true
+	default:
+		var zero T
+		return zero, false
+	}
+}
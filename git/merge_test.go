@@ -0,0 +1,357 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mergeBaseContent = "one\ntwo\nthree\nfour\nfive\n"
+
+var mergeSig = Signature{Name: "Merge Bot", Email: "bot@example.com", When: time.Now()}
+
+// setupDivergedRepo creates a repository where master and feature branch from
+// a commit of file.txt and each commit the given content (empty means no commit).
+func setupDivergedRepo(t *testing.T, masterContent, featureContent string) *testRepo {
+	t.Helper()
+
+	tr := setupTestRepoWithCommit(t)
+	tr.commitFile(t, "file.txt", mergeBaseContent)
+	tr.createTestBranch(t, "feature")
+
+	if masterContent != "" {
+		tr.commitFile(t, "file.txt", masterContent)
+	}
+
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "feature", false, false))
+	if featureContent != "" {
+		tr.commitFile(t, "file.txt", featureContent)
+	}
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "master", false, false))
+
+	return tr
+}
+
+// readWorktreeFile returns the content of a file in the working tree
+func (tr *testRepo) readWorktreeFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := tr.fs.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// TestMerge tests three-way merges that combine changes from both sides
+func TestMerge(t *testing.T) {
+	tr := setupDivergedRepo(t,
+		"one\ntwo\nthree\nfour\nFIVE\n",
+		"ONE\ntwo\nthree\nfour\nfive\n",
+	)
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "feature", false, false))
+	tr.commitFile(t, "docs/feature.md", "feature docs\n")
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "master", false, false))
+
+	head, err := tr.repo.repo.Head()
+	require.NoError(t, err)
+	feature, err := tr.repo.repo.Reference(plumbing.NewBranchReferenceName("feature"), true)
+	require.NoError(t, err)
+
+	sha, err := tr.repo.Merge(tr.ctx, "feature", mergeSig, MergeOpts{})
+	require.NoError(t, err)
+
+	commit, err := tr.repo.repo.CommitObject(plumbing.NewHash(sha))
+	require.NoError(t, err)
+	assert.Equal(t, []plumbing.Hash{head.Hash(), feature.Hash()}, commit.ParentHashes)
+	assert.Equal(t, "Merge feature", commit.Message)
+	assert.Equal(t, "Merge Bot", commit.Author.Name)
+
+	assert.Equal(t, "ONE\ntwo\nthree\nfour\nFIVE\n", tr.readWorktreeFile(t, "file.txt"))
+	assert.Equal(t, "feature docs\n", tr.readWorktreeFile(t, "docs/feature.md"))
+
+	clean, err := tr.repo.IsClean(tr.ctx)
+	require.NoError(t, err)
+	assert.True(t, clean, "merge should leave a clean working tree")
+	assert.Equal(t, "master", tr.getCurrentBranch(t))
+
+	_, err = tr.repo.Merge(tr.ctx, "feature", mergeSig, MergeOpts{})
+	require.ErrorIs(t, err, ErrAlreadyUpToDate)
+}
+
+// TestMergeFastForward tests fast-forward handling
+func TestMergeFastForward(t *testing.T) {
+	tests := []struct {
+		name          string
+		noFastForward bool
+		parents       int
+	}{
+		{"fast-forward", false, 1},
+		{"no fast-forward", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := setupDivergedRepo(t, "", "one\ntwo\nthree\nfour\nfive\nsix\n")
+			feature, err := tr.repo.repo.Reference(plumbing.NewBranchReferenceName("feature"), true)
+			require.NoError(t, err)
+
+			sha, err := tr.repo.Merge(tr.ctx, "feature", mergeSig, MergeOpts{
+				NoFastForward: tt.noFastForward,
+				Message:       "Merge feature into master",
+			})
+			require.NoError(t, err)
+
+			commit, err := tr.repo.repo.CommitObject(plumbing.NewHash(sha))
+			require.NoError(t, err)
+			assert.Equal(t, tt.parents, commit.NumParents())
+			if !tt.noFastForward {
+				assert.Equal(t, feature.Hash().String(), sha)
+			}
+			assert.Equal(t, "one\ntwo\nthree\nfour\nfive\nsix\n", tr.readWorktreeFile(t, "file.txt"))
+		})
+	}
+}
+
+// TestMergeConflicts tests structured conflict reporting
+func TestMergeConflicts(t *testing.T) {
+	tr := setupDivergedRepo(t,
+		"one\ntwo\nmaster\nfour\nfive\n",
+		"one\ntwo\nfeature\nfour\nfive\n",
+	)
+
+	// Also delete a file on feature that master modified
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "feature", false, false))
+	require.NoError(t, tr.repo.Remove(tr.ctx, "test.txt"))
+	_, err := tr.repo.Commit(tr.ctx, "remove test.txt", mergeSig, CommitOpts{})
+	require.NoError(t, err)
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "master", false, false))
+	tr.commitFile(t, "test.txt", "changed on master")
+
+	before, err := tr.repo.repo.Head()
+	require.NoError(t, err)
+
+	_, err = tr.repo.Merge(tr.ctx, "feature", mergeSig, MergeOpts{})
+	require.ErrorIs(t, err, ErrMergeConflict)
+
+	var conflictErr *MergeConflictError
+	require.True(t, errors.As(err, &conflictErr))
+	require.Len(t, conflictErr.Conflicts, 2)
+
+	fileConflict := conflictErr.Conflicts[0]
+	assert.Equal(t, "file.txt", fileConflict.Path)
+	assert.NotEmpty(t, fileConflict.Base)
+	assert.NotEmpty(t, fileConflict.Ours)
+	assert.NotEmpty(t, fileConflict.Theirs)
+	assert.NotEqual(t, fileConflict.Ours, fileConflict.Theirs)
+
+	deleteConflict := conflictErr.Conflicts[1]
+	assert.Equal(t, "test.txt", deleteConflict.Path)
+	assert.NotEmpty(t, deleteConflict.Ours)
+	assert.Empty(t, deleteConflict.Theirs, "deleted side has no blob")
+	assert.Contains(t, err.Error(), "file.txt, test.txt")
+
+	// Nothing changes on conflict
+	after, err := tr.repo.repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, before.Hash(), after.Hash())
+	assert.Equal(t, "one\ntwo\nmaster\nfour\nfive\n", tr.readWorktreeFile(t, "file.txt"))
+}
+
+// TestMergeDirtyWorktree tests that merges refuse to overwrite local changes
+func TestMergeDirtyWorktree(t *testing.T) {
+	tr := setupDivergedRepo(t, "", "ONE\ntwo\nthree\nfour\nfive\n")
+	tr.modifyTestFile(t, "uncommitted")
+
+	_, err := tr.repo.Merge(tr.ctx, "feature", mergeSig, MergeOpts{})
+	require.ErrorIs(t, err, ErrDirtyWorktree)
+
+	_, err = tr.repo.CherryPick(tr.ctx, "feature", mergeSig, CherryPickOpts{})
+	require.ErrorIs(t, err, ErrDirtyWorktree)
+}
+
+// TestMergeFileDirectoryConflict tests a file on one side colliding with a directory on the other
+func TestMergeFileDirectoryConflict(t *testing.T) {
+	tr := setupDivergedRepo(t, "", "")
+	aFile := tr.commitFile(t, "a", "file")
+
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "feature", false, false))
+	require.NoError(t, tr.fs.MkdirAll("a", 0o755))
+	tr.commitFile(t, "a/b", "nested")
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "master", false, false))
+
+	_, err := tr.repo.Merge(tr.ctx, "feature", mergeSig, MergeOpts{})
+	var conflictErr *MergeConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	require.Len(t, conflictErr.Conflicts, 1)
+	assert.Equal(t, "a", conflictErr.Conflicts[0].Path)
+	assert.Empty(t, conflictErr.Conflicts[0].Base)
+	assert.Empty(t, conflictErr.Conflicts[0].Theirs)
+
+	head, err := tr.repo.repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, aFile, head.Hash().String())
+}
+
+// TestMergeUntrackedFiles tests that merges never overwrite or delete untracked files
+func TestMergeUntrackedFiles(t *testing.T) {
+	for _, noFastForward := range []bool{false, true} {
+		t.Run(fmt.Sprintf("no-ff=%v", noFastForward), func(t *testing.T) {
+			tr := setupDivergedRepo(t, "", "")
+			require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "feature", false, false))
+			tr.commitFile(t, "new.txt", "from feature")
+			require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "master", false, false))
+
+			require.NoError(t, tr.fs.WriteFile("new.txt", []byte("untracked"), 0o644))
+			require.NoError(t, tr.fs.WriteFile("scratch.txt", []byte("scratch"), 0o644))
+			before, err := tr.repo.repo.Head()
+			require.NoError(t, err)
+
+			_, err = tr.repo.Merge(tr.ctx, "feature", mergeSig, MergeOpts{NoFastForward: noFastForward})
+			require.ErrorIs(t, err, ErrDirtyWorktree)
+			assert.Equal(t, "untracked", tr.readFile(t, "new.txt"))
+			after, err := tr.repo.repo.Head()
+			require.NoError(t, err)
+			assert.Equal(t, before.Hash(), after.Hash())
+
+			// Unrelated untracked files are kept
+			require.NoError(t, tr.fs.Remove("new.txt"))
+			_, err = tr.repo.Merge(tr.ctx, "feature", mergeSig, MergeOpts{NoFastForward: noFastForward})
+			require.NoError(t, err)
+			assert.Equal(t, "from feature", tr.readFile(t, "new.txt"))
+			assert.Equal(t, []string{"?? scratch.txt"}, tr.porcelain(t))
+		})
+	}
+}
+
+// TestCherryPick tests applying a single commit onto another branch
+func TestCherryPick(t *testing.T) {
+	tr := setupDivergedRepo(t, "one\ntwo\nthree\nfour\nFIVE\n", "")
+
+	// A fix on feature, authored by someone else
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "feature", false, false))
+	require.NoError(t, tr.fs.WriteFile("file.txt", []byte("one\nTWO\nthree\nfour\nfive\n"), 0o644))
+	require.NoError(t, tr.repo.Add(tr.ctx, "file.txt"))
+	fix, err := tr.repo.Commit(tr.ctx, "fix: correct line two\n", Signature{
+		Name: "Original Author", Email: "author@example.com", When: time.Now(),
+	}, CommitOpts{})
+	require.NoError(t, err)
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "master", false, false))
+
+	sha, err := tr.repo.CherryPick(tr.ctx, fix, mergeSig, CherryPickOpts{RecordOrigin: true})
+	require.NoError(t, err)
+
+	commit, err := tr.repo.repo.CommitObject(plumbing.NewHash(sha))
+	require.NoError(t, err)
+	assert.Equal(t, "Original Author", commit.Author.Name)
+	assert.Equal(t, "Merge Bot", commit.Committer.Name)
+	assert.Equal(t, "fix: correct line two\n\n(cherry picked from commit "+fix+")\n", commit.Message)
+	assert.Equal(t, 1, commit.NumParents())
+	assert.Equal(t, "one\nTWO\nthree\nfour\nFIVE\n", tr.readWorktreeFile(t, "file.txt"))
+
+	// Picking the same change again is empty
+	_, err = tr.repo.CherryPick(tr.ctx, fix, mergeSig, CherryPickOpts{})
+	require.ErrorIs(t, err, ErrEmptyCommit)
+
+	_, err = tr.repo.CherryPick(tr.ctx, fix, mergeSig, CherryPickOpts{AllowEmpty: true})
+	require.NoError(t, err)
+}
+
+// TestCherryPickConflict tests that conflicting picks report conflicts
+func TestCherryPickConflict(t *testing.T) {
+	tr := setupDivergedRepo(t,
+		"one\ntwo\nmaster\nfour\nfive\n",
+		"one\ntwo\nfeature\nfour\nfive\n",
+	)
+
+	_, err := tr.repo.CherryPick(tr.ctx, "feature", mergeSig, CherryPickOpts{})
+	var conflictErr *MergeConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	require.Len(t, conflictErr.Conflicts, 1)
+	assert.Equal(t, "file.txt", conflictErr.Conflicts[0].Path)
+}
+
+// TestCherryPickMergeCommit tests that merge commits are rejected
+func TestCherryPickMergeCommit(t *testing.T) {
+	tr := setupDivergedRepo(t, "one\ntwo\nthree\nfour\nFIVE\n", "ONE\ntwo\nthree\nfour\nfive\n")
+
+	sha, err := tr.repo.Merge(tr.ctx, "feature", mergeSig, MergeOpts{})
+	require.NoError(t, err)
+
+	_, err = tr.repo.CherryPick(tr.ctx, sha, mergeSig, CherryPickOpts{})
+	require.ErrorIs(t, err, ErrInvalidRef)
+
+	_, err = tr.repo.Revert(tr.ctx, sha, mergeSig, RevertOpts{})
+	require.ErrorIs(t, err, ErrInvalidRef)
+}
+
+// TestRevert tests undoing an earlier commit
+func TestRevert(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.commitFile(t, "file.txt", mergeBaseContent)
+	bad := tr.commitFile(t, "file.txt", "ONE\ntwo\nthree\nfour\nfive\n")
+	tr.commitFile(t, "file.txt", "ONE\ntwo\nthree\nfour\nFIVE\n")
+
+	sha, err := tr.repo.Revert(tr.ctx, bad, mergeSig, RevertOpts{})
+	require.NoError(t, err)
+
+	commit, err := tr.repo.repo.CommitObject(plumbing.NewHash(sha))
+	require.NoError(t, err)
+	assert.Equal(t, "Revert \"update file.txt\"\n\nThis reverts commit "+bad+".\n", commit.Message)
+	assert.Equal(t, "Merge Bot", commit.Author.Name)
+	assert.Equal(t, "one\ntwo\nthree\nfour\nFIVE\n", tr.readWorktreeFile(t, "file.txt"))
+
+	// Reverting again finds nothing left to undo
+	_, err = tr.repo.Revert(tr.ctx, bad, mergeSig, RevertOpts{})
+	require.ErrorIs(t, err, ErrEmptyCommit)
+}
+
+// TestMergeBareRepository tests that merges in bare repositories only move refs
+func TestMergeBareRepository(t *testing.T) {
+	tr := setupDivergedRepo(t, "one\ntwo\nthree\nfour\nFIVE\n", "ONE\ntwo\nthree\nfour\nfive\n")
+	bare := &Repo{repo: tr.repo.repo, fs: tr.repo.fs, options: tr.repo.options}
+
+	sha, err := bare.Merge(tr.ctx, "feature", mergeSig, MergeOpts{})
+	require.NoError(t, err)
+
+	master, err := tr.repo.repo.Reference(plumbing.NewBranchReferenceName("master"), true)
+	require.NoError(t, err)
+	assert.Equal(t, sha, master.Hash().String())
+
+	commit, err := tr.repo.repo.CommitObject(master.Hash())
+	require.NoError(t, err)
+	file, err := commit.File("file.txt")
+	require.NoError(t, err)
+	content, err := file.Contents()
+	require.NoError(t, err)
+	assert.Equal(t, "ONE\ntwo\nthree\nfour\nFIVE\n", content)
+}
+
+// TestMergeWithConfigIdentity tests the ThreeWay strategy used by FetchAndMerge
+func TestMergeWithConfigIdentity(t *testing.T) {
+	tr := setupDivergedRepo(t, "one\ntwo\nthree\nfour\nFIVE\n", "ONE\ntwo\nthree\nfour\nfive\n")
+
+	err := tr.repo.mergeWithConfigIdentity(tr.ctx, "feature")
+	require.ErrorIs(t, err, ErrInvalidRef, "identity must be configured")
+
+	cfg, err := tr.repo.repo.Config()
+	require.NoError(t, err)
+	cfg.User.Name = "Config User"
+	cfg.User.Email = "config@example.com"
+	require.NoError(t, tr.repo.repo.SetConfig(cfg))
+
+	require.NoError(t, tr.repo.mergeWithConfigIdentity(tr.ctx, "feature"))
+	head, err := tr.repo.repo.Head()
+	require.NoError(t, err)
+	commit, err := tr.repo.repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "Config User", commit.Author.Name)
+	assert.True(t, strings.HasPrefix(commit.Message, "Merge feature"))
+
+	// Already merged is not an error
+	require.NoError(t, tr.repo.mergeWithConfigIdentity(tr.ctx, "feature"))
+}
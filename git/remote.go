@@ -0,0 +1,436 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains remote management operations.
+package git
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
+)
+
+// Config section and key names used when editing the raw config.
+const (
+	remoteSectionName = "remote"
+	branchSectionName = "branch"
+	remoteURLKey      = "url"
+	remoteFetchKey    = "fetch"
+	remotePushURLKey  = "pushurl"
+	remotePushKey     = "push"
+	remoteMirrorKey   = "mirror"
+)
+
+// Remote describes a configured remote repository.
+type Remote struct {
+	// Name is the name of the remote (e.g., "origin").
+	Name string
+
+	// URL is the fetch URL (remote.<name>.url).
+	URL string
+
+	// PushURLs are the push URLs (remote.<name>.pushurl).
+	// If empty, pushes go to URL. Pushes go to every URL when several are set.
+	PushURLs []string
+
+	// Fetch lists the fetch refspecs (remote.<name>.fetch).
+	// AddRemote defaults it to "+refs/heads/*:refs/remotes/<name>/*".
+	Fetch []string
+
+	// Push lists the push refspecs (remote.<name>.push). If empty, Push
+	// pushes the current branch to the branch of the same name.
+	Push []string
+
+	// Mirror marks the remote as a mirror (remote.<name>.mirror).
+	Mirror bool
+}
+
+// Remotes returns all configured remotes, sorted by name.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Remotes(ctx context.Context) ([]Remote, error) {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return nil, WrapError(err, "failed to read repository config")
+	}
+
+	remotes := make([]Remote, 0, len(cfg.Remotes))
+	for _, rc := range cfg.Remotes {
+		remotes = append(remotes, remoteFromConfig(cfg, rc))
+	}
+
+	sort.Slice(remotes, func(i, j int) bool { return remotes[i].Name < remotes[j].Name })
+
+	return remotes, nil
+}
+
+// Remote returns the configuration of a single remote.
+// Returns ErrRemoteMissing if the remote does not exist.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Remote(ctx context.Context, name string) (*Remote, error) {
+	remote, err := r.lookupRemote(name)
+	if err != nil {
+		return nil, err
+	}
+	if remote == nil {
+		return nil, WrapErrorf(ErrRemoteMissing, "remote %q", name)
+	}
+
+	return remote, nil
+}
+
+// AddRemote adds a new remote and persists it to the repository config.
+// Returns ErrRemoteExists if a remote with the same name exists.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) AddRemote(ctx context.Context, remote Remote) error {
+	if err := validateRemote(remote); err != nil {
+		return err
+	}
+
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return WrapError(err, "failed to read repository config")
+	}
+
+	if _, ok := cfg.Remotes[remote.Name]; ok {
+		return WrapErrorf(ErrRemoteExists, "remote %q", remote.Name)
+	}
+
+	if len(remote.Fetch) == 0 {
+		remote.Fetch = []string{defaultFetchRefSpec(remote.Name)}
+	}
+
+	return r.writeRemote(cfg, remote)
+}
+
+// UpdateRemote replaces the configuration of an existing remote.
+// Options that Remote does not model (e.g., tagOpt) are preserved.
+// Returns ErrRemoteMissing if the remote does not exist.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) UpdateRemote(ctx context.Context, remote Remote) error {
+	if err := validateRemote(remote); err != nil {
+		return err
+	}
+
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return WrapError(err, "failed to read repository config")
+	}
+
+	if _, ok := cfg.Remotes[remote.Name]; !ok {
+		return WrapErrorf(ErrRemoteMissing, "remote %q", remote.Name)
+	}
+
+	return r.writeRemote(cfg, remote)
+}
+
+// SetRemoteURL changes the fetch URL of a remote, keeping its push URLs.
+// Returns ErrRemoteMissing if the remote does not exist.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) SetRemoteURL(ctx context.Context, name, url string) error {
+	remote, err := r.Remote(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	remote.URL = url
+	return r.UpdateRemote(ctx, *remote)
+}
+
+// RemoveRemote removes a remote, its remote-tracking branches and the
+// upstream configuration of branches that track it.
+// Returns ErrRemoteMissing if the remote does not exist.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) RemoveRemote(ctx context.Context, name string) error {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return WrapError(err, "failed to read repository config")
+	}
+
+	if _, ok := cfg.Remotes[name]; !ok {
+		return WrapErrorf(ErrRemoteMissing, "remote %q", name)
+	}
+
+	delete(cfg.Remotes, name)
+	cfg.Raw.Section(remoteSectionName).RemoveSubsection(name)
+
+	for branch, b := range cfg.Branches {
+		if b.Remote == name {
+			delete(cfg.Branches, branch)
+			cfg.Raw.Section(branchSectionName).RemoveSubsection(branch)
+		}
+	}
+
+	if err := r.saveConfig(cfg); err != nil {
+		return err
+	}
+
+	return r.moveRemoteRefs(ctx, name, "")
+}
+
+// RenameRemote renames a remote, updating its default fetch refspecs,
+// remote-tracking branches and the upstream configuration of branches
+// that track it. Returns ErrRemoteMissing if the remote does not exist and
+// ErrRemoteExists if newName is taken.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) RenameRemote(ctx context.Context, oldName, newName string) error {
+	if err := validateRemoteName(newName); err != nil {
+		return err
+	}
+
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return WrapError(err, "failed to read repository config")
+	}
+
+	rc, ok := cfg.Remotes[oldName]
+	if !ok {
+		return WrapErrorf(ErrRemoteMissing, "remote %q", oldName)
+	}
+
+	if _, ok := cfg.Remotes[newName]; ok {
+		return WrapErrorf(ErrRemoteExists, "remote %q", newName)
+	}
+
+	remote := remoteFromConfig(cfg, rc)
+	remote.Name = newName
+	for i, spec := range remote.Fetch {
+		remote.Fetch[i] = strings.ReplaceAll(spec, "refs/remotes/"+oldName+"/", "refs/remotes/"+newName+"/")
+	}
+
+	// Move the existing entry so options go-git does not model are kept
+	delete(cfg.Remotes, oldName)
+	rc.Name = newName
+	cfg.Remotes[newName] = rc
+	cfg.Raw.Section(remoteSectionName).Subsection(oldName).Name = newName
+
+	for _, b := range cfg.Branches {
+		if b.Remote == oldName {
+			b.Remote = newName
+		}
+	}
+
+	if err := r.writeRemote(cfg, remote); err != nil {
+		return err
+	}
+
+	return r.moveRemoteRefs(ctx, oldName, newName)
+}
+
+// lookupRemote returns the remote with the given name, or nil if it does not exist.
+func (r *Repo) lookupRemote(name string) (*Remote, error) {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return nil, WrapError(err, "failed to read repository config")
+	}
+
+	rc, ok := cfg.Remotes[name]
+	if !ok {
+		return nil, nil
+	}
+
+	remote := remoteFromConfig(cfg, rc)
+	return &remote, nil
+}
+
+// writeRemote stores a remote in cfg and persists the config.
+func (r *Repo) writeRemote(cfg *config.Config, remote Remote) error {
+	if _, ok := cfg.Remotes[remote.Name]; !ok {
+		cfg.Remotes[remote.Name] = &config.RemoteConfig{Name: remote.Name}
+	}
+
+	// Update the raw section in place to keep options go-git does not model
+	sub := cfg.Raw.Section(remoteSectionName).Subsection(remote.Name)
+	sub.SetOption(remoteURLKey, remote.URL)
+	setOrRemoveOption(sub, remoteFetchKey, remote.Fetch)
+	setOrRemoveOption(sub, remotePushURLKey, remote.PushURLs)
+	setOrRemoveOption(sub, remotePushKey, remote.Push)
+	if remote.Mirror {
+		sub.SetOption(remoteMirrorKey, "true")
+	} else {
+		sub.RemoveOption(remoteMirrorKey)
+	}
+
+	return r.saveConfig(cfg)
+}
+
+// saveConfig persists cfg. go-git reads remote.<name>.pushurl into the URLs
+// of a remote and would write every entry back as url, so each remote with a
+// raw section is rebuilt from it, keeping the options go-git does not model.
+// All config writes go through here.
+func (r *Repo) saveConfig(cfg *config.Config) error {
+	section := cfg.Raw.Section(remoteSectionName)
+	kept := make(map[string]format.Options)
+	for name := range cfg.Remotes {
+		if !section.HasSubsection(name) {
+			continue
+		}
+		options := section.Subsection(name).Options
+		kept[name] = options
+
+		rc := &config.RemoteConfig{
+			Name:   name,
+			URLs:   options.GetAll(remoteURLKey),
+			Mirror: options.Get(remoteMirrorKey) == "true",
+		}
+		for _, spec := range options.GetAll(remoteFetchKey) {
+			rc.Fetch = append(rc.Fetch, config.RefSpec(spec))
+		}
+		cfg.Remotes[name] = rc
+	}
+
+	// Marshal rebuilds the remote sections, then restore the other keys
+	if _, err := cfg.Marshal(); err != nil {
+		return WrapError(err, "failed to encode repository config")
+	}
+	for name, options := range kept {
+		sub := section.Subsection(name)
+		for _, o := range options {
+			if o.IsKey(remoteURLKey) || o.IsKey(remoteFetchKey) || o.IsKey(remoteMirrorKey) {
+				continue
+			}
+			sub.AddOption(o.Key, o.Value)
+		}
+	}
+
+	if err := r.repo.SetConfig(cfg); err != nil {
+		return WrapError(err, "failed to write repository config")
+	}
+
+	return nil
+}
+
+// moveRemoteRefs renames the remote-tracking references of a remote, or
+// deletes them when newName is empty.
+func (r *Repo) moveRemoteRefs(ctx context.Context, oldName, newName string) error {
+	oldPrefix := "refs/remotes/" + oldName + "/"
+	newPrefix := "refs/remotes/" + newName + "/"
+
+	iter, err := r.repo.References()
+	if err != nil {
+		return WrapError(err, "failed to list references")
+	}
+
+	var refs []*plumbing.Reference
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if strings.HasPrefix(ref.Name().String(), oldPrefix) {
+			refs = append(refs, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return WrapError(err, "failed to list references")
+	}
+
+	for _, ref := range refs {
+		if newName != "" {
+			name := plumbing.ReferenceName(newPrefix + strings.TrimPrefix(ref.Name().String(), oldPrefix))
+			var moved *plumbing.Reference
+			if ref.Type() == plumbing.SymbolicReference {
+				target := strings.Replace(ref.Target().String(), oldPrefix, newPrefix, 1)
+				moved = plumbing.NewSymbolicReference(name, plumbing.ReferenceName(target))
+			} else {
+				moved = plumbing.NewHashReference(name, ref.Hash())
+			}
+			if err := r.repo.Storer.SetReference(moved); err != nil {
+				return WrapError(err, "failed to rename remote-tracking reference")
+			}
+		}
+		if err := r.repo.Storer.RemoveReference(ref.Name()); err != nil {
+			return WrapError(err, "failed to remove remote-tracking reference")
+		}
+	}
+
+	return nil
+}
+
+// remoteFromConfig builds a Remote from its go-git config and raw section.
+func remoteFromConfig(cfg *config.Config, rc *config.RemoteConfig) Remote {
+	remote := Remote{Name: rc.Name, Mirror: rc.Mirror}
+	for _, spec := range rc.Fetch {
+		remote.Fetch = append(remote.Fetch, spec.String())
+	}
+
+	// go-git folds pushurl into URLs, so prefer the raw values when present
+	urls := rc.URLs
+	if section := cfg.Raw.Section(remoteSectionName); section.HasSubsection(rc.Name) {
+		sub := section.Subsection(rc.Name)
+		if raw := sub.Options.GetAll(remoteURLKey); len(raw) > 0 {
+			urls = raw
+		}
+		if pushURLs := sub.Options.GetAll(remotePushURLKey); len(pushURLs) > 0 {
+			remote.PushURLs = pushURLs
+		}
+		if push := sub.Options.GetAll(remotePushKey); len(push) > 0 {
+			remote.Push = push
+		}
+	}
+	if len(urls) > 0 {
+		remote.URL = urls[0]
+	}
+
+	return remote
+}
+
+// validateRemote checks the fields of a remote before it is written.
+func validateRemote(remote Remote) error {
+	if err := validateRemoteName(remote.Name); err != nil {
+		return err
+	}
+
+	if remote.URL == "" {
+		return WrapError(ErrInvalidRef, "remote URL cannot be empty")
+	}
+
+	for _, spec := range remote.Fetch {
+		if err := config.RefSpec(spec).Validate(); err != nil {
+			return WrapErrorf(ErrInvalidRef, "invalid fetch refspec %q", spec)
+		}
+	}
+
+	for _, spec := range remote.Push {
+		if err := config.RefSpec(spec).Validate(); err != nil {
+			return WrapErrorf(ErrInvalidRef, "invalid push refspec %q", spec)
+		}
+	}
+
+	return nil
+}
+
+// validateRemoteName checks that a remote name is usable in config and refs.
+func validateRemoteName(name string) error {
+	if name == "" {
+		return WrapError(ErrInvalidRef, "remote name cannot be empty")
+	}
+
+	if strings.ContainsAny(name, " \t\n\"\\") || strings.Contains(name, "..") {
+		return WrapErrorf(ErrInvalidRef, "invalid remote name %q", name)
+	}
+
+	return nil
+}
+
+// defaultFetchRefSpec returns the refspec git configures for a new remote.
+func defaultFetchRefSpec(name string) string {
+	return "+refs/heads/*:refs/remotes/" + name + "/*"
+}
+
+// setOrRemoveOption replaces all values of key, removing it when values is empty.
+func setOrRemoveOption(sub *format.Subsection, key string, values []string) {
+	if len(values) == 0 {
+		sub.RemoveOption(key)
+		return
+	}
+	sub.SetOption(key, values...)
+}
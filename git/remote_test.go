@@ -0,0 +1,280 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	fsb "github.com/input-output-hk/catalyst-forge-libs/fs/billy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddRemote tests adding, listing and reading remotes
+func TestAddRemote(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+
+	remotes, err := tr.repo.Remotes(tr.ctx)
+	require.NoError(t, err)
+	assert.Empty(t, remotes)
+
+	require.NoError(t, tr.repo.AddRemote(tr.ctx, Remote{Name: "upstream", URL: "https://example.com/upstream.git"}))
+	require.NoError(t, tr.repo.AddRemote(tr.ctx, Remote{Name: "origin", URL: "https://example.com/origin.git"}))
+
+	remotes, err = tr.repo.Remotes(tr.ctx)
+	require.NoError(t, err)
+	require.Len(t, remotes, 2)
+	assert.Equal(t, "origin", remotes[0].Name)
+	assert.Equal(t, "upstream", remotes[1].Name)
+
+	remote, err := tr.repo.Remote(tr.ctx, "origin")
+	require.NoError(t, err)
+	assert.Equal(t, &Remote{
+		Name:  "origin",
+		URL:   "https://example.com/origin.git",
+		Fetch: []string{"+refs/heads/*:refs/remotes/origin/*"},
+	}, remote)
+
+	err = tr.repo.AddRemote(tr.ctx, Remote{Name: "origin", URL: "https://example.com/other.git"})
+	require.ErrorIs(t, err, ErrRemoteExists)
+
+	_, err = tr.repo.Remote(tr.ctx, "missing")
+	require.ErrorIs(t, err, ErrRemoteMissing)
+}
+
+// TestAddRemoteValidation tests that invalid remotes are rejected
+func TestAddRemoteValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		remote Remote
+	}{
+		{"empty name", Remote{URL: "https://example.com/repo.git"}},
+		{"name with space", Remote{Name: "my remote", URL: "https://example.com/repo.git"}},
+		{"empty URL", Remote{Name: "origin"}},
+		{"invalid fetch refspec", Remote{Name: "origin", URL: "https://example.com/repo.git", Fetch: []string{"refs/heads/*"}}},
+		{"invalid push refspec", Remote{Name: "origin", URL: "https://example.com/repo.git", Push: []string{"a:b:c"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := setupTestRepo(t, false)
+			err := tr.repo.AddRemote(tr.ctx, tt.remote)
+			require.ErrorIs(t, err, ErrInvalidRef)
+		})
+	}
+}
+
+// TestUpdateRemote tests replacing remote configuration including push settings
+func TestUpdateRemote(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.repo.AddRemote(tr.ctx, Remote{Name: "origin", URL: "https://example.com/repo.git"}))
+
+	updated := Remote{
+		Name:     "origin",
+		URL:      "https://example.com/repo.git",
+		PushURLs: []string{"git@example.com:repo.git", "git@mirror.example.com:repo.git"},
+		Fetch:    []string{"+refs/heads/main:refs/remotes/origin/main"},
+		Push:     []string{"refs/heads/main:refs/heads/main"},
+	}
+	require.NoError(t, tr.repo.UpdateRemote(tr.ctx, updated))
+
+	remote, err := tr.repo.Remote(tr.ctx, "origin")
+	require.NoError(t, err)
+	assert.Equal(t, &updated, remote)
+
+	// SetRemoteURL only changes the fetch URL
+	require.NoError(t, tr.repo.SetRemoteURL(tr.ctx, "origin", "https://example.com/moved.git"))
+	remote, err = tr.repo.Remote(tr.ctx, "origin")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/moved.git", remote.URL)
+	assert.Equal(t, updated.PushURLs, remote.PushURLs)
+	assert.Equal(t, updated.Push, remote.Push)
+
+	// Clearing push URLs removes them
+	remote.PushURLs = nil
+	require.NoError(t, tr.repo.UpdateRemote(tr.ctx, *remote))
+	remote, err = tr.repo.Remote(tr.ctx, "origin")
+	require.NoError(t, err)
+	assert.Empty(t, remote.PushURLs)
+
+	err = tr.repo.UpdateRemote(tr.ctx, Remote{Name: "missing", URL: "https://example.com/repo.git"})
+	require.ErrorIs(t, err, ErrRemoteMissing)
+	err = tr.repo.SetRemoteURL(tr.ctx, "missing", "https://example.com/repo.git")
+	require.ErrorIs(t, err, ErrRemoteMissing)
+}
+
+// TestRemoteConfigPersistence tests that remotes survive reopening an on-disk repository
+func TestRemoteConfigPersistence(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	repo, err := Init(ctx, &Options{FS: fsb.NewOSFS(dir), Workdir: "."})
+	require.NoError(t, err)
+
+	want := Remote{
+		Name:     "origin",
+		URL:      "https://example.com/repo.git",
+		PushURLs: []string{"git@example.com:repo.git"},
+		Fetch:    []string{"+refs/heads/*:refs/remotes/origin/*"},
+		Push:     []string{"refs/heads/main:refs/heads/release"},
+	}
+	require.NoError(t, repo.AddRemote(ctx, want))
+
+	reopened, err := Open(ctx, &Options{FS: fsb.NewOSFS(dir), Workdir: "."})
+	require.NoError(t, err)
+
+	remote, err := reopened.Remote(ctx, "origin")
+	require.NoError(t, err)
+	assert.Equal(t, &want, remote)
+
+	// Rewriting must not fold the push URL into the fetch URLs
+	require.NoError(t, reopened.SetRemoteURL(ctx, "origin", "https://example.com/moved.git"))
+
+	data, err := os.ReadFile(filepath.Join(dir, ".git", "config"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\turl = "), "config:\n%s", data)
+	assert.Equal(t, 1, strings.Count(string(data), "\tpushurl = "), "config:\n%s", data)
+}
+
+// TestRemoteConfigUnrelatedWrites tests that push URLs survive config writes
+// that do not touch their remote
+func TestRemoteConfigUnrelatedWrites(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	repo, err := Init(ctx, &Options{FS: fsb.NewOSFS(dir), Workdir: "."})
+	require.NoError(t, err)
+
+	want := Remote{
+		Name:     "origin",
+		URL:      "https://example.com/repo.git",
+		PushURLs: []string{"git@a:r.git", "git@b:r.git"},
+		Fetch:    []string{"+refs/heads/*:refs/remotes/origin/*"},
+	}
+	require.NoError(t, repo.AddRemote(ctx, want))
+	require.NoError(t, repo.AddRemote(ctx, Remote{Name: "other", URL: "https://example.com/other.git"}))
+	require.NoError(t, repo.RenameRemote(ctx, "other", "fork"))
+	require.NoError(t, repo.RemoveRemote(ctx, "fork"))
+
+	data, err := os.ReadFile(filepath.Join(dir, ".git", "config"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\turl = "), "config:\n%s", data)
+	assert.Equal(t, 2, strings.Count(string(data), "\tpushurl = "), "config:\n%s", data)
+
+	reopened, err := Open(ctx, &Options{FS: fsb.NewOSFS(dir), Workdir: "."})
+	require.NoError(t, err)
+	remote, err := reopened.Remote(ctx, "origin")
+	require.NoError(t, err)
+	assert.Equal(t, &want, remote)
+}
+
+// TestRenameRemote tests renaming a remote with its refs and upstream configuration
+func TestRenameRemote(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.repo.AddRemote(tr.ctx, Remote{Name: "origin", URL: "https://example.com/repo.git"}))
+	require.NoError(t, tr.repo.AddRemote(tr.ctx, Remote{Name: "fork", URL: "https://example.com/fork.git"}))
+	tr.createRemoteBranch(t, "origin", "main")
+	require.NoError(t, tr.repo.SetUpstream(tr.ctx, "master", "origin", "main"))
+
+	err := tr.repo.RenameRemote(tr.ctx, "origin", "fork")
+	require.ErrorIs(t, err, ErrRemoteExists)
+
+	require.NoError(t, tr.repo.RenameRemote(tr.ctx, "origin", "upstream"))
+
+	_, err = tr.repo.Remote(tr.ctx, "origin")
+	require.ErrorIs(t, err, ErrRemoteMissing)
+
+	remote, err := tr.repo.Remote(tr.ctx, "upstream")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/repo.git", remote.URL)
+	assert.Equal(t, []string{"+refs/heads/*:refs/remotes/upstream/*"}, remote.Fetch)
+
+	_, err = tr.repo.repo.Reference(plumbing.NewRemoteReferenceName("upstream", "main"), true)
+	require.NoError(t, err, "remote-tracking branch should be moved")
+	_, err = tr.repo.repo.Reference(plumbing.NewRemoteReferenceName("origin", "main"), true)
+	require.Error(t, err, "old remote-tracking branch should be removed")
+
+	upstream, err := tr.repo.Upstream(tr.ctx, "master")
+	require.NoError(t, err)
+	assert.Equal(t, &Upstream{Remote: "upstream", Branch: "main"}, upstream)
+
+	err = tr.repo.RenameRemote(tr.ctx, "missing", "other")
+	require.ErrorIs(t, err, ErrRemoteMissing)
+}
+
+// TestRemoveRemote tests removing a remote with its refs and upstream configuration
+func TestRemoveRemote(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.repo.AddRemote(tr.ctx, Remote{Name: "origin", URL: "https://example.com/repo.git"}))
+	tr.createRemoteBranch(t, "origin", "main")
+	require.NoError(t, tr.repo.SetUpstream(tr.ctx, "master", "origin", "main"))
+
+	require.NoError(t, tr.repo.RemoveRemote(tr.ctx, "origin"))
+
+	remotes, err := tr.repo.Remotes(tr.ctx)
+	require.NoError(t, err)
+	assert.Empty(t, remotes)
+
+	_, err = tr.repo.repo.Reference(plumbing.NewRemoteReferenceName("origin", "main"), true)
+	require.Error(t, err, "remote-tracking branch should be removed")
+
+	_, err = tr.repo.Upstream(tr.ctx, "master")
+	require.ErrorIs(t, err, ErrNoUpstream)
+
+	err = tr.repo.RemoveRemote(tr.ctx, "origin")
+	require.ErrorIs(t, err, ErrRemoteMissing)
+}
+
+// TestPushToPushURLs tests that Push uses push refspecs and every push URL
+func TestPushToPushURLs(t *testing.T) {
+	ctx := context.Background()
+	primaryDir, mirrorDir := t.TempDir(), t.TempDir()
+
+	primary, err := Init(ctx, &Options{FS: fsb.NewOSFS(primaryDir), Bare: true, Workdir: "."})
+	require.NoError(t, err)
+	mirror, err := Init(ctx, &Options{FS: fsb.NewOSFS(mirrorDir), Bare: true, Workdir: "."})
+	require.NoError(t, err)
+
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.repo.AddRemote(tr.ctx, Remote{
+		Name:     "origin",
+		URL:      "https://example.com/unreachable.git",
+		PushURLs: []string{"file://" + primaryDir, "file://" + mirrorDir},
+		Push:     []string{"refs/heads/master:refs/heads/release"},
+	}))
+
+	require.NoError(t, tr.repo.Push(tr.ctx, "origin", false))
+
+	head, err := tr.repo.repo.Head()
+	require.NoError(t, err)
+	for _, target := range []*Repo{primary, mirror} {
+		ref, err := target.repo.Reference(plumbing.NewBranchReferenceName("release"), true)
+		require.NoError(t, err, "push refspec should be used for every push URL")
+		assert.Equal(t, head.Hash(), ref.Hash())
+	}
+
+	err = tr.repo.Push(tr.ctx, "origin", false)
+	require.ErrorIs(t, err, ErrAlreadyUpToDate)
+}
+
+// TestPushCurrentBranch tests that only the current branch is pushed without refspecs
+func TestPushCurrentBranch(t *testing.T) {
+	ctx := context.Background()
+	remoteDir := t.TempDir()
+	target, err := Init(ctx, &Options{FS: fsb.NewOSFS(remoteDir), Bare: true, Workdir: "."})
+	require.NoError(t, err)
+
+	tr := setupTestRepoWithCommit(t)
+	tr.createTestBranch(t, "other")
+	require.NoError(t, tr.repo.AddRemote(tr.ctx, Remote{Name: "origin", URL: "file://" + remoteDir}))
+
+	require.NoError(t, tr.repo.Push(tr.ctx, "origin", false))
+
+	_, err = target.repo.Reference(plumbing.NewBranchReferenceName("master"), true)
+	require.NoError(t, err)
+	_, err = target.repo.Reference(plumbing.NewBranchReferenceName("other"), true)
+	require.Error(t, err, "other branches should not be pushed")
+}
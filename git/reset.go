@@ -0,0 +1,408 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains reset, restore and clean operations on the worktree.
+package git
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+)
+
+// ResetMode selects what Reset updates besides HEAD.
+type ResetMode int8
+
+const (
+	// ResetMixed moves HEAD and resets the index, keeping the working tree.
+	// This is the default, like `git reset`.
+	ResetMixed ResetMode = iota
+
+	// ResetSoft only moves HEAD, keeping the index and working tree.
+	ResetSoft
+
+	// ResetHard moves HEAD and resets the index and the tracked files of the
+	// working tree. Untracked files are kept; use Clean to remove them.
+	ResetHard
+)
+
+// String returns a human-readable string representation of the ResetMode.
+func (m ResetMode) String() string {
+	switch m {
+	case ResetMixed:
+		return "mixed"
+	case ResetSoft:
+		return "soft"
+	case ResetHard:
+		return "hard"
+	default:
+		return "unknown"
+	}
+}
+
+// ResetOpts configures Reset.
+type ResetOpts struct {
+	// Mode selects what is reset besides HEAD. Defaults to ResetMixed.
+	Mode ResetMode
+
+	// DryRun reports what would change without changing anything.
+	DryRun bool
+}
+
+// ResetResult reports what Reset changed, or would change with DryRun.
+type ResetResult struct {
+	// Head is the SHA HEAD points to after the reset.
+	Head string
+
+	// Index lists the paths whose index entry changed, sorted.
+	Index []string
+
+	// Worktree lists the paths whose working tree file changed, sorted.
+	Worktree []string
+}
+
+// RestoreOpts configures Restore.
+type RestoreOpts struct {
+	// Source is the revision to restore from. Defaults to the index when only
+	// restoring the working tree and to HEAD when restoring the index.
+	Source string
+
+	// Staged restores the index entries of the paths.
+	Staged bool
+
+	// Worktree restores the working tree files of the paths. This is the
+	// default when neither Staged nor Worktree is set.
+	Worktree bool
+
+	// DryRun reports what would change without changing anything.
+	DryRun bool
+}
+
+// CleanOpts configures Clean.
+type CleanOpts struct {
+	// Paths limits the clean to the given paths. A directory matches
+	// everything below it and glob patterns are supported. Empty means all.
+	Paths []string
+
+	// Ignored also removes files matched by .gitignore (like `git clean -x`).
+	Ignored bool
+
+	// DryRun reports what would be removed without removing anything.
+	DryRun bool
+}
+
+// Reset moves HEAD (or the branch it points to) to rev and, depending on
+// opts.Mode, resets the index and working tree to it, like `git reset`.
+// An empty rev means HEAD, so a hard reset to "" discards every uncommitted
+// change to tracked files. Unresolved conflicts are cleared from the index.
+//
+// Soft resets are allowed in bare repositories; other modes require a worktree.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Reset(ctx context.Context, rev string, opts ResetOpts) (*ResetResult, error) {
+	if opts.Mode != ResetSoft && r.worktree == nil {
+		return nil, WrapErrorf(ErrInvalidRef, "cannot %s reset in bare repository", opts.Mode)
+	}
+	if rev == "" {
+		rev = gitHead
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, WrapError(err, "reset cancelled")
+	}
+
+	head, _, err := r.headCommit()
+	if err != nil {
+		return nil, err
+	}
+	target, err := r.commitOf(rev)
+	if err != nil {
+		return nil, err
+	}
+	result := &ResetResult{Head: target.Hash.String()}
+
+	var indexFiles, targetFiles map[string]treeFile
+	if opts.Mode != ResetSoft {
+		var conflicted []string
+		if indexFiles, conflicted, err = r.readIndex(); err != nil {
+			return nil, err
+		}
+		if targetFiles, err = r.commitFiles(target); err != nil {
+			return nil, err
+		}
+		result.Index = append(changedFiles(indexFiles, targetFiles), conflicted...)
+		sort.Strings(result.Index)
+
+		if opts.Mode == ResetHard {
+			paths := unionKeys(indexFiles, targetFiles)
+			for _, p := range conflicted {
+				paths[p] = struct{}{}
+			}
+			if result.Worktree, err = r.changedWorktreeFiles(paths, targetFiles); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(head.Name(), target.Hash)); err != nil {
+		return nil, WrapError(err, "failed to update HEAD")
+	}
+	if opts.Mode == ResetSoft {
+		return result, nil
+	}
+
+	if err := r.writeIndex(targetFiles); err != nil {
+		return nil, err
+	}
+	for _, p := range result.Worktree {
+		if err := r.restoreWorktreeFile(p, targetFiles); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Restore restores paths in the working tree and/or index from a source,
+// like `git restore`, without moving HEAD. A directory matches everything
+// below it and glob patterns are supported. Files absent from the source are
+// removed, but working tree files are only removed if the index tracks them.
+// It returns the paths that changed (or would change with DryRun), sorted.
+//
+// Returns ErrInvalidRef if no paths are given or a path matches no file in
+// the source or the index (or HEAD when restoring the index).
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Restore(ctx context.Context, opts RestoreOpts, paths ...string) ([]string, error) {
+	if r.worktree == nil {
+		return nil, WrapError(ErrInvalidRef, "cannot restore files in bare repository")
+	}
+	if len(paths) == 0 {
+		return nil, WrapError(ErrInvalidRef, "no paths to restore")
+	}
+	if !opts.Staged && !opts.Worktree {
+		opts.Worktree = true
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, WrapError(err, "restore cancelled")
+	}
+
+	indexFiles, _, err := r.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	_, head, err := r.headCommit()
+	if err != nil {
+		return nil, err
+	}
+	headFiles, err := r.commitFiles(head)
+	if err != nil {
+		return nil, err
+	}
+
+	// The source defaults to the index for the worktree and HEAD for the index
+	sourceFiles := headFiles
+	switch {
+	case opts.Source != "":
+		source, err := r.commitOf(opts.Source)
+		if err != nil {
+			return nil, err
+		}
+		if sourceFiles, err = r.commitFiles(source); err != nil {
+			return nil, err
+		}
+	case !opts.Staged:
+		sourceFiles = indexFiles
+	}
+
+	// Select the known files matching the paths
+	known := unionKeys(sourceFiles, indexFiles)
+	if opts.Staged {
+		for p := range headFiles {
+			known[p] = struct{}{}
+		}
+	}
+	selected := make(map[string]struct{})
+	for _, pattern := range paths {
+		matched := false
+		for p := range known {
+			if matchesStatusPath(p, pattern) {
+				selected[p] = struct{}{}
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, WrapErrorf(ErrInvalidRef, "path %q did not match any file known to git", pattern)
+		}
+	}
+
+	stage := make(map[string]*treeFile)
+	var changed, worktree []string
+	if opts.Staged {
+		for p := range selected {
+			s, inSource := sourceFiles[p]
+			if i, inIndex := indexFiles[p]; inIndex != inSource || i != s {
+				stage[p] = sideOf(s, inSource)
+				changed = append(changed, p)
+			}
+		}
+	}
+	if opts.Worktree {
+		// Files the index does not track are left alone unless in the source
+		restorable := make(map[string]struct{}, len(selected))
+		for p := range selected {
+			_, inSource := sourceFiles[p]
+			if _, inIndex := indexFiles[p]; inSource || inIndex {
+				restorable[p] = struct{}{}
+			}
+		}
+		if worktree, err = r.changedWorktreeFiles(restorable, sourceFiles); err != nil {
+			return nil, err
+		}
+		changed = append(changed, worktree...)
+	}
+	changed = sortedUnique(changed)
+
+	if opts.DryRun {
+		return changed, nil
+	}
+
+	for _, p := range worktree {
+		if err := r.restoreWorktreeFile(p, sourceFiles); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.stageFiles(stage); err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// Clean removes untracked files from the working tree, like `git clean -d`,
+// and with opts.Ignored also ignored files, like `git clean -dx`. Directories
+// left empty are removed. Nested repositories (directories containing a .git)
+// are left alone. It returns the removed files (or the files that would be
+// removed with DryRun), sorted.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Clean(ctx context.Context, opts CleanOpts) ([]string, error) {
+	if r.worktree == nil {
+		return nil, WrapError(ErrInvalidRef, "cannot clean files in bare repository")
+	}
+
+	status, err := r.Status(ctx, StatusOpts{Paths: opts.Paths, IncludeIgnored: opts.Ignored})
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	nested := make(map[string]bool)
+	for _, e := range status.Entries {
+		if (e.IsUntracked() || e.Index == StatusIgnored) && !r.inNestedRepo(e.Path, nested) {
+			removed = append(removed, e.Path)
+		}
+	}
+
+	if opts.DryRun {
+		return removed, nil
+	}
+
+	for _, p := range removed {
+		if err := ctx.Err(); err != nil {
+			return nil, WrapError(err, "clean cancelled")
+		}
+		if err := r.removeWorktreeFile(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return removed, nil
+}
+
+// inNestedRepo reports whether p is below a directory containing a .git, i.e.,
+// inside another repository. Results for directories are cached in nested.
+func (r *Repo) inNestedRepo(p string, nested map[string]bool) bool {
+	for dir := path.Dir(p); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		found, ok := nested[dir]
+		if !ok {
+			_, err := r.worktree.Filesystem.Lstat(path.Join(dir, ".git"))
+			found = err == nil
+			nested[dir] = found
+		}
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeIndex replaces the index with the given files.
+func (r *Repo) writeIndex(files map[string]treeFile) error {
+	idx := &index.Index{Version: 2}
+	for p, f := range files {
+		idx.Entries = append(idx.Entries, &index.Entry{Name: p, Hash: f.hash, Mode: f.mode})
+	}
+	sort.Slice(idx.Entries, func(i, j int) bool {
+		return idx.Entries[i].Name < idx.Entries[j].Name
+	})
+
+	if err := r.repo.Storer.SetIndex(idx); err != nil {
+		return WrapError(err, "failed to write index")
+	}
+
+	return nil
+}
+
+// changedWorktreeFiles returns the paths whose working tree file differs from
+// want, where a path missing from want should not exist, sorted.
+func (r *Repo) changedWorktreeFiles(paths map[string]struct{}, want map[string]treeFile) ([]string, error) {
+	var changed []string
+	for p := range paths {
+		w, wanted := want[p]
+		if w.mode == filemode.Submodule {
+			continue
+		}
+
+		content, mode, exists, err := r.readWorktreeFile(p)
+		if err != nil {
+			return nil, err
+		}
+		if exists != wanted ||
+			exists && (mode != w.mode || plumbing.ComputeHash(plumbing.BlobObject, content) != w.hash) {
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed, nil
+}
+
+// restoreWorktreeFile writes the version of p in files to the working tree,
+// or removes p if it is not in files.
+func (r *Repo) restoreWorktreeFile(p string, files map[string]treeFile) error {
+	if f, ok := files[p]; ok {
+		return r.writeWorktreeFile(p, f)
+	}
+	return r.removeWorktreeFile(p)
+}
+
+// sortedUnique sorts paths and removes duplicates.
+func sortedUnique(paths []string) []string {
+	sort.Strings(paths)
+
+	unique := paths[:0]
+	for i, p := range paths {
+		if i == 0 || p != paths[i-1] {
+			unique = append(unique, p)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,227 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResetModes tests soft, mixed and hard resets to a previous commit
+func TestResetModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     ResetMode
+		index    []string
+		worktree []string
+		status   []string
+		content  string
+	}{
+		{
+			name:    "soft",
+			mode:    ResetSoft,
+			status:  []string{"A  new.txt", "?? scratch.txt", "M  test.txt"},
+			content: "second",
+		},
+		{
+			name:    "mixed",
+			mode:    ResetMixed,
+			index:   []string{"new.txt", "test.txt"},
+			status:  []string{"?? new.txt", "?? scratch.txt", " M test.txt"},
+			content: "second",
+		},
+		{
+			name:     "hard",
+			mode:     ResetHard,
+			index:    []string{"new.txt", "test.txt"},
+			worktree: []string{"new.txt", "test.txt"},
+			status:   []string{"?? scratch.txt"},
+			content:  "initial content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := setupTestRepoWithCommit(t)
+			first, err := tr.repo.Resolve(tr.ctx, "HEAD")
+			require.NoError(t, err)
+			tr.commitFile(t, "test.txt", "second")
+			tr.commitFile(t, "new.txt", "new")
+			require.NoError(t, tr.fs.WriteFile("scratch.txt", []byte("scratch"), 0o644))
+
+			// Dry run reports without changing anything
+			result, err := tr.repo.Reset(tr.ctx, first.Hash, ResetOpts{Mode: tt.mode, DryRun: true})
+			require.NoError(t, err)
+			assert.Equal(t, first.Hash, result.Head)
+			assert.Equal(t, tt.index, result.Index)
+			assert.Equal(t, tt.worktree, result.Worktree)
+			assert.Equal(t, []string{"?? scratch.txt"}, tr.porcelain(t))
+
+			result, err = tr.repo.Reset(tr.ctx, first.Hash, ResetOpts{Mode: tt.mode})
+			require.NoError(t, err)
+			assert.Equal(t, tt.index, result.Index)
+			assert.Equal(t, tt.worktree, result.Worktree)
+
+			head, err := tr.repo.Resolve(tr.ctx, "master")
+			require.NoError(t, err)
+			assert.Equal(t, first.Hash, head.Hash)
+			assert.Equal(t, tt.status, tr.porcelain(t))
+			assert.Equal(t, tt.content, tr.readFile(t, "test.txt"))
+		})
+	}
+}
+
+// TestResetHardDiscardsChanges tests discarding uncommitted changes
+func TestResetHardDiscardsChanges(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.commitFile(t, "file.txt", mergeBaseContent)
+
+	tr.modifyTestFile(t, "dirty")
+	require.NoError(t, tr.fs.Remove("file.txt"))
+
+	result, err := tr.repo.Reset(tr.ctx, "", ResetOpts{Mode: ResetHard})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"file.txt", "test.txt"}, result.Worktree)
+	assert.Empty(t, tr.porcelain(t))
+	assert.Equal(t, mergeBaseContent, tr.readFile(t, "file.txt"))
+
+	_, err = tr.repo.Reset(tr.ctx, "nonexistent", ResetOpts{})
+	require.ErrorIs(t, err, ErrResolveFailed)
+
+	bare := setupTestRepo(t, true)
+	_, err = bare.repo.Reset(bare.ctx, "HEAD", ResetOpts{Mode: ResetHard})
+	require.ErrorIs(t, err, ErrInvalidRef)
+}
+
+// TestRestore tests restoring paths in the worktree and index
+func TestRestore(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	first, err := tr.repo.Resolve(tr.ctx, "HEAD")
+	require.NoError(t, err)
+	require.NoError(t, tr.fs.MkdirAll("dir", 0o755))
+	tr.commitFile(t, "dir/a.txt", "a")
+	tr.commitFile(t, "dir/b.txt", "b")
+
+	// Worktree from the index
+	require.NoError(t, tr.fs.WriteFile("dir/a.txt", []byte("staged"), 0o644))
+	require.NoError(t, tr.repo.Add(tr.ctx, "dir/a.txt"))
+	require.NoError(t, tr.fs.WriteFile("dir/a.txt", []byte("unstaged"), 0o644))
+	require.NoError(t, tr.fs.Remove("dir/b.txt"))
+	tr.modifyTestFile(t, "modified")
+
+	changed, err := tr.repo.Restore(tr.ctx, RestoreOpts{DryRun: true}, "dir")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dir/a.txt", "dir/b.txt"}, changed)
+	assert.Equal(t, "unstaged", tr.readFile(t, "dir/a.txt"))
+
+	changed, err = tr.repo.Restore(tr.ctx, RestoreOpts{}, "dir")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dir/a.txt", "dir/b.txt"}, changed)
+	assert.Equal(t, "staged", tr.readFile(t, "dir/a.txt"))
+	assert.Equal(t, "b", tr.readFile(t, "dir/b.txt"))
+	assert.Equal(t, []string{"M  dir/a.txt", " M test.txt"}, tr.porcelain(t))
+
+	// Index from HEAD
+	changed, err = tr.repo.Restore(tr.ctx, RestoreOpts{Staged: true}, "dir/*.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dir/a.txt"}, changed)
+	assert.Equal(t, []string{" M dir/a.txt", " M test.txt"}, tr.porcelain(t))
+
+	// Both from an older revision, removing files absent there
+	changed, err = tr.repo.Restore(tr.ctx, RestoreOpts{Source: first.Hash, Staged: true, Worktree: true}, "dir", "test.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dir/a.txt", "dir/b.txt", "test.txt"}, changed)
+	assert.Equal(t, []string{"D  dir/a.txt", "D  dir/b.txt"}, tr.porcelain(t))
+	assert.Equal(t, "initial content", tr.readFile(t, "test.txt"))
+
+	_, err = tr.repo.Restore(tr.ctx, RestoreOpts{}, "missing.txt")
+	require.ErrorIs(t, err, ErrInvalidRef)
+	_, err = tr.repo.Restore(tr.ctx, RestoreOpts{})
+	require.ErrorIs(t, err, ErrInvalidRef)
+}
+
+// TestRestoreUntracked tests that files the index does not track are kept
+func TestRestoreUntracked(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.commitFile(t, "keep.txt", "keep")
+	_, err := tr.repo.worktree.Remove("keep.txt")
+	require.NoError(t, err)
+	require.NoError(t, tr.fs.WriteFile("keep.txt", []byte("keep"), 0o644))
+	indexFiles, _, err := tr.repo.readIndex()
+	require.NoError(t, err)
+	require.NotContains(t, indexFiles, "keep.txt")
+
+	// Only in HEAD, so unknown to a restore from the index
+	_, err = tr.repo.Restore(tr.ctx, RestoreOpts{}, "keep.txt")
+	require.ErrorIs(t, err, ErrInvalidRef)
+	assert.Equal(t, "keep", tr.readFile(t, "keep.txt"))
+
+	// Restoring the index does not touch the working tree file
+	changed, err := tr.repo.Restore(tr.ctx, RestoreOpts{Staged: true}, "keep.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"keep.txt"}, changed)
+	assert.Empty(t, tr.porcelain(t))
+}
+
+// TestClean tests removing untracked and ignored files
+func TestClean(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.commitFile(t, ".gitignore", "build/\n")
+	require.NoError(t, tr.fs.MkdirAll("build", 0o755))
+	require.NoError(t, tr.fs.MkdirAll("tmp/nested", 0o755))
+	require.NoError(t, tr.fs.WriteFile("build/out.bin", []byte("bin"), 0o644))
+	require.NoError(t, tr.fs.WriteFile("tmp/nested/a.txt", []byte("a"), 0o644))
+	require.NoError(t, tr.fs.WriteFile("scratch.txt", []byte("scratch"), 0o644))
+	tr.modifyTestFile(t, "modified")
+
+	removed, err := tr.repo.Clean(tr.ctx, CleanOpts{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"scratch.txt", "tmp/nested/a.txt"}, removed)
+	exists, err := tr.fs.Exists("scratch.txt")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	removed, err = tr.repo.Clean(tr.ctx, CleanOpts{Paths: []string{"tmp"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tmp/nested/a.txt"}, removed)
+	exists, err = tr.fs.Exists("tmp")
+	require.NoError(t, err)
+	assert.False(t, exists, "emptied directories are removed")
+
+	removed, err = tr.repo.Clean(tr.ctx, CleanOpts{Ignored: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"build/out.bin", "scratch.txt"}, removed)
+	assert.Equal(t, []string{" M test.txt"}, tr.porcelain(t), "tracked changes are kept")
+
+	// A reused checkout back to a pristine state
+	_, err = tr.repo.Reset(tr.ctx, "", ResetOpts{Mode: ResetHard})
+	require.NoError(t, err)
+	status, err := tr.repo.Status(tr.ctx, StatusOpts{IncludeIgnored: true})
+	require.NoError(t, err)
+	assert.True(t, status.IsClean())
+}
+
+// TestCleanNestedRepo tests that nested repositories are left alone
+func TestCleanNestedRepo(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.fs.MkdirAll("vendor/lib/.git", 0o755))
+	require.NoError(t, tr.fs.WriteFile("vendor/lib/.git/HEAD", []byte("ref: refs/heads/master\n"), 0o644))
+	require.NoError(t, tr.fs.WriteFile("vendor/lib/work.go", []byte("package lib"), 0o644))
+	require.NoError(t, tr.fs.WriteFile("vendor/scratch.txt", []byte("scratch"), 0o644))
+
+	removed, err := tr.repo.Clean(tr.ctx, CleanOpts{Ignored: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vendor/scratch.txt"}, removed)
+	assert.Equal(t, "package lib", tr.readFile(t, "vendor/lib/work.go"))
+	exists, err := tr.fs.Exists("vendor/lib/.git/HEAD")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+// TestResetMode_String tests the String method of ResetMode
+func TestResetMode_String(t *testing.T) {
+	assert.Equal(t, "mixed", ResetMode(0).String())
+	assert.Equal(t, "soft", ResetSoft.String())
+	assert.Equal(t, "hard", ResetHard.String())
+	assert.Equal(t, "unknown", ResetMode(99).String())
+}
@@ -0,0 +1,498 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains semantic version tag discovery and next-version calculation.
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Version is a semantic version (https://semver.org) parsed from a tag name.
+type Version struct {
+	// Prefix is everything before the version core, including the optional
+	// "v" (e.g., "v" for "v1.2.3", "oci/v" for "oci/v1.2.3", "" for "1.2.3").
+	Prefix string
+
+	// Major, Minor and Patch are the numeric version components.
+	Major, Minor, Patch uint64
+
+	// Prerelease is the dot-separated pre-release identifiers without the
+	// leading "-" (e.g., "rc.1"), or empty for a release.
+	Prerelease string
+
+	// Build is the build metadata without the leading "+", or empty.
+	Build string
+}
+
+// ParseVersion parses a tag name as a semantic version with an optional prefix.
+// The version core must follow the last "/" and may start with "v", so
+// "v1.2.3", "1.2.3-rc.1" and "oci/v1.2.3+build.5" are all accepted.
+func ParseVersion(tag string) (Version, error) {
+	var v Version
+
+	prefix, rest := "", tag
+	if i := strings.LastIndex(tag, "/"); i >= 0 {
+		prefix, rest = tag[:i+1], tag[i+1:]
+	}
+	if strings.HasPrefix(rest, "v") {
+		prefix, rest = prefix+"v", rest[1:]
+	}
+	v.Prefix = prefix
+
+	var hasBuild, hasPrerelease bool
+	rest, v.Build, hasBuild = strings.Cut(rest, "+")
+	rest, v.Prerelease, hasPrerelease = strings.Cut(rest, "-")
+
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return Version{}, WrapErrorf(ErrInvalidRef, "tag %q is not a semantic version", tag)
+	}
+
+	nums := [3]*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := parseNumericIdentifier(part)
+		if err != nil {
+			return Version{}, WrapErrorf(ErrInvalidRef, "tag %q is not a semantic version", tag)
+		}
+		*nums[i] = n
+	}
+
+	if hasPrerelease && !validIdentifiers(v.Prerelease, true) {
+		return Version{}, WrapErrorf(ErrInvalidRef, "tag %q has an invalid pre-release", tag)
+	}
+
+	if hasBuild && !validIdentifiers(v.Build, false) {
+		return Version{}, WrapErrorf(ErrInvalidRef, "tag %q has invalid build metadata", tag)
+	}
+
+	return v, nil
+}
+
+// String returns the tag name of the version, including its prefix.
+func (v Version) String() string {
+	return v.Prefix + v.SemVer()
+}
+
+// SemVer returns the version without its prefix (e.g., "1.2.3-rc.1").
+func (v Version) SemVer() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// IsPrerelease reports whether the version has pre-release identifiers.
+func (v Version) IsPrerelease() bool {
+	return v.Prerelease != ""
+}
+
+// Compare returns -1, 0 or +1 depending on whether v has lower, equal or
+// higher precedence than other. Prefixes and build metadata are ignored.
+func (v Version) Compare(other Version) int {
+	for _, c := range [][2]uint64{
+		{v.Major, other.Major},
+		{v.Minor, other.Minor},
+		{v.Patch, other.Patch},
+	} {
+		if c[0] != c[1] {
+			if c[0] < c[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+// VersionBump is the kind of version increment a change requires.
+type VersionBump int8
+
+const (
+	// BumpNone means the change does not require a release.
+	BumpNone VersionBump = iota
+
+	// BumpPatch increments the patch version (fixes).
+	BumpPatch
+
+	// BumpMinor increments the minor version (features).
+	BumpMinor
+
+	// BumpMajor increments the major version (breaking changes).
+	BumpMajor
+)
+
+// String returns a human-readable string representation of the VersionBump.
+func (b VersionBump) String() string {
+	switch b {
+	case BumpNone:
+		return "none"
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	default:
+		return "unknown"
+	}
+}
+
+// Bump returns the version incremented by b, without pre-release or build
+// metadata. A pre-release is promoted to its release when that is already a
+// large enough increment, so bumping "1.3.0-rc.1" by a minor gives "1.3.0".
+func (v Version) Bump(b VersionBump) Version {
+	next := Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	pre := v.IsPrerelease()
+
+	switch b {
+	case BumpMajor:
+		if !pre || v.Minor != 0 || v.Patch != 0 {
+			next.Major, next.Minor, next.Patch = v.Major+1, 0, 0
+		}
+	case BumpMinor:
+		if !pre || v.Patch != 0 {
+			next.Minor, next.Patch = v.Minor+1, 0
+		}
+	case BumpPatch:
+		if !pre {
+			next.Patch = v.Patch + 1
+		}
+	case BumpNone:
+		return v
+	}
+
+	return next
+}
+
+// ConventionalBump returns the version bump a commit message requires under
+// the Conventional Commits specification: "feat" is a minor bump, "fix" and
+// "perf" are patch bumps, and a "!" after the type or a "BREAKING CHANGE:"
+// line anywhere in the body is a major bump. Other types and non-conventional
+// messages need no release.
+func ConventionalBump(message string) VersionBump {
+	cc, ok := ParseConventionalCommit(message)
+
+	switch {
+	case !ok:
+		return BumpNone
+	case cc.Breaking:
+		return BumpMajor
+	case cc.Type == "feat":
+		return BumpMinor
+	case cc.Type == "fix", cc.Type == "perf":
+		return BumpPatch
+	default:
+		return BumpNone
+	}
+}
+
+// VersionOpts selects the version tags considered by the version helpers.
+type VersionOpts struct {
+	// Prefix must equal the Prefix of a tag's version for it to be considered,
+	// e.g., "v" for "v1.2.3" or "oci/v" for "oci/v1.2.3". An empty prefix
+	// selects unprefixed tags such as "1.2.3".
+	Prefix string
+
+	// IncludePrerelease considers pre-release tags (e.g., "v1.3.0-rc.1").
+	// By default only releases are considered.
+	IncludePrerelease bool
+}
+
+// versionTag is a parsed version tag and the commit it points to.
+type versionTag struct {
+	version Version
+	commit  plumbing.Hash
+}
+
+// VersionTags returns the semantic version tags selected by opts, sorted by
+// ascending precedence. Tags that are not valid semantic versions are skipped.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) VersionTags(ctx context.Context, opts VersionOpts) ([]Version, error) {
+	tags, err := r.versionTags(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]Version, len(tags))
+	for i, tag := range tags {
+		versions[i] = tag.version
+	}
+
+	return versions, nil
+}
+
+// LatestVersion returns the highest version tag selected by opts that is
+// reachable from rev (i.e., points to rev or one of its ancestors).
+// Returns ErrNoVersion if there is no such tag.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) LatestVersion(ctx context.Context, rev string, opts VersionOpts) (*Version, error) {
+	latest, _, err := r.latestVersion(ctx, rev, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &latest.version, nil
+}
+
+// NextVersion computes the version to release rev as by applying the largest
+// ConventionalBump of the commits since LatestVersion. Without a previous
+// version, all commits are considered and the bump applies to 0.0.0 with
+// opts.Prefix. If no commit requires a release, the latest version is returned
+// unchanged, or ErrNoVersion if there is none.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) NextVersion(ctx context.Context, rev string, opts VersionOpts) (*Version, error) {
+	latest, head, err := r.latestVersion(ctx, rev, opts)
+	if err != nil && !errors.Is(err, ErrNoVersion) {
+		return nil, err
+	}
+
+	base := Version{Prefix: opts.Prefix}
+	var released []plumbing.Hash
+	if latest != nil {
+		base = latest.version
+		released = append(released, latest.commit)
+	}
+
+	bump, err := r.bumpSince(ctx, head, released)
+	if err != nil {
+		return nil, err
+	}
+
+	if bump == BumpNone {
+		if latest == nil {
+			return nil, WrapErrorf(ErrNoVersion, "no release needed and no %q version tag found", opts.Prefix)
+		}
+		return &base, nil
+	}
+
+	next := base.Bump(bump)
+	return &next, nil
+}
+
+// latestVersion finds the highest selected version tag reachable from rev and
+// returns it with the resolved rev commit. The tag is nil with ErrNoVersion
+// if none is reachable.
+func (r *Repo) latestVersion(ctx context.Context, rev string, opts VersionOpts) (*versionTag, plumbing.Hash, error) {
+	head, err := r.commitOf(rev)
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+
+	tags, err := r.versionTags(ctx, opts)
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+
+	for i := len(tags) - 1; i >= 0; i-- {
+		ok, err := r.isAncestor(ctx, tags[i].commit, head.Hash)
+		if err != nil {
+			return nil, plumbing.ZeroHash, err
+		}
+		if ok {
+			return &tags[i], head.Hash, nil
+		}
+	}
+
+	return nil, head.Hash, WrapErrorf(ErrNoVersion, "no %q version tag reachable from %q", opts.Prefix, rev)
+}
+
+// versionTags parses the selected version tags, sorted by ascending precedence.
+func (r *Repo) versionTags(ctx context.Context, opts VersionOpts) ([]versionTag, error) {
+	refs, err := r.repo.Tags()
+	if err != nil {
+		return nil, WrapError(err, "failed to get tags")
+	}
+
+	var tags []versionTag
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		v, parseErr := ParseVersion(ref.Name().Short())
+		if parseErr != nil || v.Prefix != opts.Prefix || (v.IsPrerelease() && !opts.IncludePrerelease) {
+			return nil
+		}
+
+		commit, ok := r.peelTag(ref)
+		if !ok {
+			return nil
+		}
+
+		tags = append(tags, versionTag{version: v, commit: commit})
+		return nil
+	})
+	if err != nil {
+		return nil, WrapError(err, "failed to iterate tags")
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		if c := tags[i].version.Compare(tags[j].version); c != 0 {
+			return c < 0
+		}
+		return tags[i].version.String() < tags[j].version.String()
+	})
+
+	return tags, nil
+}
+
+// peelTag returns the commit a tag reference points to, following annotated
+// tag objects. It reports false for tags that do not point to a commit.
+func (r *Repo) peelTag(ref *plumbing.Reference) (plumbing.Hash, bool) {
+	tag, err := r.repo.TagObject(ref.Hash())
+	if err != nil {
+		// Lightweight tag
+		if _, err := r.repo.CommitObject(ref.Hash()); err != nil {
+			return plumbing.ZeroHash, false
+		}
+		return ref.Hash(), true
+	}
+
+	commit, err := tag.Commit()
+	if err != nil {
+		return plumbing.ZeroHash, false
+	}
+
+	return commit.Hash, true
+}
+
+// bumpSince returns the largest ConventionalBump of the commits reachable from
+// head but not from the released commits.
+func (r *Repo) bumpSince(ctx context.Context, head plumbing.Hash, released []plumbing.Hash) (VersionBump, error) {
+	walk, err := r.newRevWalk(ctx, []plumbing.Hash{head}, released)
+	if err != nil {
+		return BumpNone, err
+	}
+	defer walk.Close()
+
+	bump := BumpNone
+	err = walk.ForEach(func(c *object.Commit) error {
+		bump = max(bump, ConventionalBump(c.Message))
+		return nil
+	})
+	if err != nil {
+		return BumpNone, WrapError(err, "failed to walk commit history")
+	}
+
+	return bump, nil
+}
+
+// parseNumericIdentifier parses a version number without leading zeros.
+func parseNumericIdentifier(s string) (uint64, error) {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return 0, errors.New("invalid numeric identifier")
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0, errors.New("invalid numeric identifier")
+		}
+	}
+
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid numeric identifier: %w", err)
+	}
+
+	return n, nil
+}
+
+// validIdentifiers checks dot-separated pre-release or build identifiers.
+// Numeric pre-release identifiers must not have leading zeros.
+func validIdentifiers(s string, prerelease bool) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+
+		numeric := true
+		for _, c := range id {
+			switch {
+			case c >= '0' && c <= '9':
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+				numeric = false
+			default:
+				return false
+			}
+		}
+
+		if prerelease && numeric && len(id) > 1 && id[0] == '0' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// comparePrerelease compares pre-release strings by semver precedence.
+// A release (empty string) has higher precedence than any pre-release.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	aIDs, bIDs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		if c := compareIdentifier(aIDs[i], bIDs[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(aIDs) < len(bIDs):
+		return -1
+	case len(aIDs) > len(bIDs):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareIdentifier compares a single pre-release identifier. Numeric
+// identifiers compare numerically and sort before alphanumeric ones.
+func compareIdentifier(a, b string) int {
+	an, aErr := parseNumericIdentifier(a)
+	bn, bErr := parseNumericIdentifier(b)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		default:
+			return 0
+		}
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
@@ -0,0 +1,298 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitMessage creates an empty commit with the given message, returning its SHA
+func (tr *testRepo) commitMessage(t *testing.T, message string) string {
+	t.Helper()
+
+	sha, err := tr.repo.Commit(tr.ctx, message, Signature{
+		Name:  "Test",
+		Email: "test@example.com",
+		When:  time.Now(),
+	}, CommitOpts{AllowEmpty: true})
+	require.NoError(t, err)
+
+	return sha
+}
+
+// TestParseVersion tests parsing tag names as semantic versions
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    Version
+		wantErr bool
+	}{
+		{tag: "v1.2.3", want: Version{Prefix: "v", Major: 1, Minor: 2, Patch: 3}},
+		{tag: "1.2.3", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{tag: "oci/v0.10.0", want: Version{Prefix: "oci/v", Minor: 10}},
+		{tag: "tools/my-cli/2.0.0", want: Version{Prefix: "tools/my-cli/", Major: 2}},
+		{tag: "v1.3.0-rc.1", want: Version{Prefix: "v", Major: 1, Minor: 3, Prerelease: "rc.1"}},
+		{tag: "v1.0.0-alpha-2+build.5", want: Version{Prefix: "v", Major: 1, Prerelease: "alpha-2", Build: "build.5"}},
+		{tag: "v1.2", wantErr: true},
+		{tag: "v1.2.3.4", wantErr: true},
+		{tag: "v01.2.3", wantErr: true},
+		{tag: "v1.2.x", wantErr: true},
+		{tag: "v1.2.3-", wantErr: true},
+		{tag: "v1.2.3-rc.01", wantErr: true},
+		{tag: "v1.2.3+", wantErr: true},
+		{tag: "release-1.2.3", wantErr: true},
+		{tag: "latest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := ParseVersion(tt.tag)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidRef)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.tag, got.String())
+		})
+	}
+}
+
+// TestVersionCompare tests semver precedence ordering
+func TestVersionCompare(t *testing.T) {
+	// Each version has lower precedence than the next
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.2.0",
+		"2.0.0",
+	}
+
+	for i := 0; i < len(ordered)-1; i++ {
+		a, err := ParseVersion(ordered[i])
+		require.NoError(t, err)
+		b, err := ParseVersion(ordered[i+1])
+		require.NoError(t, err)
+
+		assert.Equal(t, -1, a.Compare(b), "%s < %s", a, b)
+		assert.Equal(t, 1, b.Compare(a), "%s > %s", b, a)
+	}
+
+	a, _ := ParseVersion("v1.0.0+build.1")
+	b, _ := ParseVersion("oci/v1.0.0")
+	assert.Equal(t, 0, a.Compare(b), "prefix and build metadata are ignored")
+}
+
+// TestVersionBump tests incrementing versions
+func TestVersionBump(t *testing.T) {
+	tests := []struct {
+		version string
+		bump    VersionBump
+		want    string
+	}{
+		{"v1.2.3", BumpPatch, "v1.2.4"},
+		{"v1.2.3", BumpMinor, "v1.3.0"},
+		{"v1.2.3", BumpMajor, "v2.0.0"},
+		{"v1.2.3+build", BumpNone, "v1.2.3+build"},
+		{"v1.2.3+build", BumpPatch, "v1.2.4"},
+		{"v1.3.0-rc.1", BumpPatch, "v1.3.0"},
+		{"v1.3.0-rc.1", BumpMinor, "v1.3.0"},
+		{"v1.3.0-rc.1", BumpMajor, "v2.0.0"},
+		{"v1.2.3-rc.1", BumpMinor, "v1.3.0"},
+		{"v2.0.0-rc.1", BumpMajor, "v2.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.bump.String(), func(t *testing.T) {
+			v, err := ParseVersion(tt.version)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, v.Bump(tt.bump).String())
+		})
+	}
+}
+
+// TestConventionalBump tests mapping commit messages to version bumps
+func TestConventionalBump(t *testing.T) {
+	tests := []struct {
+		message string
+		want    VersionBump
+	}{
+		{"feat: add login", BumpMinor},
+		{"feat(auth): add login", BumpMinor},
+		{"Feat: capitalized type", BumpMinor},
+		{"fix: handle nil", BumpPatch},
+		{"fix(parser): handle nil\n\nDetails here.", BumpPatch},
+		{"perf: faster diff", BumpPatch},
+		{"feat!: drop v1 API", BumpMajor},
+		{"refactor(core)!: rename package", BumpMajor},
+		{"fix: rename flag\n\nBREAKING CHANGE: --foo is now --bar", BumpMajor},
+		{"feat: new flag\n\nBREAKING-CHANGE: old flag removed", BumpMajor},
+		{"fix: rename flag\n\nBREAKING CHANGE: --foo is now --bar\n\nSigned-off-by: Jane Doe <jane@example.com>", BumpMajor},
+		{"chore: update deps", BumpNone},
+		{"docs: fix typo", BumpNone},
+		{"Update README", BumpNone},
+		{"Merge branch 'feature': fix", BumpNone},
+		{"feat(auth: missing paren", BumpNone},
+		{"", BumpNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			assert.Equal(t, tt.want, ConventionalBump(tt.message))
+		})
+	}
+}
+
+// TestVersionTags tests listing version tags by prefix
+func TestVersionTags(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+
+	for _, tag := range []string{"v1.10.0", "v1.2.0", "v1.9.0-rc.1", "oci/v3.0.0", "latest", "release-2"} {
+		require.NoError(t, tr.repo.CreateTag(tr.ctx, tag, "HEAD", "", false))
+	}
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v2.0.0", "HEAD", "Release 2.0.0", true))
+
+	versions, err := tr.repo.VersionTags(tr.ctx, VersionOpts{Prefix: "v"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.2.0", "v1.10.0", "v2.0.0"}, versionStrings(versions))
+
+	versions, err = tr.repo.VersionTags(tr.ctx, VersionOpts{Prefix: "v", IncludePrerelease: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.2.0", "v1.9.0-rc.1", "v1.10.0", "v2.0.0"}, versionStrings(versions))
+
+	versions, err = tr.repo.VersionTags(tr.ctx, VersionOpts{Prefix: "oci/v"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"oci/v3.0.0"}, versionStrings(versions))
+}
+
+// TestLatestVersion tests finding the highest reachable version tag
+func TestLatestVersion(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	opts := VersionOpts{Prefix: "v"}
+
+	_, err := tr.repo.LatestVersion(tr.ctx, "HEAD", opts)
+	require.ErrorIs(t, err, ErrNoVersion)
+
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v1.0.0", "HEAD", "", false))
+	tr.commitMessage(t, "feat: second")
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v1.1.0", "HEAD", "Release 1.1.0", true))
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "oci/v0.3.0", "HEAD", "", false))
+
+	// A higher version on another branch is not reachable from master
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "hotfix", true, false))
+	tr.commitMessage(t, "fix: on branch")
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v9.0.0", "HEAD", "", false))
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "master", false, false))
+
+	latest, err := tr.repo.LatestVersion(tr.ctx, "master", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", latest.String())
+
+	latest, err = tr.repo.LatestVersion(tr.ctx, "hotfix", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "v9.0.0", latest.String())
+
+	latest, err = tr.repo.LatestVersion(tr.ctx, "HEAD~1", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", latest.String())
+
+	latest, err = tr.repo.LatestVersion(tr.ctx, "master", VersionOpts{Prefix: "oci/v"})
+	require.NoError(t, err)
+	assert.Equal(t, "oci/v0.3.0", latest.String())
+
+	_, err = tr.repo.LatestVersion(tr.ctx, "nonexistent", opts)
+	require.ErrorIs(t, err, ErrResolveFailed)
+}
+
+// TestNextVersion tests computing the next version from conventional commits
+func TestNextVersion(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	opts := VersionOpts{Prefix: "oci/v"}
+
+	// Without releasable commits or tags there is no version
+	_, err := tr.repo.NextVersion(tr.ctx, "HEAD", opts)
+	require.ErrorIs(t, err, ErrNoVersion)
+
+	// The first release starts from 0.0.0
+	tr.commitMessage(t, "feat(oci): initial publisher")
+	next, err := tr.repo.NextVersion(tr.ctx, "HEAD", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "oci/v0.1.0", next.String())
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, next.String(), "HEAD", "", false))
+
+	// Nothing releasable since the tag returns the tag itself
+	tr.commitMessage(t, "chore: tidy")
+	next, err = tr.repo.NextVersion(tr.ctx, "HEAD", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "oci/v0.1.0", next.String())
+
+	tr.commitMessage(t, "fix: retry uploads")
+	next, err = tr.repo.NextVersion(tr.ctx, "HEAD", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "oci/v0.1.1", next.String())
+
+	tr.commitMessage(t, "feat: support digests")
+	next, err = tr.repo.NextVersion(tr.ctx, "HEAD", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "oci/v0.2.0", next.String())
+
+	tr.commitMessage(t, "refactor!: new config format")
+	next, err = tr.repo.NextVersion(tr.ctx, "HEAD", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "oci/v1.0.0", next.String())
+
+	// Tags with other prefixes do not affect the module
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v5.0.0", "HEAD", "", false))
+	next, err = tr.repo.NextVersion(tr.ctx, "HEAD", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "oci/v1.0.0", next.String())
+}
+
+// TestNextVersionShallow tests computing versions in a shallow clone
+func TestNextVersionShallow(t *testing.T) {
+	tr, _ := setupShallowClone(t, 2, map[string]int{"v0.1.0": 2},
+		"chore: init", "fix: first", "feat: released", "feat: new")
+
+	latest, err := tr.repo.LatestVersion(tr.ctx, "HEAD", VersionOpts{Prefix: "v"})
+	require.NoError(t, err)
+	assert.Equal(t, "v0.1.0", latest.String())
+
+	next, err := tr.repo.NextVersion(tr.ctx, "HEAD", VersionOpts{Prefix: "v"})
+	require.NoError(t, err)
+	assert.Equal(t, "v0.2.0", next.String())
+}
+
+// TestNextVersionPrerelease tests promoting a pre-release to a release
+func TestNextVersionPrerelease(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v1.2.0", "HEAD", "", false))
+	tr.commitMessage(t, "feat: new API")
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "v1.3.0-rc.1", "HEAD", "", false))
+	tr.commitMessage(t, "fix: follow-up")
+
+	next, err := tr.repo.NextVersion(tr.ctx, "HEAD", VersionOpts{Prefix: "v", IncludePrerelease: true})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.0", next.String())
+
+	next, err = tr.repo.NextVersion(tr.ctx, "HEAD", VersionOpts{Prefix: "v"})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.0", next.String(), "feature since v1.2.0 is a minor bump")
+}
+
+// versionStrings converts versions to their tag names
+func versionStrings(versions []Version) []string {
+	names := make([]string, len(versions))
+	for i, v := range versions {
+		names[i] = v.String()
+	}
+	return names
+}
@@ -0,0 +1,429 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains commit and tag signing and signature verification.
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/input-output-hk/catalyst-forge-libs/git/internal/sshsig"
+)
+
+// sshSignatureNamespace is the SSHSIG namespace git uses for commits and tags.
+const sshSignatureNamespace = "git"
+
+// Signer signs git objects. Sign receives the encoded object without its
+// signature and returns the armored signature to embed in it.
+// The method set matches go-git's Signer so implementations can be shared.
+type Signer interface {
+	Sign(message io.Reader) ([]byte, error)
+}
+
+// OpenPGPSigner signs objects with an OpenPGP private key, like gpg.format=openpgp.
+type OpenPGPSigner struct {
+	entity *openpgp.Entity
+}
+
+// NewOpenPGPSigner creates a signer from an entity with a decrypted private key.
+func NewOpenPGPSigner(entity *openpgp.Entity) (*OpenPGPSigner, error) {
+	if entity == nil || entity.PrivateKey == nil {
+		return nil, WrapError(ErrInvalidRef, "OpenPGP entity has no private key")
+	}
+
+	if entity.PrivateKey.Encrypted {
+		return nil, WrapError(ErrInvalidRef, "OpenPGP private key is encrypted")
+	}
+
+	return &OpenPGPSigner{entity: entity}, nil
+}
+
+// NewOpenPGPSignerFromArmored creates a signer from the first private key in an
+// ASCII-armored key ring. The passphrase is used to decrypt the key if needed.
+func NewOpenPGPSignerFromArmored(armoredKey []byte, passphrase string) (*OpenPGPSigner, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKey))
+	if err != nil {
+		return nil, WrapError(err, "failed to read OpenPGP key")
+	}
+
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			continue
+		}
+		if entity.PrivateKey.Encrypted {
+			if err := entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+				return nil, WrapError(err, "failed to decrypt OpenPGP key")
+			}
+		}
+		return NewOpenPGPSigner(entity)
+	}
+
+	return nil, WrapError(ErrInvalidRef, "no OpenPGP private key found")
+}
+
+// Sign returns an armored detached OpenPGP signature of the message.
+func (s *OpenPGPSigner) Sign(message io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, s.entity, message, nil); err != nil {
+		return nil, WrapError(err, "failed to create OpenPGP signature")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SSHSigner signs objects with an SSH private key, like gpg.format=ssh.
+type SSHSigner struct {
+	signer gossh.Signer
+}
+
+// NewSSHSigner creates a signer from an SSH signer (e.g., a key from an agent).
+func NewSSHSigner(signer gossh.Signer) *SSHSigner {
+	return &SSHSigner{signer: signer}
+}
+
+// NewSSHSignerFromPEM creates a signer from a PEM-encoded SSH private key.
+// The passphrase is only used if the key is encrypted.
+func NewSSHSignerFromPEM(pemBytes []byte, passphrase string) (*SSHSigner, error) {
+	var (
+		signer gossh.Signer
+		err    error
+	)
+	if passphrase != "" {
+		signer, err = gossh.ParsePrivateKeyWithPassphrase(pemBytes, []byte(passphrase))
+	} else {
+		signer, err = gossh.ParsePrivateKey(pemBytes)
+	}
+	if err != nil {
+		return nil, WrapError(err, "failed to parse SSH private key")
+	}
+
+	return NewSSHSigner(signer), nil
+}
+
+// Sign returns an armored SSH signature of the message in the "git" namespace.
+func (s *SSHSigner) Sign(message io.Reader) ([]byte, error) {
+	sig, err := sshsig.Sign(s.signer, message, sshSignatureNamespace)
+	if err != nil {
+		return nil, WrapError(err, "failed to create SSH signature")
+	}
+
+	return sig, nil
+}
+
+// SignatureFormat identifies the kind of signature on an object.
+type SignatureFormat string
+
+const (
+	// SignatureOpenPGP is an OpenPGP signature.
+	SignatureOpenPGP SignatureFormat = "openpgp"
+
+	// SignatureSSH is an SSH signature.
+	SignatureSSH SignatureFormat = "ssh"
+)
+
+// Verification describes a valid signature.
+type Verification struct {
+	// Format is the kind of signature.
+	Format SignatureFormat
+
+	// Signer identifies the key owner: the primary user ID of an OpenPGP key
+	// (e.g., "Jane Doe <jane@example.com>") or the principal of an SSH key.
+	Signer string
+
+	// KeyID is the key fingerprint: uppercase hex for OpenPGP keys,
+	// "SHA256:..." for SSH keys.
+	KeyID string
+}
+
+// Keyring holds the public keys trusted when verifying signatures.
+type Keyring struct {
+	openpgp openpgp.EntityList
+	ssh     []sshAllowedKey
+}
+
+// sshAllowedKey is a trusted SSH public key, its principals and the
+// restrictions from its allowed signers options.
+type sshAllowedKey struct {
+	principals  []string
+	key         gossh.PublicKey
+	namespaces  []string
+	validAfter  time.Time
+	validBefore time.Time
+}
+
+// allows reports whether the key may sign git objects at the given time.
+func (a sshAllowedKey) allows(when time.Time) bool {
+	if !a.validAfter.IsZero() && when.Before(a.validAfter) {
+		return false
+	}
+	if !a.validBefore.IsZero() && when.After(a.validBefore) {
+		return false
+	}
+	if len(a.namespaces) == 0 {
+		return true
+	}
+	for _, pattern := range a.namespaces {
+		if ok, _ := path.Match(pattern, sshSignatureNamespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// principal returns the principal matching the identity of the signed
+// object (principals may be patterns), or the first principal if none does.
+func (a sshAllowedKey) principal(identity string) string {
+	for _, pattern := range a.principals {
+		if ok, _ := path.Match(pattern, identity); ok {
+			return pattern
+		}
+	}
+	return a.principals[0]
+}
+
+// NewKeyring creates an empty keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{}
+}
+
+// AddOpenPGPArmored adds the public keys of an ASCII-armored key ring.
+func (k *Keyring) AddOpenPGPArmored(armored []byte) error {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armored))
+	if err != nil {
+		return WrapError(err, "failed to read OpenPGP key ring")
+	}
+
+	k.openpgp = append(k.openpgp, entities...)
+	return nil
+}
+
+// AddSSHKey trusts an SSH public key in authorized_keys format
+// (e.g., "ssh-ed25519 AAAA... comment") for the given principal.
+func (k *Keyring) AddSSHKey(principal string, authorizedKey []byte) error {
+	key, _, _, _, err := gossh.ParseAuthorizedKey(authorizedKey)
+	if err != nil {
+		return WrapError(err, "failed to parse SSH public key")
+	}
+
+	k.ssh = append(k.ssh, sshAllowedKey{principals: []string{principal}, key: key})
+	return nil
+}
+
+// AddSSHAllowedSigners adds the keys of a file in git's gpg.ssh.allowedSignersFile
+// format: one "principals [options] key-type base64-key [comment]" entry per line.
+// The namespaces, valid-after and valid-before options are enforced when
+// verifying; keys with other options, including cert-authority, are rejected.
+func (k *Keyring) AddSSHAllowedSigners(data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		principals, key, ok := strings.Cut(line, " ")
+		if !ok {
+			return WrapErrorf(ErrInvalidRef, "allowed signers line %d has no key", lineNum)
+		}
+
+		allowed, err := parseSSHAllowedKey(principals, strings.TrimSpace(key))
+		if err != nil {
+			return WrapErrorf(err, "allowed signers line %d", lineNum)
+		}
+		k.ssh = append(k.ssh, allowed)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return WrapError(err, "failed to read allowed signers")
+	}
+
+	return nil
+}
+
+// VerifyCommit checks the signature of the commit at rev against the keyring.
+// Returns ErrNotSigned if the commit has no signature and ErrInvalidSignature
+// if the signature does not match or was made by a key not in the keyring.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) VerifyCommit(ctx context.Context, rev string, keyring *Keyring) (*Verification, error) {
+	commit, err := r.commitOf(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	if commit.PGPSignature == "" {
+		return nil, WrapErrorf(ErrNotSigned, "commit %s", commit.Hash)
+	}
+
+	payload := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(payload); err != nil {
+		return nil, WrapError(err, "failed to encode commit")
+	}
+
+	return keyring.verify(commit.PGPSignature, payload, commit.Committer)
+}
+
+// VerifyTag checks the signature of an annotated tag against the keyring.
+// Returns ErrTagMissing if the tag does not exist, ErrNotSigned if it is a
+// lightweight or unsigned tag and ErrInvalidSignature if the signature does
+// not match or was made by a key not in the keyring.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) VerifyTag(ctx context.Context, name string, keyring *Keyring) (*Verification, error) {
+	ref, err := r.repo.Tag(name)
+	if err != nil {
+		return nil, WrapErrorf(ErrTagMissing, "tag %q", name)
+	}
+
+	tag, err := r.repo.TagObject(ref.Hash())
+	if err != nil {
+		return nil, WrapErrorf(ErrNotSigned, "tag %q is a lightweight tag", name)
+	}
+
+	if tag.PGPSignature == "" {
+		return nil, WrapErrorf(ErrNotSigned, "tag %q", name)
+	}
+
+	payload := &plumbing.MemoryObject{}
+	if err := tag.EncodeWithoutSignature(payload); err != nil {
+		return nil, WrapError(err, "failed to encode tag")
+	}
+
+	return keyring.verify(tag.PGPSignature, payload, tag.Tagger)
+}
+
+// verify checks an armored signature over an encoded object signed by who.
+func (k *Keyring) verify(signature string, payload *plumbing.MemoryObject, who object.Signature) (*Verification, error) {
+	if k == nil {
+		k = NewKeyring()
+	}
+
+	message, err := payload.Reader()
+	if err != nil {
+		return nil, WrapError(err, "failed to read object")
+	}
+
+	switch {
+	case strings.HasPrefix(signature, "-----BEGIN PGP SIGNATURE-----"):
+		return k.verifyOpenPGP(signature, message)
+	case sshsig.IsArmored([]byte(signature)):
+		return k.verifySSH(signature, message, who)
+	default:
+		return nil, WrapError(ErrInvalidSignature, "unsupported signature format")
+	}
+}
+
+// verifyOpenPGP checks an OpenPGP signature against the trusted OpenPGP keys.
+func (k *Keyring) verifyOpenPGP(signature string, message io.Reader) (*Verification, error) {
+	entity, err := openpgp.CheckArmoredDetachedSignature(k.openpgp, message, strings.NewReader(signature), nil)
+	if err != nil {
+		return nil, WrapErrorf(ErrInvalidSignature, "OpenPGP verification failed (%v)", err)
+	}
+
+	v := &Verification{
+		Format: SignatureOpenPGP,
+		KeyID:  fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint),
+	}
+	if id := entity.PrimaryIdentity(); id != nil {
+		v.Signer = id.Name
+	}
+
+	return v, nil
+}
+
+// verifySSH checks an SSH signature against the trusted SSH keys, which must
+// allow signing git objects at the time who signed.
+func (k *Keyring) verifySSH(signature string, message io.Reader, who object.Signature) (*Verification, error) {
+	pub, err := sshsig.Verify([]byte(signature), message, sshSignatureNamespace)
+	if err != nil {
+		return nil, WrapErrorf(ErrInvalidSignature, "SSH verification failed (%v)", err)
+	}
+
+	fingerprint := gossh.FingerprintSHA256(pub)
+	trusted := false
+	for _, allowed := range k.ssh {
+		if !bytes.Equal(allowed.key.Marshal(), pub.Marshal()) {
+			continue
+		}
+		trusted = true
+		if allowed.allows(who.When) {
+			return &Verification{
+				Format: SignatureSSH,
+				Signer: allowed.principal(who.Email),
+				KeyID:  fingerprint,
+			}, nil
+		}
+	}
+
+	if trusted {
+		return nil, WrapErrorf(ErrInvalidSignature,
+			"SSH key %s is not allowed to sign git objects at %s", fingerprint, who.When.Format(time.RFC3339))
+	}
+	return nil, WrapErrorf(ErrInvalidSignature, "SSH key %s is not trusted", fingerprint)
+}
+
+// parseSSHAllowedKey parses the "[options] key-type base64-key [comment]"
+// part of an allowed signers line for comma-separated principals.
+func parseSSHAllowedKey(principals, key string) (sshAllowedKey, error) {
+	pub, _, options, _, err := gossh.ParseAuthorizedKey([]byte(key))
+	if err != nil {
+		return sshAllowedKey{}, WrapError(err, "failed to parse SSH public key")
+	}
+
+	allowed := sshAllowedKey{principals: strings.Split(principals, ","), key: pub}
+	for _, option := range options {
+		name, value, _ := strings.Cut(option, "=")
+		value = strings.Trim(value, "\"")
+
+		switch strings.ToLower(name) {
+		case "namespaces":
+			allowed.namespaces = strings.Split(value, ",")
+		case "valid-after":
+			if allowed.validAfter, err = parseSSHTime(value); err != nil {
+				return sshAllowedKey{}, err
+			}
+		case "valid-before":
+			if allowed.validBefore, err = parseSSHTime(value); err != nil {
+				return sshAllowedKey{}, err
+			}
+		case "cert-authority":
+			return sshAllowedKey{}, WrapError(ErrInvalidRef, "certificate authorities are not supported")
+		default:
+			return sshAllowedKey{}, WrapErrorf(ErrInvalidRef, "unsupported option %q", name)
+		}
+	}
+
+	return allowed, nil
+}
+
+// parseSSHTime parses a valid-after/valid-before time ("YYYYMMDD[HHMM[SS]]"),
+// in UTC with a "Z" suffix and in local time otherwise, like ssh-keygen.
+func parseSSHTime(value string) (time.Time, error) {
+	loc := time.Local
+	if trimmed, ok := strings.CutSuffix(strings.ToUpper(value), "Z"); ok {
+		value, loc = trimmed, time.UTC
+	}
+
+	layouts := map[int]string{8: "20060102", 12: "200601021504", 14: "20060102150405"}
+	layout, ok := layouts[len(value)]
+	if !ok {
+		return time.Time{}, WrapErrorf(ErrInvalidRef, "invalid time %q", value)
+	}
+
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, WrapErrorf(ErrInvalidRef, "invalid time %q", value)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,246 @@
+package git
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// newTestOpenPGPEntity generates an OpenPGP key pair for tests
+func newTestOpenPGPEntity(t *testing.T) *openpgp.Entity {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("Release Bot", "", "release@example.com", nil)
+	require.NoError(t, err)
+	return entity
+}
+
+// armoredPublicKey exports the public part of an entity
+func armoredPublicKey(t *testing.T, entity *openpgp.Entity) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// newTestSSHKey generates an ed25519 key and returns its PEM private key and authorized_keys line
+func newTestSSHKey(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := gossh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+
+	sshPub, err := gossh.NewPublicKey(pub)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(block), gossh.MarshalAuthorizedKey(sshPub)
+}
+
+// commitSigned commits a file change with the given signer
+func (tr *testRepo) commitSigned(t *testing.T, signer Signer) string {
+	t.Helper()
+
+	tr.modifyTestFile(t, "signed change "+time.Now().String())
+	require.NoError(t, tr.repo.Add(tr.ctx, "test.txt"))
+
+	sha, err := tr.repo.Commit(tr.ctx, "signed commit", Signature{
+		Name:  "Release Bot",
+		Email: "release@example.com",
+		When:  time.Now(),
+	}, CommitOpts{Signer: signer})
+	require.NoError(t, err)
+	return sha
+}
+
+// TestOpenPGPCommitSigning tests signing commits with OpenPGP keys and verifying them
+func TestOpenPGPCommitSigning(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	entity := newTestOpenPGPEntity(t)
+
+	signer, err := NewOpenPGPSigner(entity)
+	require.NoError(t, err)
+	sha := tr.commitSigned(t, signer)
+
+	keyring := NewKeyring()
+	require.NoError(t, keyring.AddOpenPGPArmored(armoredPublicKey(t, entity)))
+
+	v, err := tr.repo.VerifyCommit(tr.ctx, sha, keyring)
+	require.NoError(t, err)
+	assert.Equal(t, SignatureOpenPGP, v.Format)
+	assert.Equal(t, "Release Bot <release@example.com>", v.Signer)
+	assert.Len(t, v.KeyID, 40)
+
+	// A keyring without the key rejects the signature
+	other := NewKeyring()
+	require.NoError(t, other.AddOpenPGPArmored(armoredPublicKey(t, newTestOpenPGPEntity(t))))
+	_, err = tr.repo.VerifyCommit(tr.ctx, sha, other)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	// The initial commit is unsigned
+	_, err = tr.repo.VerifyCommit(tr.ctx, "HEAD~1", keyring)
+	require.ErrorIs(t, err, ErrNotSigned)
+}
+
+// TestSSHCommitSigning tests signing commits with SSH keys and verifying them
+func TestSSHCommitSigning(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	privateKey, authorizedKey := newTestSSHKey(t)
+
+	signer, err := NewSSHSignerFromPEM(privateKey, "")
+	require.NoError(t, err)
+	sha := tr.commitSigned(t, signer)
+
+	keyring := NewKeyring()
+	allowed := "# release automation\nrelease@example.com " + string(authorizedKey)
+	require.NoError(t, keyring.AddSSHAllowedSigners([]byte(allowed)))
+
+	v, err := tr.repo.VerifyCommit(tr.ctx, sha, keyring)
+	require.NoError(t, err)
+	assert.Equal(t, SignatureSSH, v.Format)
+	assert.Equal(t, "release@example.com", v.Signer)
+	assert.Contains(t, v.KeyID, "SHA256:")
+
+	// An untrusted key is rejected even though the signature is valid
+	_, otherKey := newTestSSHKey(t)
+	other := NewKeyring()
+	require.NoError(t, other.AddSSHKey("someone@example.com", otherKey))
+	_, err = tr.repo.VerifyCommit(tr.ctx, sha, other)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+// TestSSHAllowedSignersOptions tests enforcing allowed signers options
+func TestSSHAllowedSignersOptions(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	privateKey, authorizedKey := newTestSSHKey(t)
+	signer, err := NewSSHSignerFromPEM(privateKey, "")
+	require.NoError(t, err)
+	sha := tr.commitSigned(t, signer)
+
+	past := time.Now().AddDate(0, 0, -2).UTC().Format("20060102") + "Z"
+	future := time.Now().AddDate(0, 0, 2).UTC().Format("20060102") + "Z"
+
+	tests := []struct {
+		name       string
+		principals string
+		options    string
+		signer     string
+		wantErr    bool
+	}{
+		{name: "git namespace", principals: "release@example.com", options: `namespaces="file,git"`, signer: "release@example.com"},
+		{name: "other namespace", principals: "release@example.com", options: `namespaces="file"`, wantErr: true},
+		{name: "within validity", principals: "release@example.com", options: `valid-after="` + past + `",valid-before="` + future + `"`, signer: "release@example.com"},
+		{name: "expired", principals: "release@example.com", options: `valid-before="` + past + `"`, wantErr: true},
+		{name: "not yet valid", principals: "release@example.com", options: `valid-after="` + future + `"`, wantErr: true},
+		{name: "matching principal", principals: "ci@example.com,*@example.com", signer: "*@example.com"},
+		{name: "first principal", principals: "ci@example.com,ops@example.com", signer: "ci@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := tt.principals + " "
+			if tt.options != "" {
+				line += tt.options + " "
+			}
+			keyring := NewKeyring()
+			require.NoError(t, keyring.AddSSHAllowedSigners(append([]byte(line), authorizedKey...)))
+
+			v, err := tr.repo.VerifyCommit(tr.ctx, sha, keyring)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidSignature)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.signer, v.Signer)
+		})
+	}
+
+	// Unsupported options are rejected rather than ignored
+	for _, options := range []string{"cert-authority", `no-touch-required`, `valid-before="2024"`} {
+		err := NewKeyring().AddSSHAllowedSigners(append([]byte("release@example.com "+options+" "), authorizedKey...))
+		require.ErrorIs(t, err, ErrInvalidRef, options)
+	}
+}
+
+// TestSignedTags tests creating and verifying signed annotated tags
+func TestSignedTags(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	privateKey, authorizedKey := newTestSSHKey(t)
+
+	signer, err := NewSSHSignerFromPEM(privateKey, "")
+	require.NoError(t, err)
+
+	err = tr.repo.CreateAnnotatedTag(tr.ctx, "v1.0.0", "HEAD", TagOpts{
+		Message: "Release v1.0.0",
+		Tagger:  &Signature{Name: "Release Bot", Email: "release@example.com", When: time.Now()},
+		Signer:  signer,
+	})
+	require.NoError(t, err)
+
+	keyring := NewKeyring()
+	require.NoError(t, keyring.AddSSHKey("release@example.com", authorizedKey))
+
+	v, err := tr.repo.VerifyTag(tr.ctx, "v1.0.0", keyring)
+	require.NoError(t, err)
+	assert.Equal(t, "release@example.com", v.Signer)
+
+	ref, err := tr.repo.repo.Tag("v1.0.0")
+	require.NoError(t, err)
+	tag, err := tr.repo.repo.TagObject(ref.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "Release Bot", tag.Tagger.Name)
+	assert.Equal(t, "Release v1.0.0\n", tag.Message)
+
+	err = tr.repo.CreateAnnotatedTag(tr.ctx, "v1.0.0", "HEAD", TagOpts{Message: "again"})
+	require.ErrorIs(t, err, ErrTagExists)
+
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "unsigned", "HEAD", "Unsigned", true))
+	_, err = tr.repo.VerifyTag(tr.ctx, "unsigned", keyring)
+	require.ErrorIs(t, err, ErrNotSigned)
+
+	require.NoError(t, tr.repo.CreateTag(tr.ctx, "lightweight", "HEAD", "", false))
+	_, err = tr.repo.VerifyTag(tr.ctx, "lightweight", keyring)
+	require.ErrorIs(t, err, ErrNotSigned)
+
+	_, err = tr.repo.VerifyTag(tr.ctx, "missing", keyring)
+	require.ErrorIs(t, err, ErrTagMissing)
+}
+
+// TestNewOpenPGPSignerFromArmored tests loading encrypted private keys
+func TestNewOpenPGPSignerFromArmored(t *testing.T) {
+	entity := newTestOpenPGPEntity(t)
+	require.NoError(t, entity.EncryptPrivateKeys([]byte("secret"), nil))
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivateWithoutSigning(w, nil))
+	require.NoError(t, w.Close())
+
+	_, err = NewOpenPGPSignerFromArmored(buf.Bytes(), "wrong")
+	require.Error(t, err)
+
+	signer, err := NewOpenPGPSignerFromArmored(buf.Bytes(), "secret")
+	require.NoError(t, err)
+	sig, err := signer.Sign(bytes.NewReader([]byte("payload")))
+	require.NoError(t, err)
+	assert.Contains(t, string(sig), "-----BEGIN PGP SIGNATURE-----")
+
+	_, err = NewOpenPGPSignerFromArmored(armoredPublicKey(t, newTestOpenPGPEntity(t)), "")
+	require.ErrorIs(t, err, ErrInvalidRef, "public keys cannot sign")
+}
@@ -0,0 +1,664 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains stash operations (push, list, apply, pop, drop).
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	gobilly "github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	// stashRef is the reference pointing to the newest stash entry.
+	stashRef = plumbing.ReferenceName("refs/stash")
+
+	// stashLog is the reflog holding every stash entry, relative to the git directory.
+	stashLog = "logs/refs/stash"
+)
+
+// StashOpts configures stash creation.
+type StashOpts struct {
+	// Message describes the stash. Defaults to "WIP on <branch>: <commit>".
+	Message string
+
+	// IncludeUntracked also stashes (and removes) untracked files.
+	// Ignored files are never stashed.
+	IncludeUntracked bool
+}
+
+// StashApplyOpts configures applying a stash entry.
+type StashApplyOpts struct {
+	// Index restores the staged changes to the index as well, like
+	// `git stash apply --index`. By default only files that were added
+	// are staged and every other change is left unstaged.
+	Index bool
+}
+
+// StashEntry is a single entry of the stash list.
+type StashEntry struct {
+	// Index is the position in the stash list; 0 is the newest entry (stash@{0}).
+	Index int
+
+	// Hash is the SHA of the stash commit.
+	Hash string
+
+	// Message is the stash description, e.g., "WIP on main: 1a2b3c4 subject"
+	// or "On main: message".
+	Message string
+
+	// When is the time the entry was created.
+	When time.Time
+}
+
+// Stash saves the local modifications of tracked files (and untracked files
+// with opts.IncludeUntracked) as a new stash entry and resets the working
+// tree and index to HEAD. It returns the SHA of the stash commit.
+//
+// Entries are stored like git: a commit on refs/stash whose parents are HEAD
+// and a commit of the index (plus a commit of the untracked files), with every
+// entry recorded in the refs/stash reflog, so the git CLI can read them.
+//
+// Returns ErrEmptyCommit if there are no local changes to stash.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Stash(ctx context.Context, who Signature, opts StashOpts) (string, error) {
+	if r.worktree == nil {
+		return "", WrapError(ErrInvalidRef, "cannot stash in bare repository")
+	}
+	if err := validateSignature(who); err != nil {
+		return "", err
+	}
+
+	head, headCommit, err := r.headCommit()
+	if err != nil {
+		return "", err
+	}
+
+	status, err := r.worktree.Status()
+	if err != nil {
+		return "", WrapError(err, "failed to get worktree status")
+	}
+
+	var tracked, untracked []string
+	for p, s := range status {
+		switch {
+		case s.Worktree == git.Untracked:
+			if opts.IncludeUntracked {
+				untracked = append(untracked, p)
+			}
+		case s.Staging != git.Unmodified || s.Worktree != git.Unmodified:
+			tracked = append(tracked, p)
+		}
+	}
+	if len(tracked) == 0 && len(untracked) == 0 {
+		return "", WrapError(ErrEmptyCommit, "no local changes to stash")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", WrapError(err, "stash cancelled")
+	}
+
+	branch := "(no branch)"
+	if head.Name().IsBranch() {
+		branch = head.Name().Short()
+	}
+	subject, _, _ := strings.Cut(headCommit.Message, "\n")
+	summary := fmt.Sprintf("%s: %s %s", branch, headCommit.Hash.String()[:7], subject)
+
+	sig := toObjectSignature(who)
+	commit := func(msg string, files map[string]treeFile, parents ...plumbing.Hash) (plumbing.Hash, error) {
+		tree, err := r.writeTree(files)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return r.writeCommit(&object.Commit{
+			Author:       sig,
+			Committer:    sig,
+			Message:      msg + "\n",
+			TreeHash:     tree,
+			ParentHashes: parents,
+		})
+	}
+
+	// Index commit
+	files, err := r.indexFiles()
+	if err != nil {
+		return "", err
+	}
+	indexHash, err := commit("index on "+summary, files, headCommit.Hash)
+	if err != nil {
+		return "", err
+	}
+
+	// Working tree commit, starting from the index
+	for _, p := range tracked {
+		f, ok, err := r.worktreeFile(p)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			files[p] = f
+		} else {
+			delete(files, p)
+		}
+	}
+	parents := []plumbing.Hash{headCommit.Hash, indexHash}
+
+	// Untracked files commit
+	if len(untracked) > 0 {
+		untrackedFiles := make(map[string]treeFile, len(untracked))
+		for _, p := range untracked {
+			f, ok, err := r.worktreeFile(p)
+			if err != nil {
+				return "", err
+			}
+			if ok {
+				untrackedFiles[p] = f
+			}
+		}
+		untrackedHash, err := commit("untracked files on "+summary, untrackedFiles)
+		if err != nil {
+			return "", err
+		}
+		parents = append(parents, untrackedHash)
+	}
+
+	msg := "WIP on " + summary
+	if opts.Message != "" {
+		msg = "On " + branch + ": " + opts.Message
+	}
+	hash, err := commit(msg, files, parents...)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.pushStash(hash, sig, msg); err != nil {
+		return "", err
+	}
+
+	// Clear the stashed changes. A hard reset is avoided as go-git's also
+	// deletes untracked files.
+	headFiles, err := r.commitFiles(headCommit)
+	if err != nil {
+		return "", err
+	}
+	stage := make(map[string]*treeFile, len(tracked))
+	for _, p := range tracked {
+		f, ok := headFiles[p]
+		stage[p] = sideOf(f, ok)
+	}
+	if err := r.checkoutFiles(files, headFiles); err != nil {
+		return "", err
+	}
+	if err := r.stageFiles(stage); err != nil {
+		return "", err
+	}
+	for _, p := range untracked {
+		if err := r.removeWorktreeFile(p); err != nil {
+			return "", err
+		}
+	}
+
+	return hash.String(), nil
+}
+
+// Stashes returns the stash list, newest entry first.
+// An empty list is returned if nothing is stashed.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Stashes(ctx context.Context) ([]StashEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, WrapError(err, "stash list cancelled")
+	}
+
+	log, err := r.readStashLog()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]StashEntry, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		entries = append(entries, StashEntry{
+			Index:   len(entries),
+			Hash:    log[i].newHash.String(),
+			Message: log[i].message,
+			When:    log[i].when,
+		})
+	}
+
+	return entries, nil
+}
+
+// StashApply applies the stash entry at index (0 is the newest) to the working
+// tree without removing it from the stash list. Changes made on top of a
+// different commit than the current HEAD are merged like a cherry-pick, and
+// stashed untracked files are restored.
+//
+// Returns ErrStashMissing if there is no entry at index, ErrDirtyWorktree if
+// tracked files have uncommitted changes or a stashed untracked file already
+// exists, and a *MergeConflictError (matching ErrMergeConflict) if the changes
+// conflict with HEAD. The working tree is left untouched on error.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) StashApply(ctx context.Context, index int, opts StashApplyOpts) error {
+	if r.worktree == nil {
+		return WrapError(ErrInvalidRef, "cannot apply stash in bare repository")
+	}
+
+	log, err := r.readStashLog()
+	if err != nil {
+		return err
+	}
+	pos, err := stashPosition(log, index)
+	if err != nil {
+		return err
+	}
+
+	stash, err := r.repo.CommitObject(log[pos].newHash)
+	if err != nil {
+		return WrapError(err, "failed to get stash commit")
+	}
+	if stash.NumParents() < 2 {
+		return WrapErrorf(ErrInvalidRef, "stash@{%d} is not a stash commit", index)
+	}
+	base, err := stash.Parent(0)
+	if err != nil {
+		return WrapError(err, "failed to get stash base commit")
+	}
+	staged, err := stash.Parent(1)
+	if err != nil {
+		return WrapError(err, "failed to get stash index commit")
+	}
+
+	if err := r.requireCleanWorktree(); err != nil {
+		return err
+	}
+	_, ours, err := r.headCommit()
+	if err != nil {
+		return err
+	}
+
+	// Stashed untracked files must not overwrite existing files
+	untracked := make(map[string]treeFile)
+	if stash.NumParents() > 2 {
+		parent, err := stash.Parent(2)
+		if err != nil {
+			return WrapError(err, "failed to get stash untracked commit")
+		}
+		if untracked, err = r.commitFiles(parent); err != nil {
+			return err
+		}
+		for p := range untracked {
+			if _, err := r.worktree.Filesystem.Lstat(p); err == nil {
+				return WrapErrorf(ErrDirtyWorktree, "untracked file %q already exists", p)
+			}
+		}
+	}
+
+	// Merge the stashed changes into HEAD
+	targetTree := stash.TreeHash
+	if ours.Hash != base.Hash {
+		if targetTree, err = r.mergeCommits(ctx, base, ours, stash); err != nil {
+			return err
+		}
+	}
+
+	oursFiles, err := r.commitFiles(ours)
+	if err != nil {
+		return err
+	}
+	tree, err := r.repo.TreeObject(targetTree)
+	if err != nil {
+		return WrapError(err, "failed to get merged tree")
+	}
+	targetFiles, err := treeFiles(tree)
+	if err != nil {
+		return err
+	}
+
+	// Files added by the stash must not overwrite untracked files
+	if err := r.checkUntrackedFiles(oursFiles, targetFiles); err != nil {
+		return err
+	}
+
+	// Decide what to stage before touching the working tree
+	stage := make(map[string]*treeFile)
+	if opts.Index {
+		baseFiles, err := r.commitFiles(base)
+		if err != nil {
+			return err
+		}
+		stagedFiles, err := r.commitFiles(staged)
+		if err != nil {
+			return err
+		}
+		for p := range unionKeys(baseFiles, stagedFiles) {
+			b, inBase := baseFiles[p]
+			s, inStaged := stagedFiles[p]
+			if inBase == inStaged && b == s {
+				continue
+			}
+			if o, inOurs := oursFiles[p]; inOurs != inBase || o != b {
+				return WrapErrorf(ErrMergeConflict, "staged changes to %q conflict with HEAD", p)
+			}
+			stage[p] = sideOf(s, inStaged)
+		}
+	} else {
+		for p, f := range targetFiles {
+			if _, ok := oursFiles[p]; !ok {
+				stage[p] = &f
+			}
+		}
+	}
+
+	if err := r.checkoutFiles(oursFiles, targetFiles); err != nil {
+		return err
+	}
+	for p, f := range untracked {
+		if err := r.writeWorktreeFile(p, f); err != nil {
+			return err
+		}
+	}
+
+	return r.stageFiles(stage)
+}
+
+// StashPop applies the stash entry at index like StashApply and then drops it.
+// The entry is kept if applying fails.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) StashPop(ctx context.Context, index int, opts StashApplyOpts) error {
+	if err := r.StashApply(ctx, index, opts); err != nil {
+		return err
+	}
+
+	return r.StashDrop(ctx, index)
+}
+
+// StashDrop removes the stash entry at index (0 is the newest) from the stash list.
+// Returns ErrStashMissing if there is no entry at index.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) StashDrop(ctx context.Context, index int) error {
+	if err := ctx.Err(); err != nil {
+		return WrapError(err, "stash drop cancelled")
+	}
+
+	log, err := r.readStashLog()
+	if err != nil {
+		return err
+	}
+	pos, err := stashPosition(log, index)
+	if err != nil {
+		return err
+	}
+
+	// Keep the chain of old/new hashes intact, like `git reflog delete --rewrite`
+	if pos+1 < len(log) {
+		if pos > 0 {
+			log[pos+1].oldHash = log[pos-1].newHash
+		} else {
+			log[pos+1].oldHash = plumbing.ZeroHash
+		}
+	}
+	log = append(log[:pos], log[pos+1:]...)
+
+	if len(log) == 0 {
+		if err := r.repo.Storer.RemoveReference(stashRef); err != nil {
+			return WrapError(err, "failed to remove stash reference")
+		}
+		dotGit, err := r.dotGit()
+		if err != nil {
+			return err
+		}
+		if err := dotGit.Remove(stashLog); err != nil && !os.IsNotExist(err) {
+			return WrapError(err, "failed to remove stash log")
+		}
+		return nil
+	}
+
+	if err := r.writeStashLog(log); err != nil {
+		return err
+	}
+	ref := plumbing.NewHashReference(stashRef, log[len(log)-1].newHash)
+	if err := r.repo.Storer.SetReference(ref); err != nil {
+		return WrapError(err, "failed to update stash reference")
+	}
+
+	return nil
+}
+
+// reflogEntry is a single line of a reflog file.
+type reflogEntry struct {
+	oldHash plumbing.Hash
+	newHash plumbing.Hash
+	name    string
+	email   string
+	when    time.Time
+	message string
+}
+
+// String formats the entry as a reflog line, without the trailing newline.
+func (e reflogEntry) String() string {
+	return fmt.Sprintf("%s %s %s <%s> %d %s\t%s",
+		e.oldHash, e.newHash, e.name, e.email, e.when.Unix(), e.when.Format("-0700"), e.message)
+}
+
+// parseReflogEntry parses a reflog line.
+func parseReflogEntry(line string) (reflogEntry, error) {
+	header, message, _ := strings.Cut(line, "\t")
+
+	hashes, who, ok := strings.Cut(header, " <")
+	if !ok || len(hashes) < 2*40+2 {
+		return reflogEntry{}, fmt.Errorf("malformed reflog line %q", line)
+	}
+	e := reflogEntry{
+		oldHash: plumbing.NewHash(hashes[:40]),
+		newHash: plumbing.NewHash(hashes[41:81]),
+		name:    strings.TrimSpace(hashes[81:]),
+		message: message,
+	}
+
+	email, stamp, ok := strings.Cut(who, "> ")
+	if !ok {
+		return reflogEntry{}, fmt.Errorf("malformed reflog line %q", line)
+	}
+	e.email = email
+
+	secs, zone, _ := strings.Cut(stamp, " ")
+	unix, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return reflogEntry{}, fmt.Errorf("malformed reflog timestamp %q", stamp)
+	}
+	e.when = time.Unix(unix, 0)
+	if tz, err := time.Parse("-0700", zone); err == nil {
+		e.when = e.when.In(tz.Location())
+	}
+
+	return e, nil
+}
+
+// pushStash records a new stash commit in refs/stash and its reflog.
+func (r *Repo) pushStash(hash plumbing.Hash, sig object.Signature, msg string) error {
+	log, err := r.readStashLog()
+	if err != nil {
+		return err
+	}
+
+	entry := reflogEntry{
+		oldHash: plumbing.ZeroHash,
+		newHash: hash,
+		name:    sig.Name,
+		email:   sig.Email,
+		when:    sig.When,
+		message: msg,
+	}
+	if len(log) > 0 {
+		entry.oldHash = log[len(log)-1].newHash
+	}
+	if entry.when.IsZero() {
+		entry.when = time.Now()
+	}
+
+	if err := r.writeStashLog(append(log, entry)); err != nil {
+		return err
+	}
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(stashRef, hash)); err != nil {
+		return WrapError(err, "failed to update stash reference")
+	}
+
+	return nil
+}
+
+// readStashLog returns the stash reflog entries, oldest first.
+func (r *Repo) readStashLog() ([]reflogEntry, error) {
+	dotGit, err := r.dotGit()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := dotGit.Open(stashLog)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, WrapError(err, "failed to open stash log")
+	}
+	defer f.Close()
+
+	var log []reflogEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		entry, err := parseReflogEntry(scanner.Text())
+		if err != nil {
+			return nil, WrapError(err, "failed to parse stash log")
+		}
+		log = append(log, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, WrapError(err, "failed to read stash log")
+	}
+
+	return log, nil
+}
+
+// writeStashLog replaces the stash reflog with entries, oldest first.
+func (r *Repo) writeStashLog(log []reflogEntry) error {
+	dotGit, err := r.dotGit()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range log {
+		buf.WriteString(entry.String())
+		buf.WriteByte('\n')
+	}
+
+	if err := dotGit.MkdirAll(path.Dir(stashLog), 0o755); err != nil {
+		return WrapError(err, "failed to create stash log directory")
+	}
+	if err := util.WriteFile(dotGit, stashLog, buf.Bytes(), 0o644); err != nil {
+		return WrapError(err, "failed to write stash log")
+	}
+
+	return nil
+}
+
+// dotGit returns the filesystem of the git directory backing the repository storage.
+func (r *Repo) dotGit() (gobilly.Filesystem, error) {
+	storage, ok := r.repo.Storer.(interface{ Filesystem() gobilly.Filesystem })
+	if !ok {
+		return nil, WrapError(ErrInvalidRef, "repository storage has no git directory")
+	}
+	return storage.Filesystem(), nil
+}
+
+// stashPosition converts a stash index (0 is the newest) to a position in
+// the reflog entries (oldest first).
+func stashPosition(log []reflogEntry, index int) (int, error) {
+	if index < 0 || index >= len(log) {
+		return 0, WrapErrorf(ErrStashMissing, "stash@{%d}", index)
+	}
+	return len(log) - 1 - index, nil
+}
+
+// indexFiles returns the entries of the index keyed by path.
+// Unresolved conflicts are rejected.
+func (r *Repo) indexFiles() (map[string]treeFile, error) {
+	files, conflicted, err := r.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicted) > 0 {
+		return nil, WrapErrorf(ErrMergeConflict, "unresolved conflict in %q", conflicted[0])
+	}
+
+	return files, nil
+}
+
+// worktreeFile stores the content of a working tree file as a blob.
+// It returns false if the file does not exist.
+func (r *Repo) worktreeFile(p string) (treeFile, bool, error) {
+	content, mode, ok, err := r.readWorktreeFile(p)
+	if err != nil || !ok {
+		return treeFile{}, false, err
+	}
+
+	hash, err := r.writeBlob(content)
+	if err != nil {
+		return treeFile{}, false, err
+	}
+
+	return treeFile{hash: hash, mode: mode}, true, nil
+}
+
+// readWorktreeFile returns the content and mode of a working tree file as
+// they would be stored in a blob. It returns false if the file does not exist.
+func (r *Repo) readWorktreeFile(p string) ([]byte, filemode.FileMode, bool, error) {
+	wfs := r.worktree.Filesystem
+
+	info, err := wfs.Lstat(p)
+	if os.IsNotExist(err) {
+		return nil, filemode.Empty, false, nil
+	}
+	if err != nil {
+		return nil, filemode.Empty, false, WrapErrorf(err, "failed to stat %q", p)
+	}
+
+	switch {
+	case info.IsDir():
+		return nil, filemode.Empty, false, nil
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := wfs.Readlink(p)
+		if err != nil {
+			return nil, filemode.Empty, false, WrapErrorf(err, "failed to read link %q", p)
+		}
+		return []byte(target), filemode.Symlink, true, nil
+	default:
+		content, err := util.ReadFile(wfs, p)
+		if err != nil {
+			return nil, filemode.Empty, false, WrapErrorf(err, "failed to read %q", p)
+		}
+		if info.Mode()&0o111 != 0 {
+			return content, filemode.Executable, true, nil
+		}
+		return content, filemode.Regular, true, nil
+	}
+}
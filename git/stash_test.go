@@ -0,0 +1,264 @@
+package git
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stashSignature is the identity used for stash commits in tests
+var stashSignature = Signature{Name: "Test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+
+// porcelain returns the status of the repository as porcelain lines
+func (tr *testRepo) porcelain(t *testing.T) []string {
+	t.Helper()
+
+	status, err := tr.repo.Status(tr.ctx, StatusOpts{})
+	require.NoError(t, err)
+
+	var lines []string
+	for _, e := range status.Entries {
+		lines = append(lines, e.String())
+	}
+	return lines
+}
+
+// readFile returns the content of a worktree file
+func (tr *testRepo) readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := tr.fs.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// TestStash tests stashing local changes and popping them back
+func TestStash(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	head, err := tr.repo.Resolve(tr.ctx, "HEAD")
+	require.NoError(t, err)
+
+	tr.modifyTestFile(t, "modified content")
+	require.NoError(t, tr.fs.WriteFile("added.txt", []byte("added"), 0o644))
+	require.NoError(t, tr.repo.Add(tr.ctx, "added.txt"))
+	require.NoError(t, tr.fs.WriteFile("scratch.txt", []byte("scratch"), 0o644))
+
+	hash, err := tr.repo.Stash(tr.ctx, stashSignature, StashOpts{})
+	require.NoError(t, err)
+
+	// Tracked changes are cleared, untracked files are left alone
+	assert.Equal(t, []string{"?? scratch.txt"}, tr.porcelain(t))
+	assert.Equal(t, "initial content", tr.readFile(t, "test.txt"))
+
+	// Stored in the git format
+	resolved, err := tr.repo.Resolve(tr.ctx, "refs/stash")
+	require.NoError(t, err)
+	assert.Equal(t, hash, resolved.Hash)
+
+	commit, err := tr.repo.repo.CommitObject(plumbing.NewHash(hash))
+	require.NoError(t, err)
+	require.Len(t, commit.ParentHashes, 2)
+	assert.Equal(t, head.Hash, commit.ParentHashes[0].String())
+
+	log, err := tr.fs.ReadFile(".git/logs/refs/stash")
+	require.NoError(t, err)
+	want := strings.Repeat("0", 40) + " " + hash + " Test <test@example.com> 1700000000 " +
+		stashSignature.When.Format("-0700") + "\tWIP on master: " + head.Hash[:7] + " Initial commit\n"
+	assert.Equal(t, want, string(log))
+
+	stashes, err := tr.repo.Stashes(tr.ctx)
+	require.NoError(t, err)
+	require.Len(t, stashes, 1)
+	assert.Equal(t, 0, stashes[0].Index)
+	assert.Equal(t, hash, stashes[0].Hash)
+	assert.Equal(t, "WIP on master: "+head.Hash[:7]+" Initial commit", stashes[0].Message)
+	assert.True(t, stashSignature.When.Equal(stashes[0].When))
+
+	require.NoError(t, tr.repo.StashPop(tr.ctx, 0, StashApplyOpts{}))
+
+	assert.Equal(t, []string{"A  added.txt", "?? scratch.txt", " M test.txt"}, tr.porcelain(t))
+	assert.Equal(t, "modified content", tr.readFile(t, "test.txt"))
+
+	stashes, err = tr.repo.Stashes(tr.ctx)
+	require.NoError(t, err)
+	assert.Empty(t, stashes)
+	_, err = tr.repo.Resolve(tr.ctx, "refs/stash")
+	require.ErrorIs(t, err, ErrResolveFailed)
+}
+
+// TestStashUntracked tests stashing and restoring untracked files
+func TestStashUntracked(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.fs.MkdirAll("tmp", 0o755))
+	require.NoError(t, tr.fs.WriteFile("tmp/scratch.txt", []byte("scratch"), 0o644))
+
+	_, err := tr.repo.Stash(tr.ctx, stashSignature, StashOpts{})
+	require.ErrorIs(t, err, ErrEmptyCommit, "untracked files are not stashed by default")
+
+	_, err = tr.repo.Stash(tr.ctx, stashSignature, StashOpts{IncludeUntracked: true, Message: "scratch work"})
+	require.NoError(t, err)
+	assert.Empty(t, tr.porcelain(t))
+	exists, err := tr.fs.Exists("tmp")
+	require.NoError(t, err)
+	assert.False(t, exists, "emptied directories are removed")
+
+	stashes, err := tr.repo.Stashes(tr.ctx)
+	require.NoError(t, err)
+	require.Len(t, stashes, 1)
+	assert.Equal(t, "On master: scratch work", stashes[0].Message)
+
+	// Existing untracked files are not overwritten
+	require.NoError(t, tr.fs.MkdirAll("tmp", 0o755))
+	require.NoError(t, tr.fs.WriteFile("tmp/scratch.txt", []byte("other"), 0o644))
+	err = tr.repo.StashApply(tr.ctx, 0, StashApplyOpts{})
+	require.ErrorIs(t, err, ErrDirtyWorktree)
+	require.NoError(t, tr.fs.Remove("tmp/scratch.txt"))
+
+	require.NoError(t, tr.repo.StashApply(tr.ctx, 0, StashApplyOpts{}))
+	assert.Equal(t, []string{"?? tmp/scratch.txt"}, tr.porcelain(t))
+	assert.Equal(t, "scratch", tr.readFile(t, "tmp/scratch.txt"))
+
+	stashes, err = tr.repo.Stashes(tr.ctx)
+	require.NoError(t, err)
+	assert.Len(t, stashes, 1, "apply keeps the entry")
+}
+
+// TestStashApplyNewFiles tests that stashed new files do not overwrite untracked files
+func TestStashApplyNewFiles(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.modifyTestFile(t, "modified content")
+	require.NoError(t, tr.fs.WriteFile("new.txt", []byte("stashed"), 0o644))
+	require.NoError(t, tr.repo.Add(tr.ctx, "new.txt"))
+
+	_, err := tr.repo.Stash(tr.ctx, stashSignature, StashOpts{})
+	require.NoError(t, err)
+	assert.Empty(t, tr.porcelain(t))
+
+	// Nothing is written when a new file would overwrite an untracked one
+	require.NoError(t, tr.fs.WriteFile("new.txt", []byte("untracked"), 0o644))
+	err = tr.repo.StashApply(tr.ctx, 0, StashApplyOpts{})
+	require.ErrorIs(t, err, ErrDirtyWorktree)
+	assert.Equal(t, "untracked", tr.readFile(t, "new.txt"))
+	assert.Equal(t, "initial content", tr.readFile(t, "test.txt"))
+	assert.Equal(t, []string{"?? new.txt"}, tr.porcelain(t))
+
+	require.NoError(t, tr.fs.Remove("new.txt"))
+	require.NoError(t, tr.repo.StashApply(tr.ctx, 0, StashApplyOpts{}))
+	assert.Equal(t, "stashed", tr.readFile(t, "new.txt"))
+	assert.Equal(t, "modified content", tr.readFile(t, "test.txt"))
+}
+
+// TestStashApplyIndex tests restoring staged and unstaged changes separately
+func TestStashApplyIndex(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.modifyTestFile(t, "staged content")
+	require.NoError(t, tr.repo.Add(tr.ctx, "test.txt"))
+	tr.modifyTestFile(t, "unstaged content")
+
+	_, err := tr.repo.Stash(tr.ctx, stashSignature, StashOpts{})
+	require.NoError(t, err)
+
+	require.NoError(t, tr.repo.StashApply(tr.ctx, 0, StashApplyOpts{Index: true}))
+	assert.Equal(t, []string{"MM test.txt"}, tr.porcelain(t))
+	assert.Equal(t, "unstaged content", tr.readFile(t, "test.txt"))
+
+	// Committing takes the staged version
+	sha, err := tr.repo.Commit(tr.ctx, "staged", stashSignature, CommitOpts{})
+	require.NoError(t, err)
+	data, err := tr.repo.ReadFileAt(tr.ctx, sha, "test.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "staged content", string(data))
+}
+
+// TestStashApplyMerge tests applying a stash on top of a newer commit
+func TestStashApplyMerge(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.commitFile(t, "file.txt", mergeBaseContent)
+
+	require.NoError(t, tr.fs.WriteFile("file.txt", []byte("ONE\ntwo\nthree\nfour\nfive\n"), 0o644))
+	_, err := tr.repo.Stash(tr.ctx, stashSignature, StashOpts{})
+	require.NoError(t, err)
+
+	tr.commitFile(t, "file.txt", "one\ntwo\nthree\nfour\nFIVE\n")
+
+	require.NoError(t, tr.repo.StashApply(tr.ctx, 0, StashApplyOpts{}))
+	assert.Equal(t, "ONE\ntwo\nthree\nfour\nFIVE\n", tr.readFile(t, "file.txt"))
+	assert.Equal(t, []string{" M file.txt"}, tr.porcelain(t))
+
+	// Conflicting changes leave the working tree untouched
+	tr.commitFile(t, "file.txt", "uno\ntwo\nthree\nfour\nFIVE\n")
+	err = tr.repo.StashApply(tr.ctx, 0, StashApplyOpts{})
+	var conflictErr *MergeConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, "file.txt", conflictErr.Conflicts[0].Path)
+	assert.Empty(t, tr.porcelain(t))
+}
+
+// TestStashDrop tests removing entries from the middle of the stash list
+func TestStashDrop(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+
+	var hashes []string
+	for _, content := range []string{"first", "second", "third"} {
+		tr.modifyTestFile(t, content)
+		hash, err := tr.repo.Stash(tr.ctx, stashSignature, StashOpts{Message: content})
+		require.NoError(t, err)
+		hashes = append(hashes, hash)
+	}
+
+	require.NoError(t, tr.repo.StashDrop(tr.ctx, 1))
+
+	stashes, err := tr.repo.Stashes(tr.ctx)
+	require.NoError(t, err)
+	require.Len(t, stashes, 2)
+	assert.Equal(t, "On master: third", stashes[0].Message)
+	assert.Equal(t, "On master: first", stashes[1].Message)
+	assert.Equal(t, 1, stashes[1].Index)
+
+	// The reflog chain skips the dropped entry
+	log, err := tr.repo.readStashLog()
+	require.NoError(t, err)
+	assert.Equal(t, hashes[0], log[1].oldHash.String())
+
+	require.NoError(t, tr.repo.StashPop(tr.ctx, 1, StashApplyOpts{}))
+	assert.Equal(t, "first", tr.readFile(t, "test.txt"))
+
+	resolved, err := tr.repo.Resolve(tr.ctx, "refs/stash")
+	require.NoError(t, err)
+	assert.Equal(t, hashes[2], resolved.Hash)
+
+	err = tr.repo.StashDrop(tr.ctx, 1)
+	require.ErrorIs(t, err, ErrStashMissing)
+	err = tr.repo.StashApply(tr.ctx, -1, StashApplyOpts{})
+	require.ErrorIs(t, err, ErrStashMissing)
+}
+
+// TestStashErrors tests stash preconditions
+func TestStashErrors(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+
+	_, err := tr.repo.Stash(tr.ctx, stashSignature, StashOpts{})
+	require.ErrorIs(t, err, ErrEmptyCommit)
+
+	_, err = tr.repo.Stash(tr.ctx, Signature{}, StashOpts{})
+	require.ErrorIs(t, err, ErrInvalidRef)
+
+	tr.modifyTestFile(t, "stashed")
+	_, err = tr.repo.Stash(tr.ctx, stashSignature, StashOpts{})
+	require.NoError(t, err)
+
+	tr.modifyTestFile(t, "dirty")
+	err = tr.repo.StashApply(tr.ctx, 0, StashApplyOpts{})
+	require.ErrorIs(t, err, ErrDirtyWorktree)
+
+	bare := setupTestRepo(t, true)
+	_, err = bare.repo.Stash(bare.ctx, stashSignature, StashOpts{})
+	require.ErrorIs(t, err, ErrInvalidRef)
+	stashes, err := bare.repo.Stashes(bare.ctx)
+	require.NoError(t, err)
+	assert.Empty(t, stashes)
+}
@@ -0,0 +1,393 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains working tree status operations.
+package git
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+)
+
+// StatusCode describes the state of a path in the index or worktree.
+// The values match the letters used by `git status --porcelain`.
+type StatusCode byte
+
+const (
+	// StatusUnmodified indicates the path has no changes.
+	StatusUnmodified StatusCode = ' '
+
+	// StatusUntracked indicates the path is not tracked.
+	StatusUntracked StatusCode = '?'
+
+	// StatusIgnored indicates the path is untracked and matched by an ignore pattern.
+	StatusIgnored StatusCode = '!'
+
+	// StatusModified indicates the path content changed.
+	StatusModified StatusCode = 'M'
+
+	// StatusAdded indicates the path is new.
+	StatusAdded StatusCode = 'A'
+
+	// StatusDeleted indicates the path was removed.
+	StatusDeleted StatusCode = 'D'
+
+	// StatusRenamed indicates the path was moved from StatusEntry.From.
+	StatusRenamed StatusCode = 'R'
+
+	// StatusUnmerged indicates the path has unresolved merge conflicts.
+	StatusUnmerged StatusCode = 'U'
+)
+
+// StatusEntry is the state of a single path, equivalent to one line of
+// `git status --porcelain`.
+type StatusEntry struct {
+	// Path is the path relative to the worktree root, using forward slashes.
+	Path string
+
+	// Index is the state of the path in the index compared to HEAD.
+	Index StatusCode
+
+	// Worktree is the state of the path in the worktree compared to the index.
+	Worktree StatusCode
+
+	// From is the original path of a renamed file, empty otherwise.
+	From string
+}
+
+// IsStaged reports whether the entry has changes staged for the next commit.
+func (e StatusEntry) IsStaged() bool {
+	return e.Index != StatusUnmodified && e.Index != StatusUntracked &&
+		e.Index != StatusIgnored && e.Index != StatusUnmerged
+}
+
+// IsUntracked reports whether the path is untracked and not ignored.
+func (e StatusEntry) IsUntracked() bool {
+	return e.Worktree == StatusUntracked
+}
+
+// IsConflicted reports whether the path has unresolved merge conflicts.
+func (e StatusEntry) IsConflicted() bool {
+	return e.Index == StatusUnmerged || e.Worktree == StatusUnmerged
+}
+
+// String formats the entry like a line of `git status --porcelain`.
+func (e StatusEntry) String() string {
+	if e.From != "" {
+		return string([]byte{byte(e.Index), byte(e.Worktree)}) + " " + e.From + " -> " + e.Path
+	}
+	return string([]byte{byte(e.Index), byte(e.Worktree)}) + " " + e.Path
+}
+
+// Status is the state of the working tree.
+type Status struct {
+	// Entries lists every path that differs from HEAD, sorted by path.
+	Entries []StatusEntry
+}
+
+// IsClean reports whether the working tree has no staged, unstaged, untracked
+// or conflicted changes. Ignored files do not affect cleanliness.
+func (s *Status) IsClean() bool {
+	for _, e := range s.Entries {
+		if e.Index != StatusIgnored {
+			return false
+		}
+	}
+	return true
+}
+
+// Staged returns the entries with changes staged for the next commit.
+func (s *Status) Staged() []StatusEntry {
+	return s.filter(StatusEntry.IsStaged)
+}
+
+// Unstaged returns the entries of tracked files with changes in the worktree.
+func (s *Status) Unstaged() []StatusEntry {
+	return s.filter(func(e StatusEntry) bool {
+		return e.Worktree == StatusModified || e.Worktree == StatusDeleted
+	})
+}
+
+// Untracked returns the entries of untracked, non-ignored files.
+func (s *Status) Untracked() []StatusEntry {
+	return s.filter(StatusEntry.IsUntracked)
+}
+
+// Conflicted returns the entries with unresolved merge conflicts.
+func (s *Status) Conflicted() []StatusEntry {
+	return s.filter(StatusEntry.IsConflicted)
+}
+
+// filter returns the entries matching the predicate.
+func (s *Status) filter(match func(StatusEntry) bool) []StatusEntry {
+	var entries []StatusEntry
+	for _, e := range s.Entries {
+		if match(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// StatusOpts controls which paths Status reports.
+// The zero value matches `git status --porcelain`.
+type StatusOpts struct {
+	// Paths limits the status to the given paths. A directory matches
+	// everything below it and glob patterns are supported. Empty means all.
+	Paths []string
+
+	// ExcludeUntracked omits untracked files (like --untracked-files=no).
+	ExcludeUntracked bool
+
+	// IncludeIgnored reports untracked files matched by .gitignore with
+	// StatusIgnored in both columns (like --ignored).
+	IncludeIgnored bool
+}
+
+// Status returns the state of every path in the working tree that differs
+// from HEAD, including staged, unstaged, untracked and conflicted paths.
+// Renames are detected for staged files whose content is unchanged.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Status(ctx context.Context, opts StatusOpts) (*Status, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, WrapError(err, "context cancelled")
+	}
+
+	if r.worktree == nil {
+		return nil, WrapError(ErrInvalidRef, "cannot get status of bare repository")
+	}
+
+	raw, err := r.worktree.Status()
+	if err != nil {
+		return nil, WrapError(err, "failed to get worktree status")
+	}
+
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return nil, WrapError(err, "failed to read index")
+	}
+
+	entries := make(map[string]*StatusEntry, len(raw))
+	for p, fs := range raw {
+		entries[p] = &StatusEntry{
+			Path:     p,
+			Index:    StatusCode(fs.Staging),
+			Worktree: StatusCode(fs.Worktree),
+		}
+	}
+
+	markConflicts(entries, idx)
+
+	if err := r.detectRenames(entries, idx); err != nil {
+		return nil, err
+	}
+
+	if opts.IncludeIgnored {
+		if err := r.addIgnored(ctx, entries, idx); err != nil {
+			return nil, err
+		}
+	}
+
+	status := &Status{}
+	for _, e := range entries {
+		if e.Index == StatusUnmodified && e.Worktree == StatusUnmodified {
+			continue
+		}
+		if opts.ExcludeUntracked && e.IsUntracked() {
+			continue
+		}
+		if !matchesStatusPaths(e, opts.Paths) {
+			continue
+		}
+		status.Entries = append(status.Entries, *e)
+	}
+
+	sort.Slice(status.Entries, func(i, j int) bool {
+		return status.Entries[i].Path < status.Entries[j].Path
+	})
+
+	return status, nil
+}
+
+// IsClean reports whether the working tree has no staged, unstaged,
+// untracked or conflicted changes.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) IsClean(ctx context.Context) (bool, error) {
+	status, err := r.Status(ctx, StatusOpts{})
+	if err != nil {
+		return false, err
+	}
+	return status.IsClean(), nil
+}
+
+// markConflicts marks paths with conflict stages in the index as unmerged.
+func markConflicts(entries map[string]*StatusEntry, idx *index.Index) {
+	for _, e := range idx.Entries {
+		// Normal entries have stage 0 (go-git's index.Merged constant is 1)
+		if e.Stage == 0 {
+			continue
+		}
+		entries[e.Name] = &StatusEntry{
+			Path:     e.Name,
+			Index:    StatusUnmerged,
+			Worktree: StatusUnmerged,
+		}
+	}
+}
+
+// detectRenames pairs staged additions with staged deletions of identical
+// content and reports them as renames.
+func (r *Repo) detectRenames(entries map[string]*StatusEntry, idx *index.Index) error {
+	var added []*StatusEntry
+	hasDeleted := false
+	for _, e := range entries {
+		switch e.Index {
+		case StatusDeleted:
+			hasDeleted = true
+		case StatusAdded:
+			added = append(added, e)
+		}
+	}
+	if len(added) == 0 || !hasDeleted {
+		return nil
+	}
+
+	// Deletions are relative to HEAD, so it must exist at this point
+	head, err := r.repo.Head()
+	if err != nil {
+		return WrapError(err, "failed to get HEAD reference")
+	}
+	commit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return WrapError(err, "failed to get HEAD commit")
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return WrapError(err, "failed to get HEAD tree")
+	}
+
+	deleted := make(map[plumbing.Hash]string)
+	for _, e := range entries {
+		if e.Index != StatusDeleted {
+			continue
+		}
+		file, err := tree.File(e.Path)
+		if err != nil {
+			continue
+		}
+		deleted[file.Hash] = e.Path
+	}
+
+	// Pair in path order so the result is deterministic
+	sort.Slice(added, func(i, j int) bool { return added[i].Path < added[j].Path })
+	for _, e := range added {
+		ie, err := idx.Entry(e.Path)
+		if err != nil {
+			continue
+		}
+		from, ok := deleted[ie.Hash]
+		if !ok {
+			continue
+		}
+		delete(deleted, ie.Hash)
+
+		e.Index = StatusRenamed
+		e.From = from
+		if src := entries[from]; src.Worktree == StatusUnmodified || src.Worktree == StatusDeleted {
+			delete(entries, from)
+		} else {
+			// The old path was recreated in the worktree
+			src.Index = StatusUnmodified
+			src.Worktree = StatusUntracked
+		}
+	}
+
+	return nil
+}
+
+// addIgnored adds every untracked file matched by an ignore pattern.
+func (r *Repo) addIgnored(ctx context.Context, entries map[string]*StatusEntry, idx *index.Index) error {
+	wfs := r.worktree.Filesystem
+
+	patterns, err := gitignore.ReadPatterns(wfs, nil)
+	if err != nil {
+		return WrapError(err, "failed to read ignore patterns")
+	}
+	patterns = append(patterns, r.worktree.Excludes...)
+	if len(patterns) == 0 {
+		return nil
+	}
+	matcher := gitignore.NewMatcher(patterns)
+
+	tracked := make(map[string]bool, len(idx.Entries))
+	for _, e := range idx.Entries {
+		tracked[e.Name] = true
+	}
+
+	err = util.Walk(wfs, "", func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if p == "" || p == "." {
+			return nil
+		}
+		p = strings.TrimPrefix(path.Clean(strings.ReplaceAll(p, "\\", "/")), "/")
+		if p == ".git" && info.IsDir() {
+			return filepath.SkipDir
+		}
+		if info.IsDir() || tracked[p] {
+			return nil
+		}
+		if matcher.Match(strings.Split(p, "/"), false) {
+			entries[p] = &StatusEntry{Path: p, Index: StatusIgnored, Worktree: StatusIgnored}
+		}
+		return nil
+	})
+	if err != nil {
+		return WrapError(err, "failed to list ignored files")
+	}
+
+	return nil
+}
+
+// matchesStatusPaths reports whether the entry (or its rename source) is
+// selected by the path filters.
+func matchesStatusPaths(e *StatusEntry, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		if matchesStatusPath(e.Path, p) || (e.From != "" && matchesStatusPath(e.From, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesStatusPath reports whether file matches a path, directory or glob.
+func matchesStatusPath(file, pattern string) bool {
+	pattern = strings.TrimSuffix(path.Clean(pattern), "/")
+	if pattern == "." || pattern == "" {
+		return true
+	}
+	if file == pattern || strings.HasPrefix(file, pattern+"/") {
+		return true
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, err := path.Match(pattern, file)
+		return err == nil && matched
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStatus tests the Status method for staged, unstaged and untracked paths
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, tr *testRepo)
+		opts     StatusOpts
+		expected []StatusEntry
+	}{
+		{
+			name:     "clean worktree",
+			setup:    func(t *testing.T, tr *testRepo) {},
+			expected: nil,
+		},
+		{
+			name: "modified and untracked files",
+			setup: func(t *testing.T, tr *testRepo) {
+				tr.modifyTestFile(t, "changed content")
+				require.NoError(t, tr.fs.WriteFile("new.txt", []byte("new"), 0o644))
+			},
+			expected: []StatusEntry{
+				{Path: "new.txt", Index: StatusUntracked, Worktree: StatusUntracked},
+				{Path: "test.txt", Index: StatusUnmodified, Worktree: StatusModified},
+			},
+		},
+		{
+			name: "staged addition with further changes",
+			setup: func(t *testing.T, tr *testRepo) {
+				require.NoError(t, tr.fs.WriteFile("new.txt", []byte("new"), 0o644))
+				require.NoError(t, tr.repo.Add(context.Background(), "new.txt"))
+				require.NoError(t, tr.fs.WriteFile("new.txt", []byte("newer"), 0o644))
+			},
+			expected: []StatusEntry{
+				{Path: "new.txt", Index: StatusAdded, Worktree: StatusModified},
+			},
+		},
+		{
+			name: "staged rename",
+			setup: func(t *testing.T, tr *testRepo) {
+				require.NoError(t, tr.fs.Rename("test.txt", "renamed.txt"))
+				require.NoError(t, tr.repo.Add(context.Background(), "renamed.txt"))
+				require.NoError(t, tr.repo.Remove(context.Background(), "test.txt"))
+			},
+			expected: []StatusEntry{
+				{Path: "renamed.txt", Index: StatusRenamed, Worktree: StatusUnmodified, From: "test.txt"},
+			},
+		},
+		{
+			name: "unstaged deletion",
+			setup: func(t *testing.T, tr *testRepo) {
+				require.NoError(t, tr.fs.Remove("test.txt"))
+			},
+			expected: []StatusEntry{
+				{Path: "test.txt", Index: StatusUnmodified, Worktree: StatusDeleted},
+			},
+		},
+		{
+			name: "exclude untracked",
+			setup: func(t *testing.T, tr *testRepo) {
+				require.NoError(t, tr.fs.WriteFile("new.txt", []byte("new"), 0o644))
+			},
+			opts:     StatusOpts{ExcludeUntracked: true},
+			expected: nil,
+		},
+		{
+			name: "path filters",
+			setup: func(t *testing.T, tr *testRepo) {
+				tr.modifyTestFile(t, "changed content")
+				require.NoError(t, tr.fs.MkdirAll("docs/api", 0o755))
+				require.NoError(t, tr.fs.WriteFile("docs/api/index.md", []byte("docs"), 0o644))
+				require.NoError(t, tr.fs.WriteFile("notes.md", []byte("notes"), 0o644))
+			},
+			opts: StatusOpts{Paths: []string{"docs", "*.md"}},
+			expected: []StatusEntry{
+				{Path: "docs/api/index.md", Index: StatusUntracked, Worktree: StatusUntracked},
+				{Path: "notes.md", Index: StatusUntracked, Worktree: StatusUntracked},
+			},
+		},
+		{
+			name: "ignored files",
+			setup: func(t *testing.T, tr *testRepo) {
+				require.NoError(t, tr.fs.WriteFile(".gitignore", []byte("*.log\nbuild/\n"), 0o644))
+				require.NoError(t, tr.fs.WriteFile("debug.log", []byte("log"), 0o644))
+				require.NoError(t, tr.fs.MkdirAll("build", 0o755))
+				require.NoError(t, tr.fs.WriteFile("build/out.bin", []byte("bin"), 0o644))
+			},
+			opts: StatusOpts{IncludeIgnored: true},
+			expected: []StatusEntry{
+				{Path: ".gitignore", Index: StatusUntracked, Worktree: StatusUntracked},
+				{Path: "build/out.bin", Index: StatusIgnored, Worktree: StatusIgnored},
+				{Path: "debug.log", Index: StatusIgnored, Worktree: StatusIgnored},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := setupTestRepoWithCommit(t)
+			tt.setup(t, tr)
+
+			status, err := tr.repo.Status(tr.ctx, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, status.Entries)
+		})
+	}
+}
+
+// TestStatusConflicts tests that unmerged index entries are reported as conflicts
+func TestStatusConflicts(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+
+	idx, err := tr.repo.repo.Storer.Index()
+	require.NoError(t, err)
+	entry, err := idx.Entry("test.txt")
+	require.NoError(t, err)
+
+	ours := *entry
+	ours.Stage = index.OurMode
+	theirs := *entry
+	theirs.Stage = index.TheirMode
+	idx.Entries = append(idx.Entries, &ours, &theirs)
+	require.NoError(t, tr.repo.repo.Storer.SetIndex(idx))
+
+	status, err := tr.repo.Status(tr.ctx, StatusOpts{})
+	require.NoError(t, err)
+
+	conflicted := status.Conflicted()
+	require.Len(t, conflicted, 1)
+	assert.Equal(t, "test.txt", conflicted[0].Path)
+	assert.Equal(t, "UU test.txt", conflicted[0].String())
+	assert.False(t, status.IsClean())
+}
+
+// TestIsClean tests the IsClean convenience method
+func TestIsClean(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+
+	clean, err := tr.repo.IsClean(tr.ctx)
+	require.NoError(t, err)
+	assert.True(t, clean)
+
+	require.NoError(t, tr.fs.WriteFile("untracked.txt", []byte("x"), 0o644))
+	clean, err = tr.repo.IsClean(tr.ctx)
+	require.NoError(t, err)
+	assert.False(t, clean, "untracked files make the tree dirty")
+
+	status, err := tr.repo.Status(tr.ctx, StatusOpts{})
+	require.NoError(t, err)
+	assert.Len(t, status.Untracked(), 1)
+	assert.Empty(t, status.Staged())
+	assert.Empty(t, status.Unstaged())
+}
+
+// TestStatusBareRepository tests that Status fails on bare repositories
+func TestStatusBareRepository(t *testing.T) {
+	tr := setupTestRepo(t, true)
+
+	_, err := tr.repo.Status(tr.ctx, StatusOpts{})
+	require.ErrorIs(t, err, ErrInvalidRef)
+}
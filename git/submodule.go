@@ -0,0 +1,334 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains submodule operations (list, update).
+package git
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// Submodule describes a submodule declared in .gitmodules.
+type Submodule struct {
+	// Name is the submodule name from .gitmodules.
+	Name string
+
+	// Path is the submodule path relative to the worktree root.
+	Path string
+
+	// URL is the submodule URL. Relative URLs are resolved against the
+	// origin remote once the submodule is initialized.
+	URL string
+
+	// Branch is the branch configured for the submodule, if any.
+	Branch string
+
+	// Recorded is the commit recorded for the submodule in the index,
+	// empty if the path is not recorded.
+	Recorded string
+
+	// Current is the commit checked out in the submodule, empty if it was
+	// never updated.
+	Current string
+
+	// Initialized indicates the submodule is registered in .git/config.
+	Initialized bool
+}
+
+// UpToDate reports whether the checked-out commit is the recorded commit.
+func (s Submodule) UpToDate() bool {
+	return s.Current != "" && s.Current == s.Recorded
+}
+
+// SubmoduleUpdateOpts configures UpdateSubmodules.
+type SubmoduleUpdateOpts struct {
+	// Paths limits the update to the submodules at these paths. Empty means all.
+	Paths []string
+
+	// Init initializes submodules that are not initialized yet.
+	// Uninitialized submodules are skipped otherwise.
+	Init bool
+
+	// Recursive also updates the submodules of the submodules.
+	Recursive bool
+
+	// Depth limits the fetch depth of submodules when > 0.
+	Depth int
+}
+
+// Submodules returns the submodules declared in .gitmodules with their
+// recorded and checked-out commits, sorted by path.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Submodules(ctx context.Context) ([]Submodule, error) {
+	if r.worktree == nil {
+		return nil, WrapError(ErrInvalidRef, "cannot list submodules in bare repository")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, WrapError(err, "context cancelled")
+	}
+
+	subs, err := r.worktree.Submodules()
+	if err != nil {
+		return nil, WrapError(err, "failed to read submodules")
+	}
+
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return nil, WrapError(err, "failed to read index")
+	}
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return nil, WrapError(err, "failed to read repository config")
+	}
+
+	result := make([]Submodule, 0, len(subs))
+	for _, sub := range subs {
+		c := sub.Config()
+		s := Submodule{Name: c.Name, Path: c.Path, URL: c.URL, Branch: c.Branch}
+		_, s.Initialized = cfg.Submodules[c.Name]
+
+		if e, err := idx.Entry(c.Path); err == nil {
+			s.Recorded = e.Hash.String()
+		} else if !errors.Is(err, index.ErrEntryNotFound) {
+			return nil, WrapErrorf(err, "failed to read index entry for %q", c.Path)
+		}
+
+		if s.Initialized {
+			if s.Current, err = r.submoduleHead(c.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		result = append(result, s)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+
+	return result, nil
+}
+
+// UpdateSubmodules checks out the recorded commit in each submodule, cloning
+// or fetching it first, like `git submodule update`. Authentication for each
+// submodule URL is resolved through the repository's AuthProvider, using the
+// URL registered in .git/config with relative URLs resolved against origin.
+//
+// Returns ErrResolveFailed if a path in opts.Paths is not a submodule.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) UpdateSubmodules(ctx context.Context, opts SubmoduleUpdateOpts) error {
+	if r.worktree == nil {
+		return WrapError(ErrInvalidRef, "cannot update submodules in bare repository")
+	}
+
+	subs, err := r.worktree.Submodules()
+	if err != nil {
+		return WrapError(err, "failed to read submodules")
+	}
+
+	for _, pattern := range opts.Paths {
+		matched := false
+		for _, sub := range subs {
+			matched = matched || matchesStatusPath(sub.Config().Path, pattern)
+		}
+		if !matched {
+			return WrapErrorf(ErrResolveFailed, "no submodule at %q", pattern)
+		}
+	}
+
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return WrapError(err, "failed to read repository config")
+	}
+
+	for _, sub := range subs {
+		c := sub.Config()
+		if len(opts.Paths) > 0 && !matchesAnyPath(c.Path, opts.Paths) {
+			continue
+		}
+		registered, initialized := cfg.Submodules[c.Name]
+		if !initialized && !opts.Init {
+			continue
+		}
+
+		url := c.URL
+		if initialized {
+			url = registered.URL
+		}
+		if url, err = r.resolveSubmoduleURL(url); err != nil {
+			return err
+		}
+
+		if !initialized {
+			// Register the resolved URL, as `git submodule init` does
+			cfg.Submodules[c.Name] = &config.Submodule{Name: c.Name, URL: url, Branch: c.Branch}
+			if err := r.saveConfig(cfg); err != nil {
+				return err
+			}
+			if sub, err = r.worktree.Submodule(c.Name); err != nil {
+				return WrapErrorf(err, "failed to read submodule %q", c.Path)
+			}
+		}
+
+		// Fetch from the URL the authentication is resolved for
+		if err := r.setSubmoduleOrigin(sub, url); err != nil {
+			return err
+		}
+
+		updateOpts := &git.SubmoduleUpdateOptions{
+			RecurseSubmodules: git.NoRecurseSubmodules,
+			Depth:             opts.Depth,
+		}
+		if r.options.Auth != nil {
+			authMethod, authErr := r.options.Auth.Method(url)
+			if authErr != nil {
+				return WrapErrorf(ErrAuthRequired, "failed to get authentication method for submodule %q", c.Path)
+			}
+			updateOpts.Auth = authMethod
+		}
+
+		if err := sub.UpdateContext(ctx, updateOpts); err != nil {
+			return WrapErrorf(err, "failed to update submodule %q", c.Path)
+		}
+
+		if opts.Recursive {
+			child, err := r.submoduleRepo(sub)
+			if err != nil {
+				return err
+			}
+			nested := opts
+			nested.Paths = nil
+			if err := child.UpdateSubmodules(ctx, nested); err != nil {
+				return WrapErrorf(err, "failed to update submodules of %q", c.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// setSubmoduleOrigin points the origin remote of a submodule repository at
+// url, initializing the repository if it does not exist yet. go-git only sets
+// origin when it creates the repository, resolving relative URLs against the
+// first remote, so a changed registered URL would otherwise not be fetched.
+func (r *Repo) setSubmoduleOrigin(sub *git.Submodule, url string) error {
+	c := sub.Config()
+	storer, err := r.repo.Storer.Module(c.Name)
+	if err != nil {
+		return WrapErrorf(err, "failed to open storage of submodule %q", c.Path)
+	}
+	worktree, err := r.worktree.Filesystem.Chroot(c.Path)
+	if err != nil {
+		return WrapErrorf(err, "failed to open worktree of submodule %q", c.Path)
+	}
+
+	repo, err := git.Open(storer, worktree)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		repo, err = git.Init(storer, worktree)
+	}
+	if err != nil {
+		return WrapErrorf(err, "failed to open submodule %q", c.Path)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return WrapErrorf(err, "failed to read config of submodule %q", c.Path)
+	}
+	origin, ok := cfg.Remotes[git.DefaultRemoteName]
+	if !ok {
+		_, err = repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{url}})
+		if err != nil {
+			return WrapErrorf(err, "failed to create origin of submodule %q", c.Path)
+		}
+		return nil
+	}
+	if len(origin.URLs) == 1 && origin.URLs[0] == url {
+		return nil
+	}
+
+	origin.URLs = []string{url}
+	if err := repo.SetConfig(cfg); err != nil {
+		return WrapErrorf(err, "failed to update origin of submodule %q", c.Path)
+	}
+
+	return nil
+}
+
+// submoduleRepo returns a Repo for an initialized submodule sharing the
+// filesystem and options of r.
+func (r *Repo) submoduleRepo(sub *git.Submodule) (*Repo, error) {
+	repo, err := sub.Repository()
+	if err != nil {
+		return nil, WrapErrorf(err, "failed to open submodule %q", sub.Config().Path)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, WrapErrorf(err, "failed to get worktree of submodule %q", sub.Config().Path)
+	}
+
+	opts := r.options
+	opts.Workdir = path.Join(r.options.Workdir, sub.Config().Path)
+
+	return &Repo{repo: repo, worktree: worktree, fs: r.fs, options: opts}, nil
+}
+
+// submoduleHead returns the commit checked out in a submodule, or empty if
+// it was never checked out.
+func (r *Repo) submoduleHead(name string) (string, error) {
+	s, err := r.repo.Storer.Module(name)
+	if err != nil {
+		return "", WrapErrorf(err, "failed to open submodule %q storage", name)
+	}
+
+	head, err := storer.ResolveReference(s, plumbing.HEAD)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", WrapErrorf(err, "failed to resolve HEAD of submodule %q", name)
+	}
+
+	return head.Hash().String(), nil
+}
+
+// resolveSubmoduleURL resolves a URL relative to the origin remote
+// ("../lib.git"), returning other URLs unchanged.
+func (r *Repo) resolveSubmoduleURL(url string) (string, error) {
+	if !strings.HasPrefix(url, "./") && !strings.HasPrefix(url, "../") {
+		return url, nil
+	}
+
+	origin, err := r.lookupRemote(DefaultRemoteName)
+	if err != nil {
+		return "", WrapErrorf(err, "cannot resolve relative submodule URL %q", url)
+	}
+
+	endpoint, err := transport.NewEndpoint(origin.URL)
+	if err != nil {
+		return "", WrapErrorf(err, "invalid origin URL %q", origin.URL)
+	}
+	endpoint.Path = path.Join(endpoint.Path, url)
+
+	return endpoint.String(), nil
+}
+
+// matchesAnyPath reports whether p matches one of the paths, directories or globs.
+func matchesAnyPath(p string, paths []string) bool {
+	for _, pattern := range paths {
+		if matchesStatusPath(p, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fsb "github.com/input-output-hk/catalyst-forge-libs/fs/billy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOSRepo initializes a repository on disk with a single committed file,
+// returning the repository and the commit SHA
+func newOSRepo(t *testing.T, dir, file string) (*Repo, string) {
+	t.Helper()
+
+	ctx := context.Background()
+	repo, err := Init(ctx, &Options{FS: fsb.NewOSFS(dir), Workdir: "."})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(file), 0o644))
+	require.NoError(t, repo.Add(ctx, file))
+	sha, err := repo.Commit(ctx, "add "+file, stashSignature, CommitOpts{})
+	require.NoError(t, err)
+
+	return repo, sha
+}
+
+// addSubmodule records a submodule at path pointing to commit and commits it
+func addSubmodule(t *testing.T, repo *Repo, dir, path, url, commit string) string {
+	t.Helper()
+
+	ctx := context.Background()
+	gitmodules := filepath.Join(dir, ".gitmodules")
+	existing, _ := os.ReadFile(gitmodules)
+	entry := "[submodule \"" + path + "\"]\n\tpath = " + path + "\n\turl = " + url + "\n"
+	require.NoError(t, os.WriteFile(gitmodules, append(existing, entry...), 0o644))
+	require.NoError(t, repo.Add(ctx, ".gitmodules"))
+
+	gitlink := treeFile{hash: plumbing.NewHash(commit), mode: filemode.Submodule}
+	require.NoError(t, repo.stageFiles(map[string]*treeFile{path: &gitlink}))
+
+	sha, err := repo.Commit(ctx, "add submodule "+path, stashSignature, CommitOpts{})
+	require.NoError(t, err)
+
+	return sha
+}
+
+// setupSubmoduleRepos creates an app repository with the lib submodule, which
+// itself has the inner submodule, both using URLs relative to their parent
+func setupSubmoduleRepos(t *testing.T) (base, libSHA, innerSHA string) {
+	t.Helper()
+
+	base = t.TempDir()
+	for _, name := range []string{"inner", "lib", "app"} {
+		require.NoError(t, os.Mkdir(filepath.Join(base, name), 0o755))
+	}
+
+	_, innerSHA = newOSRepo(t, filepath.Join(base, "inner"), "inner.txt")
+
+	lib, _ := newOSRepo(t, filepath.Join(base, "lib"), "lib.txt")
+	libSHA = addSubmodule(t, lib, filepath.Join(base, "lib"), "vendor/inner", "../inner", innerSHA)
+
+	app, _ := newOSRepo(t, filepath.Join(base, "app"), "app.txt")
+	addSubmodule(t, app, filepath.Join(base, "app"), "libs/lib", "../lib", libSHA)
+
+	return base, libSHA, innerSHA
+}
+
+// TestCloneRecurseSubmodules tests cloning with all submodules checked out
+func TestCloneRecurseSubmodules(t *testing.T) {
+	base, libSHA, _ := setupSubmoduleRepos(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	auth := &mockAuthProvider{}
+	repo, err := Clone(ctx, "file://"+filepath.Join(base, "app"), &Options{
+		FS:                fsb.NewOSFS(dir),
+		Auth:              auth,
+		RecurseSubmodules: true,
+	})
+	require.NoError(t, err)
+
+	for _, file := range []string{"app.txt", "libs/lib/lib.txt", "libs/lib/vendor/inner/inner.txt"} {
+		_, err := os.Stat(filepath.Join(dir, file))
+		require.NoError(t, err, "%s should be checked out", file)
+	}
+
+	// Each submodule URL is resolved against its parent's origin for auth
+	assert.Equal(t, []string{
+		"file://" + filepath.Join(base, "app"),
+		"file://" + filepath.Join(base, "lib"),
+		"file://" + filepath.Join(base, "inner"),
+	}, auth.urls)
+
+	subs, err := repo.Submodules(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, "libs/lib", subs[0].Path)
+	assert.Equal(t, "file://"+filepath.Join(base, "lib"), subs[0].URL)
+	assert.Equal(t, libSHA, subs[0].Recorded)
+	assert.True(t, subs[0].Initialized)
+	assert.True(t, subs[0].UpToDate())
+}
+
+// TestUpdateSubmodules tests listing and updating submodules step by step
+func TestUpdateSubmodules(t *testing.T) {
+	base, libSHA, _ := setupSubmoduleRepos(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	repo, err := Clone(ctx, "file://"+filepath.Join(base, "app"), &Options{FS: fsb.NewOSFS(dir)})
+	require.NoError(t, err)
+
+	subs, err := repo.Submodules(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, Submodule{Name: "libs/lib", Path: "libs/lib", URL: "../lib", Recorded: libSHA}, subs[0])
+	assert.False(t, subs[0].UpToDate())
+
+	// Uninitialized submodules are skipped without Init
+	require.NoError(t, repo.UpdateSubmodules(ctx, SubmoduleUpdateOpts{}))
+	_, err = os.Stat(filepath.Join(dir, "libs/lib/lib.txt"))
+	require.True(t, os.IsNotExist(err))
+
+	require.NoError(t, repo.UpdateSubmodules(ctx, SubmoduleUpdateOpts{Init: true, Paths: []string{"libs"}}))
+	_, err = os.Stat(filepath.Join(dir, "libs/lib/lib.txt"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "libs/lib/vendor/inner/inner.txt"))
+	require.True(t, os.IsNotExist(err), "nested submodules need Recursive")
+
+	subs, err = repo.Submodules(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, libSHA, subs[0].Current)
+	assert.True(t, subs[0].UpToDate())
+
+	// A newer recorded commit is fetched and checked out
+	libDir := filepath.Join(base, "lib")
+	require.NoError(t, os.WriteFile(filepath.Join(libDir, "lib.txt"), []byte("v2"), 0o644))
+	lib, err := Open(ctx, &Options{FS: fsb.NewOSFS(libDir)})
+	require.NoError(t, err)
+	require.NoError(t, lib.Add(ctx, "lib.txt"))
+	newSHA, err := lib.Commit(ctx, "lib v2", Signature{Name: "Test", Email: "test@example.com", When: time.Now()}, CommitOpts{})
+	require.NoError(t, err)
+
+	gitlink := treeFile{hash: plumbing.NewHash(newSHA), mode: filemode.Submodule}
+	require.NoError(t, repo.stageFiles(map[string]*treeFile{"libs/lib": &gitlink}))
+	subs, err = repo.Submodules(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, newSHA, subs[0].Recorded)
+	assert.False(t, subs[0].UpToDate())
+
+	require.NoError(t, repo.UpdateSubmodules(ctx, SubmoduleUpdateOpts{Recursive: true}))
+	data, err := os.ReadFile(filepath.Join(dir, "libs/lib/lib.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(data))
+	_, err = os.Stat(filepath.Join(dir, "libs/lib/vendor/inner/inner.txt"))
+	require.True(t, os.IsNotExist(err), "Recursive does not initialize nested submodules without Init")
+
+	require.NoError(t, repo.UpdateSubmodules(ctx, SubmoduleUpdateOpts{Init: true, Recursive: true}))
+	_, err = os.Stat(filepath.Join(dir, "libs/lib/vendor/inner/inner.txt"))
+	require.NoError(t, err)
+
+	err = repo.UpdateSubmodules(ctx, SubmoduleUpdateOpts{Paths: []string{"missing"}})
+	require.ErrorIs(t, err, ErrResolveFailed)
+}
+
+// TestCloneRecurseSubmodulesShallow tests that submodules are cloned with the same depth
+func TestCloneRecurseSubmodulesShallow(t *testing.T) {
+	base, _, _ := setupSubmoduleRepos(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	_, err := Clone(ctx, "file://"+filepath.Join(base, "app"), &Options{
+		FS:                fsb.NewOSFS(dir),
+		ShallowDepth:      1,
+		RecurseSubmodules: true,
+	})
+	require.NoError(t, err)
+
+	for _, shallow := range []string{".git/shallow", ".git/modules/libs/lib/shallow"} {
+		_, err := os.Stat(filepath.Join(dir, shallow))
+		require.NoError(t, err, "%s should exist", shallow)
+	}
+	_, err = os.Stat(filepath.Join(dir, "libs/lib/vendor/inner/inner.txt"))
+	require.NoError(t, err)
+}
+
+// TestUpdateSubmodulesRegisteredURL tests that the URL registered in
+// .git/config is used for authentication and that registering keeps push URLs
+func TestUpdateSubmodulesRegisteredURL(t *testing.T) {
+	base, _, _ := setupSubmoduleRepos(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	libURL := "file://" + filepath.Join(base, "lib")
+
+	auth := &mockAuthProvider{}
+	repo, err := Clone(ctx, "file://"+filepath.Join(base, "app"), &Options{FS: fsb.NewOSFS(dir), Auth: auth})
+	require.NoError(t, err)
+	origin, err := repo.Remote(ctx, DefaultRemoteName)
+	require.NoError(t, err)
+	origin.PushURLs = []string{"git@example.com:app.git"}
+	require.NoError(t, repo.UpdateRemote(ctx, *origin))
+
+	auth.urls = nil
+	require.NoError(t, repo.UpdateSubmodules(ctx, SubmoduleUpdateOpts{Init: true}))
+	assert.Equal(t, []string{libURL}, auth.urls)
+
+	data, err := os.ReadFile(filepath.Join(dir, ".git", "config"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\tpushurl = "), "config:\n%s", data)
+	assert.Contains(t, string(data), "\turl = "+libURL+"\n")
+
+	// A relative registered URL is resolved against origin as well
+	cfg, err := repo.repo.Config()
+	require.NoError(t, err)
+	cfg.Submodules["libs/lib"].URL = "../lib"
+	require.NoError(t, repo.saveConfig(cfg))
+
+	auth.urls = nil
+	require.NoError(t, repo.UpdateSubmodules(ctx, SubmoduleUpdateOpts{}))
+	assert.Equal(t, []string{libURL}, auth.urls)
+}
+
+// TestUpdateSubmodulesFetchURL tests that submodules are fetched from the
+// registered URL that authentication is resolved for after it changes
+func TestUpdateSubmodulesFetchURL(t *testing.T) {
+	base, _, _ := setupSubmoduleRepos(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	mirror := filepath.Join(base, "mirror")
+	mirrorURL := "file://" + mirror
+
+	_, err := Clone(ctx, "file://"+filepath.Join(base, "lib"), &Options{FS: fsb.NewOSFS(mirror)})
+	require.NoError(t, err)
+
+	auth := &mockAuthProvider{}
+	repo, err := Clone(ctx, "file://"+filepath.Join(base, "app"), &Options{FS: fsb.NewOSFS(dir), Auth: auth})
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateSubmodules(ctx, SubmoduleUpdateOpts{Init: true}))
+
+	// Point the registered URL at the mirror, like `git submodule set-url`
+	require.NoError(t, os.RemoveAll(filepath.Join(base, "lib")))
+	cfg, err := repo.repo.Config()
+	require.NoError(t, err)
+	cfg.Submodules["libs/lib"] = &config.Submodule{Name: "libs/lib", URL: mirrorURL}
+	require.NoError(t, repo.saveConfig(cfg))
+
+	auth.urls = nil
+	require.NoError(t, repo.UpdateSubmodules(ctx, SubmoduleUpdateOpts{}))
+	assert.Equal(t, []string{mirrorURL}, auth.urls)
+
+	subs, err := repo.Submodules(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.True(t, subs[0].UpToDate())
+
+	sub, err := repo.worktree.Submodule("libs/lib")
+	require.NoError(t, err)
+	child, err := sub.Repository()
+	require.NoError(t, err)
+	origin, err := child.Remote(DefaultRemoteName)
+	require.NoError(t, err)
+	assert.Equal(t, []string{mirrorURL}, origin.Config().URLs)
+}
+
+// TestSubmodulesNone tests repositories without submodules
+func TestSubmodulesNone(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+
+	subs, err := tr.repo.Submodules(tr.ctx)
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+	require.NoError(t, tr.repo.UpdateSubmodules(tr.ctx, SubmoduleUpdateOpts{Init: true}))
+
+	bare := setupTestRepo(t, true)
+	_, err = bare.repo.Submodules(bare.ctx)
+	require.ErrorIs(t, err, ErrInvalidRef)
+}
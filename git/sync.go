@@ -5,19 +5,24 @@ package git
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 )
 
 // MergeStrategy represents the different types of merge strategies.
-// Currently, only FastForwardOnly is supported by go-git.
 type MergeStrategy int8
 
 const (
 	// FastForwardOnly represents a merge strategy that only allows fast-forward merges.
 	// This will fail if a merge commit would be required.
 	FastForwardOnly MergeStrategy = iota
+
+	// ThreeWay represents a merge strategy that fast-forwards when possible and
+	// otherwise creates a merge commit from a three-way merge of the trees.
+	ThreeWay
 )
 
 // String returns a human-readable string representation of the MergeStrategy.
@@ -25,6 +30,8 @@ func (s MergeStrategy) String() string {
 	switch s {
 	case FastForwardOnly:
 		return "fast-forward-only"
+	case ThreeWay:
+		return "three-way"
 	default:
 		return "unknown"
 	}
@@ -80,6 +87,8 @@ func (r *Repo) Fetch(ctx context.Context, remote string, prune bool, depth int)
 
 // PullFFOnly performs a fast-forward only pull from the specified remote.
 // It fetches changes and updates the current branch only if it's a fast-forward merge.
+// If the current branch has an upstream configured, its remote is used when remote
+// is empty and its branch is pulled instead of the remote's HEAD.
 // Returns ErrNotFastForward if a merge commit would be required.
 // Returns ErrAlreadyUpToDate if there are no changes to pull.
 //
@@ -89,14 +98,20 @@ func (r *Repo) PullFFOnly(ctx context.Context, remote string) error {
 		return WrapError(ErrInvalidRef, "cannot pull in bare repository")
 	}
 
-	if remote == "" {
-		remote = DefaultRemoteName
+	_, upstream, err := r.currentUpstream(ctx, remote)
+	if err != nil {
+		return err
 	}
 
+	remote = upstreamRemote(remote, upstream)
+
 	// Prepare pull options with fast-forward only strategy
 	pullOpts := &git.PullOptions{
 		RemoteName: remote,
 	}
+	if upstream != nil {
+		pullOpts.ReferenceName = plumbing.NewBranchReferenceName(upstream.Branch)
+	}
 
 	// Set up authentication if available
 	if r.options.Auth != nil {
@@ -114,7 +129,7 @@ func (r *Repo) PullFFOnly(ctx context.Context, remote string) error {
 	}
 
 	// Perform the pull
-	err := r.worktree.Pull(pullOpts)
+	err = r.worktree.Pull(pullOpts)
 	if err != nil {
 		// Check for specific error types
 		if errors.Is(err, git.ErrRemoteNotFound) {
@@ -134,7 +149,8 @@ func (r *Repo) PullFFOnly(ctx context.Context, remote string) error {
 
 // FetchAndMerge fetches changes from the specified remote and merges the fromRef.
 // It supports different merge strategies as specified by the strategy parameter.
-// Currently, only FastForwardOnly is supported by go-git.
+// The ThreeWay strategy records merge commits with the user.name and user.email
+// from the repository config and reports conflicts like Merge.
 //
 // Context timeout/cancellation is honored during the operation.
 func (r *Repo) FetchAndMerge(ctx context.Context, remote, fromRef string, strategy MergeStrategy) error {
@@ -154,6 +170,10 @@ func (r *Repo) FetchAndMerge(ctx context.Context, remote, fromRef string, strate
 		return WrapError(ErrResolveFailed, "failed to resolve fromRef for merge")
 	}
 
+	if strategy == ThreeWay {
+		return r.mergeWithConfigIdentity(ctx, fromRef)
+	}
+
 	ref := plumbing.NewHashReference("", *hash)
 
 	// Prepare merge options
@@ -184,13 +204,29 @@ func (r *Repo) FetchAndMerge(ctx context.Context, remote, fromRef string, strate
 
 // Push pushes the current branch to the specified remote.
 // It supports force pushing when force is true.
+// If the current branch has an upstream configured, its remote is used when remote
+// is empty and the branch is pushed to the upstream branch. Otherwise the remote's
+// push refspecs (remote.<name>.push) are used when configured, and the current
+// branch is pushed to the branch of the same name when they are not.
+// If the remote has push URLs, the push goes to each of them in order.
 // Returns ErrNotFastForward if the push would overwrite remote changes and force is false.
 // Returns ErrAlreadyUpToDate if there are no changes to push.
 //
 // Context timeout/cancellation is honored during the push operation.
 func (r *Repo) Push(ctx context.Context, remote string, force bool) error {
-	if remote == "" {
-		remote = DefaultRemoteName
+	branch, upstream, err := r.currentUpstream(ctx, remote)
+	if err != nil {
+		return err
+	}
+
+	remote = upstreamRemote(remote, upstream)
+
+	rem, err := r.lookupRemote(remote)
+	if err != nil {
+		return err
+	}
+	if rem == nil {
+		return WrapError(ErrResolveFailed, "remote not found")
 	}
 
 	// Prepare push options
@@ -198,16 +234,62 @@ func (r *Repo) Push(ctx context.Context, remote string, force bool) error {
 		RemoteName: remote,
 		Force:      force,
 	}
+	switch {
+	case upstream != nil:
+		spec := plumbing.NewBranchReferenceName(branch).String() + ":" +
+			plumbing.NewBranchReferenceName(upstream.Branch).String()
+		if force {
+			spec = "+" + spec
+		}
+		pushOpts.RefSpecs = []config.RefSpec{config.RefSpec(spec)}
+	case len(rem.Push) > 0:
+		for _, spec := range rem.Push {
+			pushOpts.RefSpecs = append(pushOpts.RefSpecs, config.RefSpec(spec))
+		}
+	case branch != "":
+		// Without refspecs go-git would push every branch
+		spec := plumbing.NewBranchReferenceName(branch).String() + ":" +
+			plumbing.NewBranchReferenceName(branch).String()
+		if force {
+			spec = "+" + spec
+		}
+		pushOpts.RefSpecs = []config.RefSpec{config.RefSpec(spec)}
+	default:
+		return WrapError(ErrInvalidRef, "cannot push detached HEAD without push refspecs")
+	}
 
-	// Set up authentication if available
-	if r.options.Auth != nil {
-		// Get the remote URL to determine auth method
-		remoteConfig, err := r.repo.Remote(remote)
+	urls := rem.PushURLs
+	if len(urls) == 0 {
+		urls = []string{rem.URL}
+	}
+
+	upToDate := true
+	for _, url := range urls {
+		err := r.pushTo(ctx, pushOpts, url)
+		if errors.Is(err, ErrAlreadyUpToDate) {
+			continue
+		}
 		if err != nil {
-			return WrapError(err, "failed to get remote configuration")
+			return err
 		}
+		upToDate = false
+	}
 
-		authMethod, authErr := r.options.Auth.Method(remoteConfig.Config().URLs[0])
+	if upToDate {
+		return ErrAlreadyUpToDate
+	}
+
+	return nil
+}
+
+// pushTo performs a push to a single URL of the remote in opts.
+func (r *Repo) pushTo(ctx context.Context, opts *git.PushOptions, url string) error {
+	pushOpts := *opts
+	pushOpts.RemoteURL = url
+
+	// Set up authentication if available
+	if r.options.Auth != nil {
+		authMethod, authErr := r.options.Auth.Method(url)
 		if authErr != nil {
 			return WrapError(ErrAuthRequired, "failed to get authentication method")
 		}
@@ -215,7 +297,7 @@ func (r *Repo) Push(ctx context.Context, remote string, force bool) error {
 	}
 
 	// Perform the push
-	err := r.repo.Push(pushOpts)
+	err := r.repo.PushContext(ctx, &pushOpts)
 	if err != nil {
 		// Check for specific error types
 		if errors.Is(err, git.ErrRemoteNotFound) {
@@ -227,8 +309,42 @@ func (r *Repo) Push(ctx context.Context, remote string, force bool) error {
 		if errors.Is(err, git.ErrNonFastForwardUpdate) {
 			return ErrNotFastForward
 		}
-		return WrapError(err, "failed to push to remote")
+		return WrapErrorf(err, "failed to push to %s", url)
 	}
 
 	return nil
 }
+
+// mergeWithConfigIdentity merges fromRef using the committer identity from the
+// repository config. A revision that is already merged is not an error.
+func (r *Repo) mergeWithConfigIdentity(ctx context.Context, fromRef string) error {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return WrapError(err, "failed to read repository config")
+	}
+
+	who := Signature{Name: cfg.User.Name, Email: cfg.User.Email, When: time.Now()}
+	if who.Name == "" || who.Email == "" {
+		return WrapError(ErrInvalidRef, "user.name and user.email must be configured for merge commits")
+	}
+
+	_, err = r.Merge(ctx, fromRef, who, MergeOpts{})
+	if errors.Is(err, ErrAlreadyUpToDate) {
+		return nil
+	}
+
+	return err
+}
+
+// upstreamRemote returns the remote to use for an operation: the explicit
+// remote if given, otherwise the upstream's remote, otherwise the default.
+func upstreamRemote(remote string, upstream *Upstream) string {
+	switch {
+	case remote != "":
+		return remote
+	case upstream != nil:
+		return upstream.Remote
+	default:
+		return DefaultRemoteName
+	}
+}
@@ -274,6 +274,7 @@ func TestMergeStrategy_String(t *testing.T) {
 		expected string
 	}{
 		{FastForwardOnly, "fast-forward-only"},
+		{ThreeWay, "three-way"},
 		{MergeStrategy(99), "unknown"},
 	}
 
@@ -7,7 +7,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
@@ -48,18 +47,9 @@ func (r *Repo) CreateTag(ctx context.Context, name, target, message string, anno
 	// Create the tag based on type
 	if annotated && message != "" {
 		// Create annotated tag
-		tagOpts := &git.CreateTagOptions{
-			Tagger: &object.Signature{
-				Name:  "git-wrapper", // Default tagger - could be made configurable
-				Email: "git@catalyst-forge-libs",
-				When:  time.Now(),
-			},
-			Message: message,
-		}
-
-		_, err = r.repo.CreateTag(name, *hash, tagOpts)
+		err = r.writeAnnotatedTag(name, *hash, TagOpts{Message: message})
 		if err != nil {
-			return WrapError(err, "failed to create annotated tag")
+			return err
 		}
 	} else {
 		// Create lightweight tag
@@ -73,6 +63,107 @@ func (r *Repo) CreateTag(ctx context.Context, name, target, message string, anno
 	return nil
 }
 
+// TagOpts configures annotated tag creation.
+type TagOpts struct {
+	// Message is the tag annotation. It is required.
+	Message string
+
+	// Tagger identifies who created the tag.
+	// Defaults to the library's generic tagger identity.
+	Tagger *Signature
+
+	// Signer signs the tag (e.g., an OpenPGPSigner or SSHSigner).
+	// A nil value creates an unsigned tag.
+	Signer Signer
+}
+
+// CreateAnnotatedTag creates an annotated tag at the specified target revision,
+// optionally signed. Returns ErrTagExists if the tag already exists.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) CreateAnnotatedTag(ctx context.Context, name, target string, opts TagOpts) error {
+	if name == "" {
+		return WrapError(ErrInvalidRef, "tag name cannot be empty")
+	}
+
+	if target == "" {
+		return WrapError(ErrInvalidRef, "target revision cannot be empty")
+	}
+
+	if strings.TrimSpace(opts.Message) == "" {
+		return WrapError(ErrInvalidRef, "annotated tag message cannot be empty")
+	}
+
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(target))
+	if err != nil {
+		return WrapError(ErrResolveFailed, "failed to resolve target revision")
+	}
+
+	if _, err := r.repo.Reference(plumbing.NewTagReferenceName(name), true); err == nil {
+		return WrapError(ErrTagExists, "tag already exists")
+	}
+
+	return r.writeAnnotatedTag(name, *hash, opts)
+}
+
+// writeAnnotatedTag stores a tag object for target and points the tag reference at it.
+func (r *Repo) writeAnnotatedTag(name string, target plumbing.Hash, opts TagOpts) error {
+	targetObj, err := r.repo.Storer.EncodedObject(plumbing.AnyObject, target)
+	if err != nil {
+		return WrapError(err, "failed to get tag target")
+	}
+
+	tagger := object.Signature{
+		Name:  "git-wrapper", // Default tagger when none is provided
+		Email: "git@catalyst-forge-libs",
+		When:  time.Now(),
+	}
+	if opts.Tagger != nil {
+		tagger = toObjectSignature(*opts.Tagger)
+	}
+
+	tag := &object.Tag{
+		Name:       name,
+		Tagger:     tagger,
+		Message:    strings.TrimSpace(opts.Message) + "\n",
+		TargetType: targetObj.Type(),
+		Target:     target,
+	}
+
+	if opts.Signer != nil {
+		payload := &plumbing.MemoryObject{}
+		if err := tag.EncodeWithoutSignature(payload); err != nil {
+			return WrapError(err, "failed to encode tag")
+		}
+		reader, err := payload.Reader()
+		if err != nil {
+			return WrapError(err, "failed to encode tag")
+		}
+		sig, err := opts.Signer.Sign(reader)
+		if err != nil {
+			return WrapError(err, "failed to sign tag")
+		}
+		tag.PGPSignature = string(sig)
+	}
+
+	obj := r.repo.Storer.NewEncodedObject()
+	if err := tag.Encode(obj); err != nil {
+		return WrapError(err, "failed to encode tag")
+	}
+
+	tagHash, err := r.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return WrapError(err, "failed to create annotated tag")
+	}
+
+	ref := plumbing.NewHashReference(plumbing.NewTagReferenceName(name), tagHash)
+	if err := r.repo.Storer.SetReference(ref); err != nil {
+		return WrapError(err, "failed to create annotated tag")
+	}
+
+	return nil
+}
+
 // DeleteTag deletes the specified tag from the repository.
 // Returns ErrTagMissing if the tag does not exist.
 //
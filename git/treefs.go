@@ -0,0 +1,457 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains read-only access to files and trees at arbitrary revisions.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/input-output-hk/catalyst-forge-libs/fs"
+)
+
+// TreeEntry describes a file or directory in a commit tree.
+type TreeEntry struct {
+	// Name is the base name of the entry.
+	Name string
+
+	// Path is the slash-separated path from the repository root.
+	Path string
+
+	// Mode is the entry mode: a directory (including submodules), a regular
+	// or executable file, or a symbolic link.
+	Mode os.FileMode
+
+	// Size is the size in bytes of a file or link target, 0 for directories.
+	Size int64
+
+	// Hash is the object hash (the commit hash for submodules).
+	Hash string
+
+	// Submodule is true for submodule entries.
+	Submodule bool
+}
+
+// ReadFileAt returns the contents of a file at the given revision without
+// touching the worktree. Returns an error matching os.ErrNotExist if the path
+// does not exist at rev.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) ReadFileAt(ctx context.Context, rev, name string) ([]byte, error) {
+	tree, err := r.FSAt(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	return tree.ReadFile(name)
+}
+
+// ListTreeAt returns the entries of a directory at the given revision, sorted
+// by name. An empty dir lists the repository root. Returns an error matching
+// os.ErrNotExist if the directory does not exist at rev.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) ListTreeAt(ctx context.Context, rev, dir string) ([]TreeEntry, error) {
+	tree, err := r.FSAt(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	infos, err := tree.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]TreeEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = info.Sys().(TreeEntry) //nolint:forcetypeassert // treeFileInfo always holds a TreeEntry
+	}
+
+	return entries, nil
+}
+
+// FSAt returns a read-only filesystem view of the tree at the given revision.
+// It works in bare repositories and does not touch the worktree.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) FSAt(ctx context.Context, rev string) (*TreeFS, error) {
+	commit, err := r.commitOf(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, WrapErrorf(err, "failed to read tree of %q", rev)
+	}
+
+	return &TreeFS{tree: tree, modTime: commit.Committer.When}, nil
+}
+
+// TreeFS is a read-only fs.Filesystem backed by a commit tree.
+// Paths are relative to the repository root; a leading "/" is ignored.
+// Write operations fail with ErrReadOnly. Symbolic links are not followed:
+// reading a link returns its target path.
+type TreeFS struct {
+	tree    *object.Tree
+	modTime time.Time
+}
+
+var _ fs.Filesystem = (*TreeFS)(nil)
+
+// Open opens a file or directory for reading.
+//
+//nolint:ireturn // fs.Filesystem dictates the fs.File return type.
+func (t *TreeFS) Open(name string) (fs.File, error) {
+	info, err := t.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &treeFSFile{name: name, info: info}
+	if !info.IsDir() {
+		data, readErr := t.readBlob("open", name, info)
+		if readErr != nil {
+			return nil, readErr
+		}
+		f.reader = bytes.NewReader(data)
+	}
+
+	return f, nil
+}
+
+// OpenFile opens a file for reading. Any flag other than os.O_RDONLY fails
+// with ErrReadOnly.
+//
+//nolint:ireturn // fs.Filesystem dictates the fs.File return type.
+func (t *TreeFS) OpenFile(name string, flag int, _ os.FileMode) (fs.File, error) {
+	if flag != os.O_RDONLY {
+		return nil, readOnlyError("open", name)
+	}
+
+	return t.Open(name)
+}
+
+// ReadFile returns the contents of a file.
+func (t *TreeFS) ReadFile(name string) ([]byte, error) {
+	info, err := t.stat("read", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return nil, &os.PathError{Op: "read", Path: name, Err: errIsDirectory}
+	}
+
+	return t.readBlob("read", name, info)
+}
+
+// ReadDir returns the entries of a directory sorted by name.
+// Submodules are listed as directories with no entries.
+func (t *TreeFS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	info, err := t.stat("readdir", dirname)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, &os.PathError{Op: "readdir", Path: dirname, Err: errNotDirectory}
+	}
+
+	entry := info.Sys().(TreeEntry) //nolint:forcetypeassert // treeFileInfo always holds a TreeEntry
+	if entry.Submodule {
+		return []os.FileInfo{}, nil
+	}
+
+	tree := t.tree
+	if entry.Path != "" {
+		if tree, err = t.tree.Tree(entry.Path); err != nil {
+			return nil, &os.PathError{Op: "readdir", Path: dirname, Err: err}
+		}
+	}
+
+	infos := make([]os.FileInfo, 0, len(tree.Entries))
+	for i := range tree.Entries {
+		child := &tree.Entries[i]
+		childInfo, err := t.entryInfo(path.Join(entry.Path, child.Name), child)
+		if err != nil {
+			return nil, &os.PathError{Op: "readdir", Path: dirname, Err: err}
+		}
+		infos = append(infos, childInfo)
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+
+	return infos, nil
+}
+
+// Stat returns file information without following symbolic links.
+func (t *TreeFS) Stat(name string) (os.FileInfo, error) {
+	return t.stat("stat", name)
+}
+
+// Exists reports whether a path exists in the tree.
+func (t *TreeFS) Exists(name string) (bool, error) {
+	_, err := t.stat("stat", name)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Walk walks the tree rooted at root in lexical order, like filepath.Walk.
+func (t *TreeFS) Walk(root string, walkFn filepath.WalkFunc) error {
+	info, err := t.stat("walk", root)
+	if err != nil {
+		err = walkFn(root, nil, err)
+	} else {
+		err = t.walk(root, info, walkFn)
+	}
+
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+
+	return err
+}
+
+// Create fails with ErrReadOnly.
+//
+//nolint:ireturn // fs.Filesystem dictates the fs.File return type.
+func (t *TreeFS) Create(name string) (fs.File, error) {
+	return nil, readOnlyError("create", name)
+}
+
+// MkdirAll fails with ErrReadOnly.
+func (t *TreeFS) MkdirAll(name string, _ os.FileMode) error {
+	return readOnlyError("mkdir", name)
+}
+
+// Remove fails with ErrReadOnly.
+func (t *TreeFS) Remove(name string) error {
+	return readOnlyError("remove", name)
+}
+
+// Rename fails with ErrReadOnly.
+func (t *TreeFS) Rename(oldpath, _ string) error {
+	return readOnlyError("rename", oldpath)
+}
+
+// TempDir fails with ErrReadOnly.
+func (t *TreeFS) TempDir(dir, _ string) (string, error) {
+	return "", readOnlyError("tempdir", dir)
+}
+
+// WriteFile fails with ErrReadOnly.
+func (t *TreeFS) WriteFile(filename string, _ []byte, _ os.FileMode) error {
+	return readOnlyError("write", filename)
+}
+
+// Symlink fails with ErrReadOnly.
+func (t *TreeFS) Symlink(_, newname string) error {
+	return readOnlyError("symlink", newname)
+}
+
+// walk calls walkFn for name and, if it is a directory, its descendants.
+func (t *TreeFS) walk(name string, info os.FileInfo, walkFn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return walkFn(name, info, nil)
+	}
+
+	children, readErr := t.ReadDir(name)
+	err := walkFn(name, info, readErr)
+	if readErr != nil || err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		err := t.walk(filepath.Join(name, child.Name()), child, walkFn)
+		if err != nil {
+			if child.IsDir() && errors.Is(err, filepath.SkipDir) {
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stat looks up a path and returns its file information.
+func (t *TreeFS) stat(op, name string) (*treeFileInfo, error) {
+	clean := cleanTreePath(name)
+	if clean == "" {
+		return &treeFileInfo{
+			entry:   TreeEntry{Name: ".", Mode: os.ModeDir | os.ModePerm, Hash: t.tree.Hash.String()},
+			modTime: t.modTime,
+		}, nil
+	}
+
+	entry, err := t.tree.FindEntry(clean)
+	if err != nil {
+		return nil, &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+
+	info, err := t.entryInfo(clean, entry)
+	if err != nil {
+		return nil, &os.PathError{Op: op, Path: name, Err: err}
+	}
+
+	return info, nil
+}
+
+// entryInfo builds the file information of a tree entry.
+func (t *TreeFS) entryInfo(entryPath string, entry *object.TreeEntry) (*treeFileInfo, error) {
+	mode, err := entry.Mode.ToOSFileMode()
+	if err != nil {
+		return nil, WrapErrorf(err, "invalid mode for %q", entryPath)
+	}
+
+	info := &treeFileInfo{
+		entry: TreeEntry{
+			Name:      entry.Name,
+			Path:      entryPath,
+			Mode:      mode,
+			Hash:      entry.Hash.String(),
+			Submodule: entry.Mode == filemode.Submodule,
+		},
+		modTime: t.modTime,
+	}
+
+	if !mode.IsDir() {
+		blob, err := t.tree.TreeEntryFile(entry)
+		if err != nil {
+			return nil, WrapErrorf(err, "failed to read %q", entryPath)
+		}
+		info.entry.Size = blob.Size
+	}
+
+	return info, nil
+}
+
+// readBlob returns the contents of the blob described by info.
+func (t *TreeFS) readBlob(op, name string, info *treeFileInfo) ([]byte, error) {
+	file, err := t.tree.File(info.entry.Path)
+	if err != nil {
+		return nil, &os.PathError{Op: op, Path: name, Err: err}
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, &os.PathError{Op: op, Path: name, Err: err}
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, &os.PathError{Op: op, Path: name, Err: err}
+	}
+
+	return data, nil
+}
+
+// cleanTreePath converts a path to the slash-separated form used in trees,
+// returning "" for the root.
+func cleanTreePath(name string) string {
+	clean := path.Clean("/" + filepath.ToSlash(name))
+	return strings.TrimPrefix(clean, "/")
+}
+
+// readOnlyError returns the error for a write operation on a TreeFS.
+func readOnlyError(op, name string) error {
+	return &os.PathError{Op: op, Path: name, Err: ErrReadOnly}
+}
+
+var (
+	// errIsDirectory is returned when reading a directory as a file.
+	errIsDirectory = errors.New("is a directory")
+
+	// errNotDirectory is returned when listing a file as a directory.
+	errNotDirectory = errors.New("not a directory")
+)
+
+// treeFileInfo implements os.FileInfo for tree entries.
+// Sys returns the TreeEntry.
+type treeFileInfo struct {
+	entry   TreeEntry
+	modTime time.Time
+}
+
+func (i *treeFileInfo) Name() string       { return i.entry.Name }
+func (i *treeFileInfo) Size() int64        { return i.entry.Size }
+func (i *treeFileInfo) Mode() os.FileMode  { return i.entry.Mode }
+func (i *treeFileInfo) ModTime() time.Time { return i.modTime }
+func (i *treeFileInfo) IsDir() bool        { return i.entry.Mode.IsDir() }
+func (i *treeFileInfo) Sys() any           { return i.entry }
+
+// treeFSFile is an open file or directory of a TreeFS.
+type treeFSFile struct {
+	name   string
+	info   *treeFileInfo
+	reader *bytes.Reader // nil for directories
+}
+
+// Close implements fs.File.
+func (f *treeFSFile) Close() error {
+	return nil
+}
+
+// Name implements fs.File.
+func (f *treeFSFile) Name() string {
+	return f.name
+}
+
+// Read implements fs.File.
+func (f *treeFSFile) Read(p []byte) (int, error) {
+	if f.reader == nil {
+		return 0, &os.PathError{Op: "read", Path: f.name, Err: errIsDirectory}
+	}
+
+	return f.reader.Read(p) //nolint:wrapcheck // io.EOF must be returned unwrapped
+}
+
+// ReadAt implements fs.File.
+func (f *treeFSFile) ReadAt(p []byte, off int64) (int, error) {
+	if f.reader == nil {
+		return 0, &os.PathError{Op: "read", Path: f.name, Err: errIsDirectory}
+	}
+
+	return f.reader.ReadAt(p, off) //nolint:wrapcheck // io.EOF must be returned unwrapped
+}
+
+// Seek implements fs.File.
+func (f *treeFSFile) Seek(offset int64, whence int) (int64, error) {
+	if f.reader == nil {
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: errIsDirectory}
+	}
+
+	pos, err := f.reader.Seek(offset, whence)
+	if err != nil {
+		return pos, &os.PathError{Op: "seek", Path: f.name, Err: err}
+	}
+
+	return pos, nil
+}
+
+// Stat implements fs.File.
+func (f *treeFSFile) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
+
+// Write fails with ErrReadOnly.
+func (f *treeFSFile) Write(_ []byte) (int, error) {
+	return 0, readOnlyError("write", f.name)
+}
@@ -0,0 +1,171 @@
+package git
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTreeRepo creates a repository with nested files and a second commit
+// that changes one of them, returning the SHA of the first commit
+func setupTreeRepo(t *testing.T) (*testRepo, string) {
+	t.Helper()
+
+	tr := setupTestRepoWithCommit(t)
+	require.NoError(t, tr.fs.MkdirAll("services/api", 0o755))
+	tr.commitFile(t, "Earthfile", "VERSION 0.8\n")
+	tr.commitFile(t, "services/api/blueprint.cue", "version: \"1.0\"\n")
+	first := tr.commitFile(t, "services/api/main.go", "package main\n")
+
+	tr.commitFile(t, "services/api/blueprint.cue", "version: \"2.0\"\n")
+
+	return tr, first
+}
+
+// TestReadFileAt tests reading files at a revision without touching the worktree
+func TestReadFileAt(t *testing.T) {
+	tr, first := setupTreeRepo(t)
+
+	data, err := tr.repo.ReadFileAt(tr.ctx, first, "services/api/blueprint.cue")
+	require.NoError(t, err)
+	assert.Equal(t, "version: \"1.0\"\n", string(data))
+
+	data, err = tr.repo.ReadFileAt(tr.ctx, "HEAD", "/services/api/blueprint.cue")
+	require.NoError(t, err)
+	assert.Equal(t, "version: \"2.0\"\n", string(data))
+
+	// Uncommitted worktree changes are not visible
+	require.NoError(t, tr.fs.WriteFile("Earthfile", []byte("modified"), 0o644))
+	data, err = tr.repo.ReadFileAt(tr.ctx, "HEAD", "Earthfile")
+	require.NoError(t, err)
+	assert.Equal(t, "VERSION 0.8\n", string(data))
+
+	_, err = tr.repo.ReadFileAt(tr.ctx, "HEAD", "missing.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = tr.repo.ReadFileAt(tr.ctx, "HEAD", "services")
+	require.Error(t, err, "directories cannot be read as files")
+
+	_, err = tr.repo.ReadFileAt(tr.ctx, "nonexistent", "Earthfile")
+	require.ErrorIs(t, err, ErrResolveFailed)
+}
+
+// TestListTreeAt tests listing directories at a revision
+func TestListTreeAt(t *testing.T) {
+	tr, first := setupTreeRepo(t)
+
+	entries, err := tr.repo.ListTreeAt(tr.ctx, first, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "Earthfile", entries[0].Name)
+	assert.Equal(t, int64(len("VERSION 0.8\n")), entries[0].Size)
+	assert.Equal(t, "services", entries[1].Name)
+	assert.True(t, entries[1].Mode.IsDir())
+	assert.Equal(t, "test.txt", entries[2].Name)
+
+	entries, err = tr.repo.ListTreeAt(tr.ctx, first, "services/api")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "services/api/blueprint.cue", entries[0].Path)
+	assert.Equal(t, "services/api/main.go", entries[1].Path)
+	assert.Len(t, entries[1].Hash, 40)
+	assert.False(t, entries[1].Submodule)
+
+	_, err = tr.repo.ListTreeAt(tr.ctx, first, "services/web")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = tr.repo.ListTreeAt(tr.ctx, first, "Earthfile")
+	require.Error(t, err, "files cannot be listed")
+}
+
+// TestFSAt tests the read-only filesystem view of a commit
+func TestFSAt(t *testing.T) {
+	tr, first := setupTreeRepo(t)
+
+	tree, err := tr.repo.FSAt(tr.ctx, first)
+	require.NoError(t, err)
+
+	exists, err := tree.Exists("services/api/main.go")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = tree.Exists("services/web")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	info, err := tree.Stat("services")
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	f, err := tree.Open("services/api/main.go")
+	require.NoError(t, err)
+	buf := make([]byte, 7)
+	_, err = f.ReadAt(buf, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "ackage ", string(buf))
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data))
+	require.NoError(t, f.Close())
+
+	var walked []string
+	err = tree.Walk(".", func(path string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		walked = append(walked, path)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		".",
+		"Earthfile",
+		"services",
+		filepath.Join("services", "api"),
+		filepath.Join("services", "api", "blueprint.cue"),
+		filepath.Join("services", "api", "main.go"),
+		"test.txt",
+	}, walked)
+
+	// SkipDir prunes a directory
+	walked = nil
+	err = tree.Walk("", func(path string, info os.FileInfo, err error) error {
+		if info.IsDir() && info.Name() == "services" {
+			return filepath.SkipDir
+		}
+		walked = append(walked, path)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "Earthfile", "test.txt"}, walked)
+}
+
+// TestFSAtReadOnly tests that write operations are rejected
+func TestFSAtReadOnly(t *testing.T) {
+	tr, _ := setupTreeRepo(t)
+
+	tree, err := tr.repo.FSAt(tr.ctx, "HEAD")
+	require.NoError(t, err)
+
+	writes := map[string]error{
+		"WriteFile": tree.WriteFile("new.txt", []byte("x"), 0o644),
+		"MkdirAll":  tree.MkdirAll("dir", 0o755),
+		"Remove":    tree.Remove("Earthfile"),
+		"Rename":    tree.Rename("Earthfile", "Other"),
+		"Symlink":   tree.Symlink("Earthfile", "link"),
+	}
+	_, writes["Create"] = tree.Create("new.txt")
+	_, writes["OpenFile"] = tree.OpenFile("Earthfile", os.O_RDWR, 0)
+	_, writes["TempDir"] = tree.TempDir("", "tmp")
+
+	f, err := tree.Open("Earthfile")
+	require.NoError(t, err)
+	_, writes["File.Write"] = f.Write([]byte("x"))
+
+	for name, err := range writes {
+		assert.True(t, errors.Is(err, ErrReadOnly), "%s should fail with ErrReadOnly, got %v", name, err)
+	}
+}
@@ -0,0 +1,294 @@
+// Package git provides a high-level Go wrapper for go-git operations.
+// This file contains upstream tracking configuration and ahead/behind reporting.
+package git
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Upstream identifies the remote branch a local branch tracks.
+type Upstream struct {
+	// Remote is the name of the remote (e.g., "origin").
+	Remote string
+
+	// Branch is the name of the branch on the remote (e.g., "main").
+	Branch string
+}
+
+// String returns the upstream in remote/branch form (e.g., "origin/main").
+func (u Upstream) String() string {
+	return u.Remote + "/" + u.Branch
+}
+
+// RefName returns the remote-tracking reference of the upstream
+// (e.g., "refs/remotes/origin/main").
+func (u Upstream) RefName() string {
+	return plumbing.NewRemoteReferenceName(u.Remote, u.Branch).String()
+}
+
+// SetUpstream configures the local branch to track remoteBranch on remote,
+// writing branch.<name>.remote and branch.<name>.merge to the repository config.
+// If branch is empty, the current branch is used.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) SetUpstream(ctx context.Context, branch, remote, remoteBranch string) error {
+	if remote == "" {
+		return WrapError(ErrInvalidRef, "remote name cannot be empty")
+	}
+
+	if remoteBranch == "" {
+		return WrapError(ErrInvalidRef, "remote branch name cannot be empty")
+	}
+
+	branch, err := r.localBranch(ctx, branch)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.repo.Remote(remote); err != nil {
+		return WrapErrorf(ErrResolveFailed, "remote %q not found", remote)
+	}
+
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return WrapError(err, "failed to read repository config")
+	}
+
+	cfg.Branches[branch] = &config.Branch{
+		Name:   branch,
+		Remote: remote,
+		Merge:  plumbing.NewBranchReferenceName(remoteBranch),
+	}
+
+	if err := r.saveConfig(cfg); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnsetUpstream removes the upstream tracking configuration of the local branch.
+// If branch is empty, the current branch is used. It is not an error if no
+// upstream was configured.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) UnsetUpstream(ctx context.Context, branch string) error {
+	branch, err := r.localBranch(ctx, branch)
+	if err != nil {
+		return err
+	}
+
+	return r.removeBranchConfig(branch)
+}
+
+// Upstream returns the upstream tracked by the local branch.
+// If branch is empty, the current branch is used.
+// Returns ErrNoUpstream if no upstream is configured.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) Upstream(ctx context.Context, branch string) (*Upstream, error) {
+	branch, err := r.localBranch(ctx, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	upstream, err := r.upstreamOf(branch)
+	if err != nil {
+		return nil, err
+	}
+	if upstream == nil {
+		return nil, WrapErrorf(ErrNoUpstream, "branch %q has no upstream", branch)
+	}
+
+	return upstream, nil
+}
+
+// AheadBehind counts the commits reachable from local but not from upstream
+// (ahead) and the commits reachable from upstream but not from local (behind).
+// Both arguments accept any revision. If upstream is empty, the upstream
+// configured for the branch named by local is used, following symbolic
+// references such as HEAD to the branch they point to.
+//
+// Context timeout/cancellation is honored during the operation.
+func (r *Repo) AheadBehind(ctx context.Context, local, upstream string) (ahead, behind int, err error) {
+	if local == "" {
+		return 0, 0, WrapError(ErrInvalidRef, "local revision cannot be empty")
+	}
+
+	if upstream == "" {
+		configured, upErr := r.Upstream(ctx, r.branchOf(local))
+		if upErr != nil {
+			return 0, 0, upErr
+		}
+		upstream = configured.RefName()
+	}
+
+	localHash, err := r.repo.ResolveRevision(plumbing.Revision(local))
+	if err != nil {
+		return 0, 0, WrapErrorf(ErrResolveFailed, "failed to resolve revision %q", local)
+	}
+
+	upstreamHash, err := r.repo.ResolveRevision(plumbing.Revision(upstream))
+	if err != nil {
+		return 0, 0, WrapErrorf(ErrResolveFailed, "failed to resolve revision %q", upstream)
+	}
+
+	if *localHash == *upstreamHash {
+		return 0, 0, nil
+	}
+
+	// Each walk stops at the history both sides share
+	if ahead, err = r.countExcluding(ctx, *localHash, *upstreamHash); err != nil {
+		return 0, 0, err
+	}
+	if behind, err = r.countExcluding(ctx, *upstreamHash, *localHash); err != nil {
+		return 0, 0, err
+	}
+
+	return ahead, behind, nil
+}
+
+// countExcluding counts the commits reachable from tip but not from excluded.
+func (r *Repo) countExcluding(ctx context.Context, tip, excluded plumbing.Hash) (int, error) {
+	walk, err := r.newRevWalk(ctx, []plumbing.Hash{tip}, []plumbing.Hash{excluded})
+	if err != nil {
+		return 0, err
+	}
+	defer walk.Close()
+
+	count := 0
+	err = walk.ForEach(func(*object.Commit) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, WrapError(err, "failed to walk commit history")
+	}
+
+	return count, nil
+}
+
+// branchOf returns the name of the branch a revision names, following
+// symbolic references such as HEAD. It returns empty for HEAD itself, meaning
+// the current branch, and rev unchanged for other revisions.
+func (r *Repo) branchOf(rev string) string {
+	if rev == gitHead {
+		return ""
+	}
+	if ref, err := r.repo.Reference(plumbing.ReferenceName(rev), true); err == nil && ref.Name().IsBranch() {
+		return ref.Name().Short()
+	}
+	return rev
+}
+
+// localBranch validates that a local branch exists, defaulting to the current branch.
+func (r *Repo) localBranch(ctx context.Context, branch string) (string, error) {
+	if branch == "" {
+		current, err := r.CurrentBranch(ctx)
+		if err != nil {
+			return "", err
+		}
+		return current, nil
+	}
+
+	if _, err := r.repo.Reference(plumbing.NewBranchReferenceName(branch), true); err != nil {
+		return "", WrapErrorf(ErrBranchMissing, "branch %q does not exist", branch)
+	}
+
+	return branch, nil
+}
+
+// upstreamOf returns the configured upstream of a branch, or nil if there is none.
+func (r *Repo) upstreamOf(branch string) (*Upstream, error) {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return nil, WrapError(err, "failed to read repository config")
+	}
+
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote == "" || b.Merge == "" {
+		return nil, nil
+	}
+
+	return &Upstream{Remote: b.Remote, Branch: b.Merge.Short()}, nil
+}
+
+// currentUpstream returns the upstream of the current branch if it is
+// configured for the given remote (or any remote when remote is empty).
+// It returns nil when HEAD is detached or no matching upstream is configured.
+func (r *Repo) currentUpstream(ctx context.Context, remote string) (string, *Upstream, error) {
+	if err := ctx.Err(); err != nil {
+		return "", nil, WrapError(err, "context cancelled")
+	}
+
+	// HEAD is read without resolving so an unborn branch is still found
+	head, err := r.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", nil, WrapError(err, "failed to get HEAD reference")
+	}
+	if head.Type() != plumbing.SymbolicReference || !head.Target().IsBranch() {
+		return "", nil, nil
+	}
+	branch := head.Target().Short()
+
+	upstream, err := r.upstreamOf(branch)
+	if err != nil || upstream == nil {
+		return branch, nil, err
+	}
+
+	if remote != "" && upstream.Remote != remote {
+		return branch, nil, nil
+	}
+
+	return branch, upstream, nil
+}
+
+// removeBranchConfig deletes the config section of a branch, if any.
+func (r *Repo) removeBranchConfig(branch string) error {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return WrapError(err, "failed to read repository config")
+	}
+
+	if _, ok := cfg.Branches[branch]; !ok {
+		return nil
+	}
+	delete(cfg.Branches, branch)
+
+	if err := r.saveConfig(cfg); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// remoteBranchOf splits a remote-tracking revision such as "origin/main" or
+// "refs/remotes/origin/main" into its remote and branch, using the configured
+// remotes to find the split point. It returns nil if rev is not a
+// remote-tracking branch.
+func (r *Repo) remoteBranchOf(rev string) (*Upstream, error) {
+	remotes, err := r.repo.Remotes()
+	if err != nil {
+		return nil, WrapError(err, "failed to list remotes")
+	}
+
+	name := strings.TrimPrefix(rev, "refs/remotes/")
+	for _, remote := range remotes {
+		remoteName := remote.Config().Name
+		branch, ok := strings.CutPrefix(name, remoteName+"/")
+		if !ok || branch == "" {
+			continue
+		}
+		ref := plumbing.NewRemoteReferenceName(remoteName, branch)
+		if _, err := r.repo.Reference(ref, true); err == nil {
+			return &Upstream{Remote: remoteName, Branch: branch}, nil
+		}
+	}
+
+	return nil, nil
+}
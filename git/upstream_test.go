@@ -0,0 +1,243 @@
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	fsb "github.com/input-output-hk/catalyst-forge-libs/fs/billy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addOriginRemote configures an "origin" remote pointing at url
+func (tr *testRepo) addOriginRemote(t *testing.T, url string) {
+	t.Helper()
+
+	_, err := tr.repo.repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{url},
+	})
+	require.NoError(t, err, "failed to create remote")
+}
+
+// commitFile writes a file and commits it, returning the commit SHA
+func (tr *testRepo) commitFile(t *testing.T, path, content string) string {
+	t.Helper()
+
+	require.NoError(t, tr.fs.WriteFile(path, []byte(content), 0o644))
+	require.NoError(t, tr.repo.Add(tr.ctx, path))
+
+	sha, err := tr.repo.Commit(tr.ctx, "update "+path, Signature{
+		Name:  "Test",
+		Email: "test@example.com",
+		When:  time.Now(),
+	}, CommitOpts{})
+	require.NoError(t, err)
+
+	return sha
+}
+
+// TestSetUpstream tests writing, reading and removing upstream configuration
+func TestSetUpstream(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.addOriginRemote(t, "https://example.com/repo.git")
+	tr.createTestBranch(t, "feature/login")
+
+	_, err := tr.repo.Upstream(tr.ctx, "feature/login")
+	require.ErrorIs(t, err, ErrNoUpstream)
+
+	require.NoError(t, tr.repo.SetUpstream(tr.ctx, "feature/login", "origin", "feature/login"))
+
+	upstream, err := tr.repo.Upstream(tr.ctx, "feature/login")
+	require.NoError(t, err)
+	assert.Equal(t, &Upstream{Remote: "origin", Branch: "feature/login"}, upstream)
+	assert.Equal(t, "origin/feature/login", upstream.String())
+	assert.Equal(t, "refs/remotes/origin/feature/login", upstream.RefName())
+
+	cfg, err := tr.repo.repo.Config()
+	require.NoError(t, err)
+	assert.Equal(t, plumbing.ReferenceName("refs/heads/feature/login"), cfg.Branches["feature/login"].Merge)
+
+	require.NoError(t, tr.repo.UnsetUpstream(tr.ctx, "feature/login"))
+	_, err = tr.repo.Upstream(tr.ctx, "feature/login")
+	require.ErrorIs(t, err, ErrNoUpstream)
+}
+
+// TestSetUpstreamErrors tests validation of SetUpstream arguments
+func TestSetUpstreamErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		branch       string
+		remote       string
+		remoteBranch string
+		expectedErr  error
+	}{
+		{"empty remote", "master", "", "main", ErrInvalidRef},
+		{"empty remote branch", "master", "origin", "", ErrInvalidRef},
+		{"missing local branch", "nonexistent", "origin", "main", ErrBranchMissing},
+		{"missing remote", "master", "upstream", "main", ErrResolveFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := setupTestRepoWithCommit(t)
+			tr.addOriginRemote(t, "https://example.com/repo.git")
+
+			err := tr.repo.SetUpstream(tr.ctx, tt.branch, tt.remote, tt.remoteBranch)
+			require.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+// TestTrackingOnBranchCreation tests the track flags of CreateBranch and CheckoutRemoteBranch
+func TestTrackingOnBranchCreation(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.addOriginRemote(t, "https://example.com/repo.git")
+	tr.createRemoteBranch(t, "origin", "release/v1")
+
+	require.NoError(t, tr.repo.CreateBranch(tr.ctx, "release", "origin/release/v1", true, false))
+	upstream, err := tr.repo.Upstream(tr.ctx, "release")
+	require.NoError(t, err)
+	assert.Equal(t, &Upstream{Remote: "origin", Branch: "release/v1"}, upstream)
+
+	require.NoError(t, tr.repo.CheckoutRemoteBranch(tr.ctx, "origin", "release/v1", "hotfix", true))
+	upstream, err = tr.repo.Upstream(tr.ctx, "")
+	require.NoError(t, err, "current branch should track the remote branch")
+	assert.Equal(t, "origin/release/v1", upstream.String())
+
+	err = tr.repo.CreateBranch(tr.ctx, "local-only", "HEAD", true, false)
+	require.ErrorIs(t, err, ErrInvalidRef, "tracking requires a remote-tracking start revision")
+
+	// Deleting a branch removes its upstream configuration
+	require.NoError(t, tr.repo.DeleteBranch(tr.ctx, "release"))
+	cfg, err := tr.repo.repo.Config()
+	require.NoError(t, err)
+	assert.NotContains(t, cfg.Branches, "release")
+}
+
+// TestAheadBehind tests counting commits on each side of diverged branches
+func TestAheadBehind(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.addOriginRemote(t, "https://example.com/repo.git")
+	tr.createRemoteBranch(t, "origin", "master")
+
+	ahead, behind, err := tr.repo.AheadBehind(tr.ctx, "master", "origin/master")
+	require.NoError(t, err)
+	assert.Equal(t, 0, ahead)
+	assert.Equal(t, 0, behind)
+
+	// Two local commits
+	tr.commitFile(t, "a.txt", "a")
+	tr.commitFile(t, "b.txt", "b")
+
+	// One commit on the remote-tracking branch, diverging from the initial commit
+	require.NoError(t, tr.repo.CheckoutRemoteBranch(tr.ctx, "origin", "master", "remote-work", false))
+	remoteSHA := tr.commitFile(t, "c.txt", "c")
+	require.NoError(t, tr.repo.repo.Storer.SetReference(
+		plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "master"), plumbing.NewHash(remoteSHA)),
+	))
+
+	ahead, behind, err = tr.repo.AheadBehind(tr.ctx, "master", "origin/master")
+	require.NoError(t, err)
+	assert.Equal(t, 2, ahead)
+	assert.Equal(t, 1, behind)
+
+	// The configured upstream is used when none is given
+	require.NoError(t, tr.repo.SetUpstream(tr.ctx, "master", "origin", "master"))
+	ahead, behind, err = tr.repo.AheadBehind(tr.ctx, "master", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, ahead)
+	assert.Equal(t, 1, behind)
+
+	// Symbolic references name the branch they point to
+	require.NoError(t, tr.repo.CheckoutBranch(tr.ctx, "master", false, false))
+	for _, local := range []string{"HEAD", "refs/heads/master"} {
+		ahead, behind, err = tr.repo.AheadBehind(tr.ctx, local, "")
+		require.NoError(t, err, local)
+		assert.Equal(t, 2, ahead, local)
+		assert.Equal(t, 1, behind, local)
+	}
+
+	_, _, err = tr.repo.AheadBehind(tr.ctx, "remote-work", "")
+	require.ErrorIs(t, err, ErrNoUpstream)
+
+	_, _, err = tr.repo.AheadBehind(tr.ctx, "master", "nonexistent")
+	require.ErrorIs(t, err, ErrResolveFailed)
+}
+
+// TestAheadBehindShallow tests counting commits in a shallow clone
+func TestAheadBehindShallow(t *testing.T) {
+	tr, _ := setupShallowClone(t, 2, nil, "one", "two", "three", "four")
+
+	ahead, behind, err := tr.repo.AheadBehind(tr.ctx, "HEAD", "HEAD~1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, ahead)
+	assert.Equal(t, 0, behind)
+}
+
+// TestCurrentUpstream tests finding the upstream of the current branch
+func TestCurrentUpstream(t *testing.T) {
+	tr := setupTestRepoWithCommit(t)
+	tr.addOriginRemote(t, "https://example.com/repo.git")
+
+	require.NoError(t, tr.repo.SetUpstream(tr.ctx, "master", "origin", "main"))
+	branch, upstream, err := tr.repo.currentUpstream(tr.ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "master", branch)
+	assert.Equal(t, &Upstream{Remote: "origin", Branch: "main"}, upstream)
+
+	_, upstream, err = tr.repo.currentUpstream(tr.ctx, "fork")
+	require.NoError(t, err)
+	assert.Nil(t, upstream)
+
+	// A detached HEAD has no upstream
+	sha := tr.commitFile(t, "a.txt", "a")
+	require.NoError(t, tr.repo.repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, plumbing.NewHash(sha))))
+	branch, upstream, err = tr.repo.currentUpstream(tr.ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, branch)
+	assert.Nil(t, upstream)
+
+	ctx, cancel := context.WithCancel(tr.ctx)
+	cancel()
+	_, _, err = tr.repo.currentUpstream(ctx, "")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// TestPushAndPullUseUpstream tests that Push and PullFFOnly follow the configured upstream
+func TestPushAndPullUseUpstream(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	origin, err := Init(ctx, &Options{FS: fsb.NewOSFS(dir), Bare: true, Workdir: "."})
+	require.NoError(t, err)
+
+	tr := setupTestRepoWithCommit(t)
+	tr.addOriginRemote(t, "file://"+dir)
+	tr.createRemoteBranch(t, "origin", "shared")
+	require.NoError(t, tr.repo.SetUpstream(tr.ctx, "master", "origin", "shared"))
+
+	// Push goes to the upstream branch rather than a branch of the same name
+	require.NoError(t, tr.repo.Push(tr.ctx, "", false))
+	_, err = origin.repo.Reference(plumbing.NewBranchReferenceName("shared"), true)
+	require.NoError(t, err, "upstream branch should exist on the remote")
+	_, err = origin.repo.Reference(plumbing.NewBranchReferenceName("master"), true)
+	require.Error(t, err, "local branch name should not be pushed")
+
+	// Another clone advances the shared branch
+	other := setupTestRepo(t, false)
+	other.addOriginRemote(t, "file://"+dir)
+	require.NoError(t, other.repo.Fetch(ctx, "origin", false, 0))
+	require.NoError(t, other.repo.CheckoutRemoteBranch(ctx, "origin", "shared", "shared", true))
+	newSHA := other.commitFile(t, "other.txt", "from another clone")
+	require.NoError(t, other.repo.Push(ctx, "", false))
+
+	// Pull fast-forwards the local branch from its upstream
+	require.NoError(t, tr.repo.PullFFOnly(tr.ctx, ""))
+	head, err := tr.repo.repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, newSHA, head.Hash().String())
+}
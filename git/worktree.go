@@ -313,6 +313,7 @@ func (r *Repo) Commit(ctx context.Context, msg string, who Signature, opts Commi
 			When:  who.When,
 		},
 		AllowEmptyCommits: opts.AllowEmpty,
+		Signer:            opts.Signer,
 	}
 
 	// Create the commit